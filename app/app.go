@@ -2,8 +2,10 @@ package app
 
 import (
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/celestiaorg/celestia-app/x/mint"
 	mintkeeper "github.com/celestiaorg/celestia-app/x/mint/keeper"
@@ -75,12 +77,15 @@ import (
 	ibcporttypes "github.com/cosmos/ibc-go/v6/modules/core/05-port/types"
 	ibchost "github.com/cosmos/ibc-go/v6/modules/core/24-host"
 	ibckeeper "github.com/cosmos/ibc-go/v6/modules/core/keeper"
+	gogogrpc "github.com/gogo/protobuf/grpc"
 	"github.com/spf13/cast"
 	abci "github.com/tendermint/tendermint/abci/types"
 	tmjson "github.com/tendermint/tendermint/libs/json"
 	"github.com/tendermint/tendermint/libs/log"
 	tmos "github.com/tendermint/tendermint/libs/os"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
 	dbm "github.com/tendermint/tm-db"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 
 	"github.com/celestiaorg/celestia-app/app/ante"
 	"github.com/celestiaorg/celestia-app/app/encoding"
@@ -236,6 +241,16 @@ type App struct {
 
 	// module configurator
 	configurator module.Configurator
+
+	// nodeClient is used to query the status of the consensus node for the
+	// health service. It is set when the Tendermint service is registered.
+	nodeClient rpcclient.StatusClient
+
+	// committed is the last committed state reported by the health service.
+	// The health service runs concurrently with the ABCI connection, so it is
+	// guarded by committedMtx.
+	committedMtx sync.RWMutex
+	committed    committedState
 }
 
 // New returns a reference to an initialized celestia app.
@@ -556,6 +571,7 @@ func New(
 			tmos.Exit(err.Error())
 		}
 	}
+	app.setCommitted()
 
 	app.ScopedIBCKeeper = scopedIBCKeeper
 	app.ScopedTransferKeeper = scopedTransferKeeper
@@ -586,6 +602,14 @@ func (app *App) EndBlocker(ctx sdk.Context, req abci.RequestEndBlock) abci.Respo
 	return res
 }
 
+// Commit commits the state of the block and records the committed state for
+// the health service.
+func (app *App) Commit() abci.ResponseCommit {
+	res := app.BaseApp.Commit()
+	app.setCommitted()
+	return res
+}
+
 // InitChainer application update at chain initialization
 func (app *App) InitChainer(ctx sdk.Context, req abci.RequestInitChain) abci.ResponseInitChain {
 	var genesisState GenesisState
@@ -702,6 +726,10 @@ func (app *App) RegisterAPIRoutes(apiSvr *api.Server, _ config.APIConfig) {
 
 	// Register the
 	ModuleBasics.RegisterGRPCGatewayRoutes(clientCtx, apiSvr.GRPCGatewayRouter)
+
+	// Register the health report of the node. Like all other routes, it is
+	// only served if the API server is enabled.
+	apiSvr.Router.HandleFunc(HealthRoute, app.healthHandler(clientCtx.Client)).Methods(http.MethodGet)
 }
 
 // RegisterTxService implements the Application.RegisterTxService method.
@@ -712,6 +740,15 @@ func (app *App) RegisterTxService(clientCtx client.Context) {
 // RegisterTendermintService implements the Application.RegisterTendermintService method.
 func (app *App) RegisterTendermintService(clientCtx client.Context) {
 	tmservice.RegisterTendermintService(clientCtx, app.BaseApp.GRPCQueryRouter(), app.interfaceRegistry, nil)
+	// the client is also used to query the sync status for the health service
+	app.nodeClient = clientCtx.Client
+}
+
+// RegisterGRPCServer implements the Application.RegisterGRPCServer method. In
+// addition to the query services it registers the gRPC health service.
+func (app *App) RegisterGRPCServer(server gogogrpc.Server) {
+	app.BaseApp.RegisterGRPCServer(server)
+	healthpb.RegisterHealthServer(server, healthServer{app: app})
 }
 
 func (app *App) RegisterNodeService(clientCtx client.Context) {
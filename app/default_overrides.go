@@ -142,7 +142,7 @@ func (ibcModule) DefaultGenesis(cdc codec.JSONCodec) json.RawMessage {
 	// per ibc documentation, this value should be 3-5 times the expected block
 	// time. The expected block time is 15 seconds, therefore this value is 75
 	// seconds.
-	maxBlockTime := appconsts.GoalBlockTime * 5
+	maxBlockTime := appconsts.DefaultGoalBlockTime * 5
 	gs := ibctypes.DefaultGenesisState()
 	gs.ClientGenesis.Params.AllowedClients = []string{"06-solomachine", "07-tendermint"}
 	gs.ConnectionGenesis.Params.MaxExpectedTimePerBlock = uint64(maxBlockTime.Nanoseconds())
@@ -223,10 +223,12 @@ func DefaultBlockParams() tmproto.BlockParams {
 func DefaultEvidenceParams() tmproto.EvidenceParams {
 	evdParams := coretypes.DefaultEvidenceParams()
 	evdParams.MaxAgeDuration = appconsts.DefaultUnbondingTime
-	evdParams.MaxAgeNumBlocks = int64(appconsts.DefaultUnbondingTime.Seconds())/int64(appconsts.GoalBlockTime.Seconds()) + 1
+	evdParams.MaxAgeNumBlocks = int64(appconsts.DefaultUnbondingTime.Seconds())/int64(appconsts.DefaultGoalBlockTime.Seconds()) + 1
 	return evdParams
 }
 
+// DefaultConsensusConfig returns the default comet config. Its timeouts and
+// mempool limits are derived from the constants of the latest app version.
 func DefaultConsensusConfig() *tmcfg.Config {
 	cfg := tmcfg.DefaultConfig()
 	// Set broadcast timeout to be 50 seconds in order to avoid timeouts for long block times
@@ -235,7 +237,7 @@ func DefaultConsensusConfig() *tmcfg.Config {
 	cfg.RPC.MaxBodyBytes = int64(8388608) // 8 MiB
 
 	cfg.Mempool.TTLNumBlocks = 5
-	cfg.Mempool.TTLDuration = time.Duration(cfg.Mempool.TTLNumBlocks) * appconsts.GoalBlockTime
+	cfg.Mempool.TTLDuration = time.Duration(cfg.Mempool.TTLNumBlocks) * appconsts.DefaultGoalBlockTime
 	// Given that there is a stateful transaction size check in CheckTx,
 	// We set a loose upper bound on what we expect the transaction to
 	// be based on the upper bound size of the entire block for the given
@@ -245,8 +247,8 @@ func DefaultConsensusConfig() *tmcfg.Config {
 	cfg.Mempool.MaxTxsBytes = int64(upperBoundBytes) * cfg.Mempool.TTLNumBlocks
 	cfg.Mempool.Version = "v1" // prioritized mempool
 
-	cfg.Consensus.TimeoutPropose = appconsts.TimeoutPropose
-	cfg.Consensus.TimeoutCommit = appconsts.TimeoutCommit
+	cfg.Consensus.TimeoutPropose = appconsts.DefaultTimeoutPropose
+	cfg.Consensus.TimeoutCommit = appconsts.DefaultTimeoutCommit
 	cfg.Consensus.SkipTimeoutCommit = false
 
 	cfg.TxIndex.Indexer = "null"
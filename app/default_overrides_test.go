@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/celestiaorg/celestia-app/app/encoding"
+	"github.com/celestiaorg/celestia-app/pkg/appconsts"
 	"github.com/cosmos/cosmos-sdk/types"
 	distributiontypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
 	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
@@ -63,3 +64,13 @@ func TestDefaultAppConfig(t *testing.T) {
 	assert.Equal(t, uint32(2), cfg.StateSync.SnapshotKeepRecent)
 	assert.Equal(t, "0.1utia", cfg.MinGasPrices)
 }
+
+func TestDefaultConsensusConfig(t *testing.T) {
+	cfg := DefaultConsensusConfig()
+	latest := appconsts.Versioned(appconsts.LatestVersion)
+
+	assert.Equal(t, latest.TimeoutPropose, cfg.Consensus.TimeoutPropose)
+	assert.Equal(t, latest.TimeoutCommit, cfg.Consensus.TimeoutCommit)
+	assert.Equal(t, time.Duration(cfg.Mempool.TTLNumBlocks)*latest.GoalBlockTime, cfg.Mempool.TTLDuration)
+	assert.Equal(t, latest.SquareSizeUpperBound*latest.SquareSizeUpperBound*appconsts.ContinuationSparseShareContentSize, cfg.Mempool.MaxTxBytes)
+}
@@ -2,12 +2,16 @@ package app
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
 	"log"
 
 	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
 
 	servertypes "github.com/cosmos/cosmos-sdk/server/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
 	slashingtypes "github.com/cosmos/cosmos-sdk/x/slashing/types"
 	"github.com/cosmos/cosmos-sdk/x/staking"
 	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
@@ -44,6 +48,140 @@ func (app *App) ExportAppStateAndValidators(
 	}, err
 }
 
+// ExportModulesGenesis writes the genesis state of the modules in moduleNames
+// to w as a JSON object keyed by module name. Unlike
+// ExportAppStateAndValidators, only the requested modules are exported. The
+// balances of the bank module are streamed to w one account at a time so that
+// they never have to be held in memory at once.
+func (app *App) ExportModulesGenesis(w io.Writer, moduleNames []string) error {
+	for _, moduleName := range moduleNames {
+		if _, ok := app.mm.Modules[moduleName]; !ok {
+			return fmt.Errorf("unknown module %s", moduleName)
+		}
+	}
+
+	ctx := app.NewContext(true, tmproto.Header{Height: app.LastBlockHeight()})
+
+	if _, err := io.WriteString(w, "{"); err != nil {
+		return err
+	}
+	for i, moduleName := range moduleNames {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		key, err := json.Marshal(moduleName)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s:", key); err != nil {
+			return err
+		}
+
+		if moduleName == banktypes.ModuleName {
+			err = app.exportBankGenesis(ctx, w)
+		} else {
+			_, err = w.Write(app.mm.Modules[moduleName].ExportGenesis(ctx, app.appCodec))
+		}
+		if err != nil {
+			return fmt.Errorf("exporting %s: %w", moduleName, err)
+		}
+	}
+	_, err := io.WriteString(w, "}")
+	return err
+}
+
+// exportBankGenesis writes the genesis state of the bank module to w. It
+// produces the same JSON as the bank module's ExportGenesis but iterates over
+// the balances store instead of loading all balances into memory.
+func (app *App) exportBankGenesis(ctx sdk.Context, w io.Writer) error {
+	params := app.BankKeeper.GetParams(ctx)
+	paramsJSON, err := app.appCodec.MarshalJSON(&params)
+	if err != nil {
+		return err
+	}
+	totalSupply, _, err := app.BankKeeper.GetPaginatedTotalSupply(ctx, &query.PageRequest{Limit: query.MaxLimit})
+	if err != nil {
+		return err
+	}
+	supplyJSON, err := json.Marshal(totalSupply)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, `{"params":%s,"supply":%s,"denom_metadata":[`, paramsJSON, supplyJSON); err != nil {
+		return err
+	}
+	var (
+		metadataCount int
+		metadataErr   error
+	)
+	app.BankKeeper.IterateAllDenomMetaData(ctx, func(metadata banktypes.Metadata) bool {
+		metadataErr = writeJSONArrayElement(w, metadataCount, app.appCodec.MustMarshalJSON(&metadata))
+		metadataCount++
+		return metadataErr != nil
+	})
+	if metadataErr != nil {
+		return metadataErr
+	}
+	if _, err := io.WriteString(w, `],"balances":[`); err != nil {
+		return err
+	}
+
+	// Balances are stored per address and denom, sorted by address. All
+	// coins of an address are therefore iterated consecutively.
+	var (
+		count   int
+		current banktypes.Balance
+		iterErr error
+	)
+	flush := func() error {
+		if current.Address == "" {
+			return nil
+		}
+		bz, err := app.appCodec.MarshalJSON(&current)
+		if err != nil {
+			return err
+		}
+		if err := writeJSONArrayElement(w, count, bz); err != nil {
+			return err
+		}
+		count++
+		return nil
+	}
+	app.BankKeeper.IterateAllBalances(ctx, func(addr sdk.AccAddress, coin sdk.Coin) bool {
+		if addr.String() != current.Address {
+			if iterErr = flush(); iterErr != nil {
+				return true
+			}
+			current = banktypes.Balance{Address: addr.String()}
+		}
+		current.Coins = current.Coins.Add(coin)
+		return false
+	})
+	if iterErr != nil {
+		return iterErr
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "]}")
+	return err
+}
+
+// writeJSONArrayElement writes bz to w, prefixed by a comma unless it is the
+// first element of the array.
+func writeJSONArrayElement(w io.Writer, index int, bz []byte) error {
+	if index > 0 {
+		if _, err := io.WriteString(w, ","); err != nil {
+			return err
+		}
+	}
+	_, err := w.Write(bz)
+	return err
+}
+
 // prepForZeroHeightGenesis preps for fresh start at zero height. Zero height
 // genesis is a temporary feature which will be deprecated in favour of export
 // at a block height.
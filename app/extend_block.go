@@ -18,7 +18,7 @@ func ExtendBlock(data coretypes.Data, appVersion uint64) (*rsmt2d.ExtendedDataSq
 		return nil, err
 	}
 
-	return da.ExtendShares(shares.ToBytes(dataSquare))
+	return da.ExtendShares(shares.ToBytes(dataSquare), appVersion)
 }
 
 // EmptyBlock returns true if the given block data is considered empty by the
@@ -0,0 +1,228 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	bsmoduletypes "github.com/celestiaorg/celestia-app/x/blobstream/types"
+	"github.com/celestiaorg/celestia-app/x/upgrade"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// HealthRoute is the route of the API server that serves the health report.
+// The route is only served if the API server is enabled (api.enable in
+// app.toml). The gRPC health service is served by the gRPC server regardless.
+const HealthRoute = "/celestia/health"
+
+// NetworkUpgradeSchedules are the upgrade schedules agreed upon by the known
+// networks. A node participating in one of these networks must be configured
+// with the same schedule in order to upgrade together with the rest of the
+// network. None of the known networks have scheduled an upgrade yet, so their
+// schedules are empty.
+var NetworkUpgradeSchedules = map[string]upgrade.Schedule{
+	"celestia":   {},
+	"mocha-4":    {},
+	"arabica-10": {},
+}
+
+// HealthCheck is the result of a single readiness check.
+type HealthCheck struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	Message string `json:"message,omitempty"`
+}
+
+// HealthReport aggregates the readiness checks of a node. The node is healthy
+// if all checks are healthy.
+type HealthReport struct {
+	Healthy    bool          `json:"healthy"`
+	ChainID    string        `json:"chain_id"`
+	Height     int64         `json:"height"`
+	AppVersion uint64        `json:"app_version"`
+	Checks     []HealthCheck `json:"checks"`
+}
+
+// Health reports whether the node is caught up, runs the expected app version,
+// is configured with the upgrade schedule of its network and, if it is a
+// validator, has registered an EVM address for Blobstream. node is used to
+// query the sync status of the consensus node.
+func (app *App) Health(ctx context.Context, node rpcclient.StatusClient) HealthReport {
+	committed := app.committedState()
+	report := HealthReport{
+		Height:     committed.height,
+		AppVersion: committed.appVersion,
+	}
+
+	if node == nil {
+		report.Checks = append(report.Checks, HealthCheck{Name: "status", Message: "no consensus node client available"})
+		return report
+	}
+	nodeStatus, err := node.Status(ctx)
+	if err != nil {
+		report.Checks = append(report.Checks, HealthCheck{Name: "status", Message: fmt.Sprintf("failed to query node status: %v", err)})
+		return report
+	}
+	report.ChainID = nodeStatus.NodeInfo.Network
+
+	syncCheck := HealthCheck{Name: "sync", Healthy: !nodeStatus.SyncInfo.CatchingUp}
+	if nodeStatus.SyncInfo.CatchingUp {
+		syncCheck.Message = fmt.Sprintf("node is catching up, latest block height %d", nodeStatus.SyncInfo.LatestBlockHeight)
+	}
+	report.Checks = append(report.Checks,
+		syncCheck,
+		app.checkAppVersion(report.ChainID, committed),
+		app.checkUpgradeSchedule(report.ChainID),
+	)
+
+	sdkCtx, err := app.healthContext(report.ChainID, report.Height)
+	if err != nil {
+		report.Checks = append(report.Checks, HealthCheck{Name: "state", Message: err.Error()})
+	} else {
+		report.Checks = append(report.Checks, app.checkEVMAddress(sdkCtx, sdk.ConsAddress(nodeStatus.ValidatorInfo.Address)))
+	}
+
+	report.Healthy = true
+	for _, check := range report.Checks {
+		report.Healthy = report.Healthy && check.Healthy
+	}
+	return report
+}
+
+// committedState is the height and app version of the last committed block.
+type committedState struct {
+	height     int64
+	appVersion uint64
+}
+
+// setCommitted records the last committed state. It must be called from the
+// ABCI connection, which is the only writer of the state of the app.
+func (app *App) setCommitted() {
+	app.committedMtx.Lock()
+	defer app.committedMtx.Unlock()
+	app.committed = committedState{height: app.LastBlockHeight(), appVersion: app.AppVersion()}
+}
+
+// committedState returns the last committed state. Unlike the BaseApp
+// accessors it is safe to call concurrently with the ABCI connection.
+func (app *App) committedState() committedState {
+	app.committedMtx.RLock()
+	defer app.committedMtx.RUnlock()
+	return app.committed
+}
+
+// healthContext returns a context over the committed state at height.
+func (app *App) healthContext(chainID string, height int64) (sdk.Context, error) {
+	cms, err := app.CommitMultiStore().CacheMultiStoreWithVersion(height)
+	if err != nil {
+		return sdk.Context{}, fmt.Errorf("failed to load state at height %d: %w", height, err)
+	}
+	return sdk.NewContext(cms, tmproto.Header{ChainID: chainID, Height: height}, true, app.Logger()), nil
+}
+
+// checkAppVersion checks that the committed app version is supported and not
+// behind the version expected by the upgrade schedule. If no schedule is
+// configured for the chain ID, the schedule of the network is used.
+func (app *App) checkAppVersion(chainID string, committed committedState) HealthCheck {
+	check := HealthCheck{Name: "app_version"}
+	appVersion := committed.appVersion
+	if !IsSupported(appVersion) {
+		check.Message = fmt.Sprintf("app version %d is not supported", appVersion)
+		return check
+	}
+	schedule, ok := app.UpgradeKeeper.GetSchedule(chainID)
+	if !ok {
+		schedule = NetworkUpgradeSchedules[chainID]
+	}
+	if expected, ok := schedule.ExpectedVersion(committed.height); ok && appVersion < expected {
+		check.Message = fmt.Sprintf("app version %d is behind the expected app version %d", appVersion, expected)
+		return check
+	}
+	check.Healthy = true
+	return check
+}
+
+// checkUpgradeSchedule reports the upgrade schedule configured for the chain
+// ID and checks that it matches the schedule of the network. The check is
+// healthy for chain IDs that are not known networks.
+func (app *App) checkUpgradeSchedule(chainID string) HealthCheck {
+	check := HealthCheck{Name: "upgrade_schedule", Healthy: true}
+	configured, ok := app.UpgradeKeeper.GetSchedule(chainID)
+	description := "no upgrade schedule is configured"
+	if ok {
+		description = fmt.Sprintf("configured upgrade schedule %s", formatSchedule(chainID, configured))
+	}
+	expected, known := NetworkUpgradeSchedules[chainID]
+	switch {
+	case !known:
+		check.Message = fmt.Sprintf("%s, %s is not a known network", description, chainID)
+	case !configured.Equal(expected):
+		check.Healthy = false
+		check.Message = fmt.Sprintf("%s, differs from the upgrade schedule %s of %s", description, formatSchedule(chainID, expected), chainID)
+	default:
+		check.Message = fmt.Sprintf("%s, matches the upgrade schedule %s of %s", description, formatSchedule(chainID, expected), chainID)
+	}
+	return check
+}
+
+// formatSchedule formats the plans of schedule as passed to the upgrade
+// schedule flag.
+func formatSchedule(chainID string, schedule upgrade.Schedule) string {
+	return "[" + strings.Join(schedule.FormatPlans(chainID), ",") + "]"
+}
+
+// checkEVMAddress checks that the validator with the consensus address of the
+// node, if any, has registered an EVM address. Blobstream assigns a default
+// EVM address derived from the operator address to every new validator, so
+// the default address counts as not registered.
+func (app *App) checkEVMAddress(ctx sdk.Context, consAddr sdk.ConsAddress) HealthCheck {
+	check := HealthCheck{Name: "evm_address", Healthy: true}
+	validator, found := app.StakingKeeper.GetValidatorByConsAddr(ctx, consAddr)
+	if !found {
+		check.Message = "node is not a validator"
+		return check
+	}
+	evmAddress, ok := app.BlobstreamKeeper.GetEVMAddress(ctx, validator.GetOperator())
+	if !ok || evmAddress == bsmoduletypes.DefaultEVMAddress(validator.GetOperator()) {
+		check.Healthy = false
+		check.Message = fmt.Sprintf("validator %s has not registered an EVM address", validator.GetOperator())
+		return check
+	}
+	check.Message = evmAddress.Hex()
+	return check
+}
+
+// healthHandler serves the health report as JSON. It responds with status
+// 503 if the node is not healthy.
+func (app *App) healthHandler(node rpcclient.StatusClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := app.Health(r.Context(), node)
+		w.Header().Set("Content-Type", "application/json")
+		if !report.Healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(report)
+	}
+}
+
+// healthServer implements the standard gRPC health service using the health
+// report of the app.
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+
+	app *App
+}
+
+// Check implements the gRPC health service. The service name is ignored.
+func (s healthServer) Check(ctx context.Context, _ *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	resp := &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING}
+	if s.app.Health(ctx, s.app.nodeClient).Healthy {
+		resp.Status = healthpb.HealthCheckResponse_SERVING
+	}
+	return resp, nil
+}
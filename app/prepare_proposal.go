@@ -58,7 +58,7 @@ func (app *App) PrepareProposal(req abci.RequestPrepareProposal) abci.ResponsePr
 	if app.LastBlockHeight() == 0 {
 		txs = make([][]byte, 0)
 	} else {
-		txs = FilterTxs(app.Logger(), sdkCtx, handler, app.txConfig, req.BlockData.Txs)
+		txs = FilterTxs(app.proposalLogger(), sdkCtx, handler, app.txConfig, req.BlockData.Txs)
 
 		// TODO: this would be improved if we only attempted the upgrade in the first round of the
 		// height to still allow transactions to pass through without being delayed from trying
@@ -92,9 +92,9 @@ func (app *App) PrepareProposal(req abci.RequestPrepareProposal) abci.ResponsePr
 	// erasure the data square which we use to create the data root.
 	// Note: uses the nmt wrapper to construct the tree.
 	// checkout pkg/wrapper/nmt_wrapper.go for more information.
-	eds, err := da.ExtendShares(shares.ToBytes(dataSquare))
+	eds, err := da.ExtendShares(shares.ToBytes(dataSquare), app.GetBaseApp().AppVersion())
 	if err != nil {
-		app.Logger().Error(
+		app.proposalLogger().Error(
 			"failure to erasure the data square while creating a proposal block",
 			"error",
 			err.Error(),
@@ -106,7 +106,7 @@ func (app *App) PrepareProposal(req abci.RequestPrepareProposal) abci.ResponsePr
 	// roots of each row and col of the erasure data).
 	dah, err := da.NewDataAvailabilityHeader(eds)
 	if err != nil {
-		app.Logger().Error(
+		app.proposalLogger().Error(
 			"failure to create new data availability header",
 			"error",
 			err.Error(),
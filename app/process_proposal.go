@@ -19,7 +19,14 @@ import (
 	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
 )
 
-const rejectedPropBlockLog = "Rejected proposal block:"
+const (
+	rejectedPropBlockLog = "Rejected proposal block:"
+
+	// ProposalLogModule is the module name under which PrepareProposal and
+	// ProcessProposal log. It can be used to configure the log level of
+	// proposal handling separately.
+	ProposalLogModule = "proposal"
+)
 
 func (app *App) ProcessProposal(req abci.RequestProcessProposal) (resp abci.ResponseProcessProposal) {
 	defer telemetry.MeasureSince(time.Now(), "process_proposal")
@@ -27,7 +34,7 @@ func (app *App) ProcessProposal(req abci.RequestProcessProposal) (resp abci.Resp
 	// network that we catch it, log an error and vote nil than to crash the node.
 	defer func() {
 		if err := recover(); err != nil {
-			logInvalidPropBlock(app.Logger(), req.Header, fmt.Sprintf("caught panic: %v", err))
+			logInvalidPropBlock(app.proposalLogger(), req.Header, fmt.Sprintf("caught panic: %v", err))
 			telemetry.IncrCounter(1, "process_proposal", "panics")
 			resp = reject()
 		}
@@ -72,24 +79,24 @@ func (app *App) ProcessProposal(req abci.RequestProcessProposal) (resp abci.Resp
 			_, has := hasPFB(msgs)
 			if has {
 				// A non blob tx has a PFB, which is invalid
-				logInvalidPropBlock(app.Logger(), req.Header, fmt.Sprintf("tx %d has PFB but is not a blob tx", idx))
+				logInvalidPropBlock(app.proposalLogger(), req.Header, fmt.Sprintf("tx %d has PFB but is not a blob tx", idx))
 				return reject()
 			}
 
 			if appVersion, ok := upgrade.IsUpgradeMsg(msgs); ok {
 				if idx != 0 {
-					logInvalidPropBlock(app.Logger(), req.Header, fmt.Sprintf("upgrade message %d is not the first transaction", idx))
+					logInvalidPropBlock(app.proposalLogger(), req.Header, fmt.Sprintf("upgrade message %d is not the first transaction", idx))
 					return reject()
 				}
 
 				if !IsSupported(appVersion) {
-					logInvalidPropBlock(app.Logger(), req.Header, fmt.Sprintf("block proposes an unsupported app version %d", appVersion))
+					logInvalidPropBlock(app.proposalLogger(), req.Header, fmt.Sprintf("block proposes an unsupported app version %d", appVersion))
 					return reject()
 				}
 
 				// app version must always increase
 				if appVersion <= app.GetBaseApp().AppVersion() {
-					logInvalidPropBlock(app.Logger(), req.Header, fmt.Sprintf("block proposes an app version %d that is not greater than the current app version %d", appVersion, app.GetBaseApp().AppVersion()))
+					logInvalidPropBlock(app.proposalLogger(), req.Header, fmt.Sprintf("block proposes an app version %d that is not greater than the current app version %d", appVersion, app.GetBaseApp().AppVersion()))
 					return reject()
 				}
 
@@ -102,7 +109,7 @@ func (app *App) ProcessProposal(req abci.RequestProcessProposal) (resp abci.Resp
 			// if the account in question doens't exist.
 			sdkCtx, err = handler(sdkCtx, sdkTx, false)
 			if err != nil {
-				logInvalidPropBlockError(app.Logger(), req.Header, "failure to increment sequence", err)
+				logInvalidPropBlockError(app.proposalLogger(), req.Header, "failure to increment sequence", err)
 				return reject()
 			}
 
@@ -118,14 +125,14 @@ func (app *App) ProcessProposal(req abci.RequestProcessProposal) (resp abci.Resp
 		// - that the namespaces match between blob and PFB
 		// - that the share commitment is correct
 		if err := blobtypes.ValidateBlobTx(app.txConfig, blobTx); err != nil {
-			logInvalidPropBlockError(app.Logger(), req.Header, fmt.Sprintf("invalid blob tx %d", idx), err)
+			logInvalidPropBlockError(app.proposalLogger(), req.Header, fmt.Sprintf("invalid blob tx %d", idx), err)
 			return reject()
 		}
 
 		// validated the PFB signature
 		sdkCtx, err = handler(sdkCtx, sdkTx, false)
 		if err != nil {
-			logInvalidPropBlockError(app.Logger(), req.Header, "invalid PFB signature", err)
+			logInvalidPropBlockError(app.proposalLogger(), req.Header, "invalid PFB signature", err)
 			return reject()
 		}
 
@@ -134,38 +141,43 @@ func (app *App) ProcessProposal(req abci.RequestProcessProposal) (resp abci.Resp
 	// Construct the data square from the block's transactions
 	dataSquare, err := square.Construct(req.BlockData.Txs, app.GetBaseApp().AppVersion(), app.GovSquareSizeUpperBound(sdkCtx))
 	if err != nil {
-		logInvalidPropBlockError(app.Logger(), req.Header, "failure to compute data square from transactions:", err)
+		logInvalidPropBlockError(app.proposalLogger(), req.Header, "failure to compute data square from transactions:", err)
 		return reject()
 	}
 
 	// Assert that the square size stated by the proposer is correct
 	if uint64(dataSquare.Size()) != req.BlockData.SquareSize {
-		logInvalidPropBlock(app.Logger(), req.Header, "proposed square size differs from calculated square size")
+		logInvalidPropBlock(app.proposalLogger(), req.Header, "proposed square size differs from calculated square size")
 		return reject()
 	}
 
-	eds, err := da.ExtendShares(shares.ToBytes(dataSquare))
+	eds, err := da.ExtendShares(shares.ToBytes(dataSquare), app.GetBaseApp().AppVersion())
 	if err != nil {
-		logInvalidPropBlockError(app.Logger(), req.Header, "failure to erasure the data square", err)
+		logInvalidPropBlockError(app.proposalLogger(), req.Header, "failure to erasure the data square", err)
 		return reject()
 	}
 
 	dah, err := da.NewDataAvailabilityHeader(eds)
 	if err != nil {
-		logInvalidPropBlockError(app.Logger(), req.Header, "failure to create new data availability header", err)
+		logInvalidPropBlockError(app.proposalLogger(), req.Header, "failure to create new data availability header", err)
 		return reject()
 	}
 	// by comparing the hashes we know the computed IndexWrappers (with the share indexes of the PFB's blobs)
 	// are identical and that square layout is consistent. This also means that the share commitment rules
 	// have been followed and thus each blobs share commitment should be valid
 	if !bytes.Equal(dah.Hash(), req.Header.DataHash) {
-		logInvalidPropBlock(app.Logger(), req.Header, "proposed data root differs from calculated data root")
+		logInvalidPropBlock(app.proposalLogger(), req.Header, "proposed data root differs from calculated data root")
 		return reject()
 	}
 
 	return accept()
 }
 
+// proposalLogger returns the logger used for proposal handling.
+func (app *App) proposalLogger() log.Logger {
+	return app.Logger().With("module", ProposalLogModule)
+}
+
 func hasPFB(msgs []sdk.Msg) (*blobtypes.MsgPayForBlobs, bool) {
 	for _, msg := range msgs {
 		if pfb, ok := msg.(*blobtypes.MsgPayForBlobs); ok {
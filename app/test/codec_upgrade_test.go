@@ -0,0 +1,61 @@
+package app_test
+
+import (
+	"testing"
+
+	"github.com/celestiaorg/celestia-app/app"
+	"github.com/celestiaorg/celestia-app/pkg/appconsts"
+	v1 "github.com/celestiaorg/celestia-app/pkg/appconsts/v1"
+	v2 "github.com/celestiaorg/celestia-app/pkg/appconsts/v2"
+	"github.com/celestiaorg/celestia-app/pkg/da"
+	appns "github.com/celestiaorg/celestia-app/pkg/namespace"
+	"github.com/celestiaorg/celestia-app/pkg/proof"
+	"github.com/celestiaorg/celestia-app/pkg/shares"
+	"github.com/celestiaorg/celestia-app/pkg/square"
+	"github.com/celestiaorg/celestia-app/test/util/testfactory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	coretypes "github.com/tendermint/tendermint/types"
+)
+
+// TestExtendBlockCodecUpgrade switches v2 to another codec and checks that
+// blocks and share proofs of each version are built with the codec of that
+// version: the same transactions commit to different data roots in v1 and v2,
+// and a share proof only validates against the data root of its own version.
+func TestExtendBlockCodecUpgrade(t *testing.T) {
+	reversed := appconsts.NewReversedLeopardCodec()
+	appconsts.SetCodec(t, reversed)
+	consts := appconsts.Versioned(v2.Version)
+	consts.Codec = reversed.Name()
+	appconsts.SetVersionedConsts(t, consts)
+	require.NotEqual(t, appconsts.Codec(v1.Version).Name(), appconsts.Codec(v2.Version).Name())
+
+	txs := testfactory.GenerateRandomTxs(50, 500)
+	versions := []uint64{v1.Version, v2.Version}
+	dataRoots := make(map[uint64][]byte, len(versions))
+	proofs := make(map[uint64]coretypes.ShareProof, len(versions))
+	for _, version := range versions {
+		eds, err := app.ExtendBlock(coretypes.Data{Txs: txs}, version)
+		require.NoError(t, err, "v%d", version)
+		dah, err := da.NewDataAvailabilityHeader(eds)
+		require.NoError(t, err, "v%d", version)
+		dataRoots[version] = dah.Hash()
+
+		dataSquare, err := square.Construct(txs.ToSliceOfBytes(), version, appconsts.SquareSizeUpperBound(version))
+		require.NoError(t, err, "v%d", version)
+		proofs[version], err = proof.NewShareInclusionProof(dataSquare, appns.TxNamespace, shares.NewRange(0, 1), version)
+		require.NoError(t, err, "v%d", version)
+	}
+
+	assert.NotEqual(t, dataRoots[v1.Version], dataRoots[v2.Version])
+	for _, version := range versions {
+		for _, root := range versions {
+			share := proofs[version]
+			if version == root {
+				assert.NoError(t, share.Validate(dataRoots[root]), "v%d proof against v%d data root", version, root)
+			} else {
+				assert.Error(t, share.Validate(dataRoots[root]), "v%d proof against v%d data root", version, root)
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package app_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/celestiaorg/celestia-app/app"
+	testutil "github.com/celestiaorg/celestia-app/test/util"
+	"github.com/celestiaorg/celestia-app/test/util/genesis"
+	"github.com/celestiaorg/celestia-app/test/util/testnode"
+	blobtypes "github.com/celestiaorg/celestia-app/x/blob/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+)
+
+func TestExportModulesGenesis(t *testing.T) {
+	testApp, _ := testutil.SetupTestAppWithGenesisValSet(app.DefaultConsensusParams(), "a", "b", "c")
+
+	var buf bytes.Buffer
+	err := testApp.ExportModulesGenesis(&buf, []string{banktypes.ModuleName, blobtypes.ModuleName})
+	require.NoError(t, err)
+
+	var states map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &states))
+	require.Len(t, states, 2)
+
+	ctx := testApp.NewContext(true, tmproto.Header{Height: testApp.LastBlockHeight()})
+	cdc := testApp.AppCodec()
+
+	// the streamed bank genesis must match the one exported by the bank module
+	var gotBank banktypes.GenesisState
+	require.NoError(t, cdc.UnmarshalJSON(states[banktypes.ModuleName], &gotBank))
+	wantBank := testApp.BankKeeper.ExportGenesis(ctx)
+	assert.JSONEq(t, string(cdc.MustMarshalJSON(&wantBank.Params)), string(cdc.MustMarshalJSON(&gotBank.Params)))
+	assert.Equal(t, wantBank.Supply, gotBank.Supply)
+	assert.Equal(t, wantBank.Balances, gotBank.Balances)
+	assert.ElementsMatch(t, wantBank.DenomMetadata, gotBank.DenomMetadata)
+
+	var gotBlob blobtypes.GenesisState
+	require.NoError(t, cdc.UnmarshalJSON(states[blobtypes.ModuleName], &gotBlob))
+	assert.Equal(t, testApp.BlobKeeper.GetParams(ctx), gotBlob.Params)
+
+	err = testApp.ExportModulesGenesis(&buf, []string{"unknown"})
+	assert.Error(t, err)
+}
+
+func TestImportModulesGenesis(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping module genesis import test in short mode.")
+	}
+	testApp, _ := testutil.SetupTestAppWithGenesisValSet(app.DefaultConsensusParams(), "a")
+	ctx := testApp.NewContext(true, tmproto.Header{Height: testApp.LastBlockHeight()})
+	params := testApp.BlobKeeper.GetParams(ctx)
+	params.GasPerBlobByte++
+	testApp.BlobKeeper.SetParams(ctx, params)
+
+	var buf bytes.Buffer
+	require.NoError(t, testApp.ExportModulesGenesis(&buf, []string{blobtypes.ModuleName}))
+	path := filepath.Join(t.TempDir(), "blob.json")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+
+	states, err := genesis.ReadModuleStates(path)
+	require.NoError(t, err)
+	cfg := testnode.DefaultConfig().WithModifiers(genesis.ImportModuleStates(states))
+	cctx, _, _ := testnode.NewNetwork(t, cfg)
+	require.NoError(t, cctx.WaitForNextBlock())
+
+	resp, err := blobtypes.NewQueryClient(cctx.GRPCClient).Params(cctx.GoContext(), &blobtypes.QueryParamsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, params, resp.Params)
+}
@@ -0,0 +1,285 @@
+package app_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/celestiaorg/celestia-app/app"
+	testutil "github.com/celestiaorg/celestia-app/test/util"
+	"github.com/celestiaorg/celestia-app/x/upgrade"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/server/api"
+	"github.com/cosmos/cosmos-sdk/server/config"
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/p2p"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	coretypes "github.com/tendermint/tendermint/rpc/core/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeStatusClient returns a fixed status of the consensus node. The other
+// methods of the client are not implemented.
+type fakeStatusClient struct {
+	rpcclient.Client
+
+	status *coretypes.ResultStatus
+}
+
+func (c fakeStatusClient) Status(context.Context) (*coretypes.ResultStatus, error) {
+	return c.status, nil
+}
+
+func TestHealth(t *testing.T) {
+	testApp, _ := testutil.SetupTestAppWithGenesisValSet(app.DefaultConsensusParams())
+	nonValidator := ed25519.GenPrivKey().PubKey().Address()
+
+	type testCase struct {
+		name       string
+		status     *coretypes.ResultStatus
+		healthy    bool
+		failedName string
+	}
+	testCases := []testCase{
+		{
+			name: "caught up non validator",
+			status: &coretypes.ResultStatus{
+				NodeInfo:      p2p.DefaultNodeInfo{Network: testutil.ChainID},
+				ValidatorInfo: coretypes.ValidatorInfo{Address: nonValidator},
+			},
+			healthy: true,
+		},
+		{
+			name: "known network without upgrade schedule",
+			status: &coretypes.ResultStatus{
+				NodeInfo:      p2p.DefaultNodeInfo{Network: "celestia"},
+				ValidatorInfo: coretypes.ValidatorInfo{Address: nonValidator},
+			},
+			healthy: true,
+		},
+		{
+			name: "catching up",
+			status: &coretypes.ResultStatus{
+				NodeInfo:      p2p.DefaultNodeInfo{Network: testutil.ChainID},
+				SyncInfo:      coretypes.SyncInfo{CatchingUp: true},
+				ValidatorInfo: coretypes.ValidatorInfo{Address: nonValidator},
+			},
+			healthy:    false,
+			failedName: "sync",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			report := testApp.Health(context.Background(), fakeStatusClient{status: tc.status})
+			require.Equal(t, tc.healthy, report.Healthy, report)
+			assert.Equal(t, tc.status.NodeInfo.Network, report.ChainID)
+			assert.Equal(t, testApp.AppVersion(), report.AppVersion)
+			for _, check := range report.Checks {
+				if check.Name == tc.failedName {
+					assert.False(t, check.Healthy)
+				} else {
+					assert.True(t, check.Healthy, check)
+				}
+			}
+		})
+	}
+
+	report := testApp.Health(context.Background(), nil)
+	assert.False(t, report.Healthy)
+}
+
+func TestHealthUpgradeSchedule(t *testing.T) {
+	testApp, _ := testutil.SetupTestAppWithGenesisValSet(app.DefaultConsensusParams())
+	testApp.UpgradeKeeper = upgrade.NewKeeper(testApp.GetKey(upgrade.StoreKey), map[string]upgrade.Schedule{
+		"celestia": upgrade.NewSchedule(upgrade.NewPlan(100, 200, 2)),
+	})
+	nonValidator := ed25519.GenPrivKey().PubKey().Address()
+
+	testCases := []struct {
+		name    string
+		chainID string
+		healthy bool
+		message string
+	}{
+		{
+			name:    "configured schedule differs from the network",
+			chainID: "celestia",
+			healthy: false,
+			message: "configured upgrade schedule [celestia:100:200:2], differs from the upgrade schedule [] of celestia",
+		},
+		{
+			name:    "no schedule configured for a known network",
+			chainID: "mocha-4",
+			healthy: true,
+			message: "no upgrade schedule is configured, matches the upgrade schedule [] of mocha-4",
+		},
+		{
+			name:    "unknown network",
+			chainID: testutil.ChainID,
+			healthy: true,
+			message: "no upgrade schedule is configured, " + testutil.ChainID + " is not a known network",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			report := testApp.Health(context.Background(), fakeStatusClient{status: &coretypes.ResultStatus{
+				NodeInfo:      p2p.DefaultNodeInfo{Network: tc.chainID},
+				ValidatorInfo: coretypes.ValidatorInfo{Address: nonValidator},
+			}})
+			assert.Equal(t, tc.healthy, report.Healthy, report)
+			var found bool
+			for _, check := range report.Checks {
+				if check.Name == "upgrade_schedule" {
+					found = true
+					assert.Equal(t, tc.healthy, check.Healthy)
+					assert.Equal(t, tc.message, check.Message)
+				}
+			}
+			assert.True(t, found, report)
+		})
+	}
+}
+
+func TestHealthEVMAddress(t *testing.T) {
+	testApp, _ := testutil.SetupTestAppWithGenesisValSet(app.DefaultConsensusParams())
+	ctx := testApp.NewContext(false, tmproto.Header{})
+	validators := testApp.StakingKeeper.GetAllValidators(ctx)
+	require.Len(t, validators, 1)
+	validator := validators[0]
+	consAddr, err := validator.GetConsAddr()
+	require.NoError(t, err)
+	status := &coretypes.ResultStatus{
+		NodeInfo:      p2p.DefaultNodeInfo{Network: testutil.ChainID},
+		ValidatorInfo: coretypes.ValidatorInfo{Address: consAddr.Bytes()},
+	}
+
+	evmAddressCheck := func(t *testing.T) app.HealthCheck {
+		report := testApp.Health(context.Background(), fakeStatusClient{status: status})
+		for _, check := range report.Checks {
+			if check.Name == "evm_address" {
+				assert.Equal(t, check.Healthy, report.Healthy, report)
+				return check
+			}
+		}
+		require.FailNow(t, "no evm_address check", report)
+		return app.HealthCheck{}
+	}
+
+	t.Run("validator with the default EVM address", func(t *testing.T) {
+		check := evmAddressCheck(t)
+		assert.False(t, check.Healthy)
+		assert.Equal(t, "validator "+validator.GetOperator().String()+" has not registered an EVM address", check.Message)
+	})
+
+	evmAddress := gethcommon.BytesToAddress(ed25519.GenPrivKey().PubKey().Address())
+	testApp.BlobstreamKeeper.SetEVMAddress(ctx, validator.GetOperator(), evmAddress)
+	testApp.Commit()
+
+	t.Run("validator with a registered EVM address", func(t *testing.T) {
+		check := evmAddressCheck(t)
+		assert.True(t, check.Healthy)
+		assert.Equal(t, evmAddress.Hex(), check.Message)
+	})
+}
+
+// healthStatuses are the statuses of a healthy and an unhealthy node that
+// isn't a validator.
+var healthStatuses = []struct {
+	name    string
+	status  *coretypes.ResultStatus
+	healthy bool
+}{
+	{
+		name: "caught up",
+		status: &coretypes.ResultStatus{
+			NodeInfo:      p2p.DefaultNodeInfo{Network: testutil.ChainID},
+			ValidatorInfo: coretypes.ValidatorInfo{Address: ed25519.GenPrivKey().PubKey().Address()},
+		},
+		healthy: true,
+	},
+	{
+		name: "catching up",
+		status: &coretypes.ResultStatus{
+			NodeInfo:      p2p.DefaultNodeInfo{Network: testutil.ChainID},
+			SyncInfo:      coretypes.SyncInfo{CatchingUp: true},
+			ValidatorInfo: coretypes.ValidatorInfo{Address: ed25519.GenPrivKey().PubKey().Address()},
+		},
+		healthy: false,
+	},
+}
+
+func TestHealthHandler(t *testing.T) {
+	for _, tc := range healthStatuses {
+		t.Run(tc.name, func(t *testing.T) {
+			testApp, _ := testutil.SetupTestAppWithGenesisValSet(app.DefaultConsensusParams())
+			clientCtx := client.Context{}.
+				WithClient(fakeStatusClient{status: tc.status}).
+				WithInterfaceRegistry(testApp.InterfaceRegistry())
+			apiSvr := api.New(clientCtx, log.NewNopLogger())
+			testApp.RegisterAPIRoutes(apiSvr, config.APIConfig{})
+
+			recorder := httptest.NewRecorder()
+			apiSvr.Router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, app.HealthRoute, nil))
+
+			expectedCode := http.StatusOK
+			if !tc.healthy {
+				expectedCode = http.StatusServiceUnavailable
+			}
+			assert.Equal(t, expectedCode, recorder.Code)
+			assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+			var report app.HealthReport
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+			assert.Equal(t, tc.healthy, report.Healthy)
+			assert.Equal(t, testutil.ChainID, report.ChainID)
+			assert.Equal(t, testApp.LastBlockHeight(), report.Height)
+			assert.Equal(t, testApp.AppVersion(), report.AppVersion)
+			assert.NotEmpty(t, report.Checks)
+		})
+	}
+}
+
+func TestHealthGRPC(t *testing.T) {
+	for _, tc := range healthStatuses {
+		t.Run(tc.name, func(t *testing.T) {
+			testApp, _ := testutil.SetupTestAppWithGenesisValSet(app.DefaultConsensusParams())
+			clientCtx := client.Context{}.
+				WithClient(fakeStatusClient{status: tc.status}).
+				WithInterfaceRegistry(testApp.InterfaceRegistry())
+			testApp.RegisterTendermintService(clientCtx)
+			server := grpc.NewServer()
+			testApp.RegisterGRPCServer(server)
+
+			listener := bufconn.Listen(1024 * 1024)
+			go func() {
+				_ = server.Serve(listener)
+			}()
+			t.Cleanup(server.Stop)
+			conn, err := grpc.Dial("bufnet",
+				grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+				grpc.WithTransportCredentials(insecure.NewCredentials()),
+			)
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = conn.Close() })
+
+			resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+			require.NoError(t, err)
+			expected := healthpb.HealthCheckResponse_SERVING
+			if !tc.healthy {
+				expected = healthpb.HealthCheckResponse_NOT_SERVING
+			}
+			assert.Equal(t, expected, resp.Status)
+		})
+	}
+}
@@ -286,7 +286,7 @@ func TestProcessProposal(t *testing.T) {
 				require.NoError(t, err)
 				dataSquare[1] = *updatedShare
 
-				eds, err := da.ExtendShares(shares.ToBytes(dataSquare))
+				eds, err := da.ExtendShares(shares.ToBytes(dataSquare), appconsts.LatestVersion)
 				require.NoError(t, err)
 
 				dah, err := da.NewDataAvailabilityHeader(eds)
@@ -328,7 +328,7 @@ func TestProcessProposal(t *testing.T) {
 func calculateNewDataHash(t *testing.T, txs [][]byte) []byte {
 	dataSquare, err := square.Construct(txs, appconsts.LatestVersion, appconsts.DefaultSquareSizeUpperBound)
 	require.NoError(t, err)
-	eds, err := da.ExtendShares(shares.ToBytes(dataSquare))
+	eds, err := da.ExtendShares(shares.ToBytes(dataSquare), appconsts.LatestVersion)
 	require.NoError(t, err)
 	dah, err := da.NewDataAvailabilityHeader(eds)
 	require.NoError(t, err)
@@ -0,0 +1,226 @@
+package app_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/celestiaorg/celestia-app/app"
+	"github.com/celestiaorg/celestia-app/app/ante"
+	"github.com/celestiaorg/celestia-app/app/encoding"
+	"github.com/celestiaorg/celestia-app/pkg/da"
+	"github.com/celestiaorg/celestia-app/pkg/shares"
+	"github.com/celestiaorg/celestia-app/pkg/square"
+	"github.com/celestiaorg/celestia-app/pkg/user"
+	testutil "github.com/celestiaorg/celestia-app/test/util"
+	"github.com/celestiaorg/celestia-app/test/util/blobfactory"
+	"github.com/celestiaorg/celestia-app/test/util/testfactory"
+	blobtypes "github.com/celestiaorg/celestia-app/x/blob/types"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/log"
+	tmrand "github.com/tendermint/tendermint/libs/rand"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+)
+
+// proposalBenchmark describes the txs of the block of a benchmark. Every tx is
+// signed by its own account.
+type proposalBenchmark struct {
+	name       string
+	normalTxs  int
+	blobTxs    int
+	blobsPerTx int
+	blobSize   int
+}
+
+// proposalBenchmarks cover squares of different sizes filled with different
+// mixes of txs. The square size of each block is reported by the benchmarks.
+var proposalBenchmarks = []proposalBenchmark{
+	{name: "few blobs", blobTxs: 10, blobsPerTx: 1, blobSize: 2000},
+	{name: "sends", normalTxs: 2000},
+	{name: "mixed", normalTxs: 100, blobTxs: 100, blobsPerTx: 2, blobSize: 1000},
+	{name: "many small blobs", blobTxs: 1000, blobsPerTx: 1, blobSize: 500},
+	{name: "multi blob txs", blobTxs: 100, blobsPerTx: 4, blobSize: 2000},
+	{name: "large blobs", blobTxs: 2, blobsPerTx: 1, blobSize: 800_000},
+}
+
+// proposalSetup is an app with the accounts of a benchmark and the txs of its
+// block.
+type proposalSetup struct {
+	app *app.App
+	txs [][]byte
+}
+
+var (
+	proposalSetupsMtx sync.Mutex
+	proposalSetups    = make(map[string]*proposalSetup)
+)
+
+// setupProposalBenchmark returns the app and block of the benchmark. Setting
+// up is expensive, so every benchmark is only set up once no matter how often
+// it is run.
+func setupProposalBenchmark(b *testing.B, bm proposalBenchmark) *proposalSetup {
+	b.Helper()
+	proposalSetupsMtx.Lock()
+	defer proposalSetupsMtx.Unlock()
+	if setup, ok := proposalSetups[bm.name]; ok {
+		return setup
+	}
+
+	accounts := testfactory.GenerateAccounts(bm.blobTxs + bm.normalTxs)
+	testApp, kr := testutil.SetupTestAppWithGenesisValSet(app.DefaultConsensusParams(), accounts...)
+	encCfg := encoding.MakeConfig(app.ModuleEncodingRegisters...)
+	rand := tmrand.NewRand()
+	rand.Seed(1)
+
+	txs := make([][]byte, 0, len(accounts))
+	for i, account := range accounts {
+		addr := testfactory.GetAddress(kr, account)
+		acc := testutil.DirectQueryAccount(testApp, addr)
+		signer, err := user.NewSigner(kr, nil, addr, encCfg.TxConfig, testutil.ChainID, acc.GetAccountNumber(), acc.GetSequence())
+		require.NoError(b, err)
+
+		if i >= bm.blobTxs {
+			txs = append(txs, blobfactory.GenerateRawSendTx(signer, 100))
+			continue
+		}
+		blobs := blobfactory.ManyRandBlobs(rand, blobfactory.Repeat(bm.blobSize, bm.blobsPerTx)...)
+		blobSizes := make([]uint32, len(blobs))
+		for j, blob := range blobs {
+			blobSizes[j] = uint32(len(blob.Data))
+		}
+		tx, err := signer.CreatePayForBlob(blobs, blobfactory.FeeTxOpts(blobtypes.DefaultEstimateGas(blobSizes))...)
+		require.NoError(b, err)
+		txs = append(txs, tx)
+	}
+
+	setup := &proposalSetup{app: testApp, txs: txs}
+	proposalSetups[bm.name] = setup
+	return setup
+}
+
+func (s *proposalSetup) prepareProposalRequest() abci.RequestPrepareProposal {
+	return abci.RequestPrepareProposal{
+		BlockData: &tmproto.Data{Txs: s.txs},
+		ChainId:   testutil.ChainID,
+		Height:    s.app.LastBlockHeight() + 1,
+		Time:      time.Now(),
+	}
+}
+
+func BenchmarkPrepareProposal(b *testing.B) {
+	for _, bm := range proposalBenchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			setup := setupProposalBenchmark(b, bm)
+			req := setup.prepareProposalRequest()
+
+			var resp abci.ResponsePrepareProposal
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				resp = setup.app.PrepareProposal(req)
+			}
+			b.StopTimer()
+			require.Len(b, resp.BlockData.Txs, len(setup.txs), "the block must fit all txs")
+			b.ReportMetric(float64(resp.BlockData.SquareSize), "square_size")
+		})
+	}
+}
+
+func BenchmarkProcessProposal(b *testing.B) {
+	for _, bm := range proposalBenchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			setup := setupProposalBenchmark(b, bm)
+			prepareReq := setup.prepareProposalRequest()
+			prepareResp := setup.app.PrepareProposal(prepareReq)
+			req := abci.RequestProcessProposal{
+				BlockData: prepareResp.BlockData,
+				Header: tmproto.Header{
+					ChainID:  testutil.ChainID,
+					Height:   prepareReq.Height,
+					Time:     prepareReq.Time,
+					DataHash: prepareResp.BlockData.Hash,
+				},
+			}
+
+			var resp abci.ResponseProcessProposal
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				resp = setup.app.ProcessProposal(req)
+			}
+			b.StopTimer()
+			require.Equal(b, abci.ResponseProcessProposal_ACCEPT, resp.Result)
+			b.ReportMetric(float64(prepareResp.BlockData.SquareSize), "square_size")
+		})
+	}
+}
+
+// BenchmarkProposalStages measures the stages of preparing a proposal
+// separately: filtering the txs, building the square, extending it and
+// computing the data availability header.
+func BenchmarkProposalStages(b *testing.B) {
+	for _, bm := range proposalBenchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			setup := setupProposalBenchmark(b, bm)
+			testApp := setup.app
+			header := tmproto.Header{ChainID: testutil.ChainID, Height: testApp.LastBlockHeight() + 1, Time: time.Now()}
+			handler := ante.NewAnteHandler(
+				testApp.AccountKeeper,
+				testApp.BankKeeper,
+				testApp.BlobKeeper,
+				testApp.FeeGrantKeeper,
+				testApp.GetTxConfig().SignModeHandler(),
+				ante.DefaultSigVerificationGasConsumer,
+				testApp.IBCKeeper,
+			)
+			appVersion := testApp.GetBaseApp().AppVersion()
+			maxSquareSize := testApp.GovSquareSizeUpperBound(testApp.NewProposalContext(header))
+
+			filteredTxs := app.FilterTxs(log.NewNopLogger(), testApp.NewProposalContext(header), handler, testApp.GetTxConfig(), setup.txs)
+			require.Len(b, filteredTxs, len(setup.txs))
+			dataSquare, _, err := square.Build(filteredTxs, appVersion, maxSquareSize)
+			require.NoError(b, err)
+
+			b.Run("FilterTxs", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					// every filter needs a fresh branch of the state as
+					// filtering increments the sequences of the accounts
+					b.StopTimer()
+					ctx := testApp.NewProposalContext(header)
+					b.StartTimer()
+					app.FilterTxs(log.NewNopLogger(), ctx, handler, testApp.GetTxConfig(), setup.txs)
+				}
+			})
+			b.Run("BuildSquare", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					_, _, err := square.Build(filteredTxs, appVersion, maxSquareSize)
+					require.NoError(b, err)
+				}
+				b.ReportMetric(float64(dataSquare.Size()), "square_size")
+			})
+			b.Run("ExtendSquare", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					_, err := da.ExtendShares(shares.ToBytes(dataSquare), appVersion)
+					require.NoError(b, err)
+				}
+			})
+			b.Run("DAH", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					// the extended square caches its roots, so every header
+					// is computed from a fresh square
+					b.StopTimer()
+					eds, err := da.ExtendShares(shares.ToBytes(dataSquare), appVersion)
+					require.NoError(b, err)
+					b.StartTimer()
+					_, err = da.NewDataAvailabilityHeader(eds)
+					require.NoError(b, err)
+				}
+			})
+		})
+	}
+}
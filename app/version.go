@@ -2,6 +2,7 @@ package app
 
 import (
 	"fmt"
+	"strconv"
 
 	v1 "github.com/celestiaorg/celestia-app/pkg/appconsts/v1"
 	v2 "github.com/celestiaorg/celestia-app/pkg/appconsts/v2"
@@ -60,9 +61,29 @@ var (
 
 const DefaultInitialVersion = v1.Version
 
+// maxSupportedVersion lowers the highest app version supported by the binary
+// if set at build time with
+// -ldflags "-X github.com/celestiaorg/celestia-app/app.maxSupportedVersion=1".
+// It is used to build binaries that don't support newer versions, for example
+// to test that such a node halts when the network upgrades.
+var maxSupportedVersion string
+
 // this is used as a compile time consistency check across different module
 // based maps
 func init() {
+	if maxSupportedVersion != "" {
+		maxVersion, err := strconv.ParseUint(maxSupportedVersion, 10, 64)
+		if err != nil {
+			panic(fmt.Sprintf("invalid max supported version %q: %v", maxSupportedVersion, err))
+		}
+		versions := make([]uint64, 0, len(supportedVersions))
+		for _, v := range supportedVersions {
+			if v <= maxVersion {
+				versions = append(versions, v)
+			}
+		}
+		supportedVersions = versions
+	}
 	for moduleName := range ModuleBasics {
 		for _, v := range supportedVersions {
 			versionMap := GetModuleVersion(v)
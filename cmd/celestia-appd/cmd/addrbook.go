@@ -1,9 +1,14 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"net"
 	"os"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/celestiaorg/celestia-app/app"
 	"github.com/spf13/cobra"
@@ -11,6 +16,25 @@ import (
 	"github.com/tendermint/tendermint/p2p/pex"
 )
 
+const (
+	// FlagRoutableOnly specifies whether unroutable addresses should be
+	// removed when merging address books.
+	FlagRoutableOnly = "routable-only"
+	// FlagProbeTimeout is the timeout used when dialing a peer.
+	FlagProbeTimeout = "timeout"
+	// FlagProbeOutput is the path an address book containing only the live
+	// peers is written to.
+	FlagProbeOutput = "output"
+	// FlagProbeMaxPeers limits the number of peers printed by the probe.
+	FlagProbeMaxPeers = "max-peers"
+	// FlagProbeFormat is the config.toml key that the live peers are printed
+	// for. Must be one of seeds or persistent_peers.
+	FlagProbeFormat = "format"
+
+	defaultProbeTimeout     = 5 * time.Second
+	defaultProbeConcurrency = 32
+)
+
 func addrbookCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "addrbook peers.txt addrbook.json",
@@ -53,5 +77,257 @@ func addrbookCommand() *cobra.Command {
 		},
 	}
 
+	cmd.AddCommand(
+		addrbookMergeCommand(),
+		addrbookProbeCommand(),
+	)
+
+	return cmd
+}
+
+func addrbookMergeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "merge output.json input.json [input.json...]",
+		Short: "Merge and dedupe one or more address books",
+		Long: "Merge and dedupe one or more address books.\n" +
+			"The first argument is the path the merged address book is written to. All following arguments are address books to merge.\n" +
+			"Addresses are deduplicated by node ID. If a node ID appears more than once, the first occurrence is kept.\n",
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outputFile := args[0]
+			routableOnly, err := cmd.Flags().GetBool(FlagRoutableOnly)
+			if err != nil {
+				return err
+			}
+
+			books := make([][]*p2p.NetAddress, 0, len(args)-1)
+			for _, inputFile := range args[1:] {
+				addrs, err := readAddrBook(inputFile)
+				if err != nil {
+					return fmt.Errorf("reading %s: %w", inputFile, err)
+				}
+				books = append(books, addrs)
+			}
+
+			addrs := dedupeAddresses(books...)
+			if routableOnly {
+				var unroutable []*p2p.NetAddress
+				addrs, unroutable = filterRoutable(addrs)
+				for _, addr := range unroutable {
+					fmt.Printf("Removed unroutable address %s\n", addr)
+				}
+			}
+
+			writeAddrBook(outputFile, addrs, routableOnly)
+			fmt.Printf("Merged %d address books into %s (%d addresses)\n", len(books), outputFile, len(addrs))
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool(FlagRoutableOnly, true, "Remove addresses that are not routable on the public internet")
+	return cmd
+}
+
+func addrbookProbeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "probe addrbook.json",
+		Short: "Probe the peers of an address book and rank them by latency",
+		Long: "Probe the peers of an address book and rank them by latency.\n" +
+			"A TCP connection is attempted with every peer in the address book. Peers that can't be reached are reported as dead.\n" +
+			"The probe only checks that a peer accepts connections. It doesn't perform a p2p handshake, so it doesn't verify that the peer has the node ID of its address.\n" +
+			"The live peers are printed, fastest first, as a seeds or persistent_peers string that can be copied into config.toml.\n",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			timeout, err := cmd.Flags().GetDuration(FlagProbeTimeout)
+			if err != nil {
+				return err
+			}
+			outputFile, err := cmd.Flags().GetString(FlagProbeOutput)
+			if err != nil {
+				return err
+			}
+			maxPeers, err := cmd.Flags().GetInt(FlagProbeMaxPeers)
+			if err != nil {
+				return err
+			}
+			format, err := cmd.Flags().GetString(FlagProbeFormat)
+			if err != nil {
+				return err
+			}
+			if format != "seeds" && format != "persistent_peers" {
+				return fmt.Errorf("unknown format %s. Must be: seeds or persistent_peers", format)
+			}
+
+			addrs, err := readAddrBook(args[0])
+			if err != nil {
+				return err
+			}
+
+			results := probeAddresses(netDialer{}, addrs, timeout, defaultProbeConcurrency)
+			live := make([]*p2p.NetAddress, 0, len(results))
+			for _, result := range results {
+				if result.Err != nil {
+					fmt.Printf("dead %s: %s\n", result.Addr, result.Err)
+					continue
+				}
+				fmt.Printf("live %s: %s\n", result.Addr, result.Latency)
+				live = append(live, result.Addr)
+			}
+			fmt.Printf("%d of %d peers are live\n", len(live), len(addrs))
+
+			if outputFile != "" {
+				// The live peers were just reached, so they are kept even if
+				// they are not routable on the public internet.
+				writeAddrBook(outputFile, live, false)
+				fmt.Printf("Saved live peers to %s\n", outputFile)
+			}
+
+			if maxPeers > 0 && len(live) > maxPeers {
+				live = live[:maxPeers]
+			}
+			fmt.Printf("%s = \"%s\"\n", format, peersString(live))
+			return nil
+		},
+	}
+
+	cmd.Flags().Duration(FlagProbeTimeout, defaultProbeTimeout, "Timeout for dialing a single peer")
+	cmd.Flags().String(FlagProbeOutput, "", "Write an address book containing only the live peers to this file")
+	cmd.Flags().Int(FlagProbeMaxPeers, 0, "Maximum number of peers to print. 0 means no limit")
+	cmd.Flags().String(FlagProbeFormat, "seeds", "The config.toml key to print the live peers for. Must be: seeds or persistent_peers")
 	return cmd
 }
+
+// addrBookFile is the subset of the address book JSON format written by the
+// pex reactor that is needed to read the addresses back.
+type addrBookFile struct {
+	Key   string `json:"key"`
+	Addrs []struct {
+		Addr *p2p.NetAddress `json:"addr"`
+	} `json:"addrs"`
+}
+
+// readAddrBook reads the addresses stored in the address book at path.
+func readAddrBook(path string) ([]*p2p.NetAddress, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var book addrBookFile
+	if err := json.Unmarshal(data, &book); err != nil {
+		return nil, err
+	}
+	addrs := make([]*p2p.NetAddress, 0, len(book.Addrs))
+	for _, ka := range book.Addrs {
+		if ka.Addr == nil {
+			continue
+		}
+		addrs = append(addrs, ka.Addr)
+	}
+	return addrs, nil
+}
+
+// writeAddrBook saves addrs to a new address book at path. A strict address
+// book silently drops addresses that are not routable on the public internet.
+func writeAddrBook(path string, addrs []*p2p.NetAddress, routabilityStrict bool) {
+	book := pex.NewAddrBook(path, routabilityStrict)
+	for _, addr := range addrs {
+		if err := book.AddAddress(addr, addr); err != nil {
+			fmt.Printf("Error adding %s: %s\n", addr, err)
+		}
+	}
+	book.Save()
+}
+
+// dedupeAddresses merges the provided lists of addresses into one, keeping
+// only the first address seen for every node ID.
+func dedupeAddresses(books ...[]*p2p.NetAddress) []*p2p.NetAddress {
+	seen := make(map[p2p.ID]struct{})
+	addrs := make([]*p2p.NetAddress, 0)
+	for _, book := range books {
+		for _, addr := range book {
+			if _, ok := seen[addr.ID]; ok {
+				continue
+			}
+			seen[addr.ID] = struct{}{}
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// filterRoutable splits addrs into the routable and the unroutable addresses.
+func filterRoutable(addrs []*p2p.NetAddress) (routable, unroutable []*p2p.NetAddress) {
+	for _, addr := range addrs {
+		if addr.Routable() {
+			routable = append(routable, addr)
+		} else {
+			unroutable = append(unroutable, addr)
+		}
+	}
+	return routable, unroutable
+}
+
+// peersString returns addrs as a comma separated list of `id@ip:port` as
+// expected by the seeds and persistent_peers fields in config.toml.
+func peersString(addrs []*p2p.NetAddress) string {
+	peers := make([]string, len(addrs))
+	for i, addr := range addrs {
+		peers[i] = addr.String()
+	}
+	return strings.Join(peers, ",")
+}
+
+// Dialer opens network connections. It allows the probe to be tested without
+// dialing real peers.
+type Dialer interface {
+	DialTimeout(network, address string, timeout time.Duration) (net.Conn, error)
+}
+
+// netDialer is the Dialer used by default. It dials using the net package.
+type netDialer struct{}
+
+func (netDialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	return net.DialTimeout(network, address, timeout)
+}
+
+// probeResult is the outcome of probing a single peer.
+type probeResult struct {
+	Addr    *p2p.NetAddress
+	Latency time.Duration
+	Err     error
+}
+
+// probeAddresses dials every address in addrs using at most concurrency
+// connections at a time. A peer is live if it accepts the connection; its node
+// ID is not verified. The results are sorted such that live peers come
+// first, ordered by latency, followed by dead peers.
+func probeAddresses(dialer Dialer, addrs []*p2p.NetAddress, timeout time.Duration, concurrency int) []probeResult {
+	results := make([]probeResult, len(addrs))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, addr := range addrs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, addr *p2p.NetAddress) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			start := time.Now()
+			conn, err := dialer.DialTimeout("tcp", addr.DialString(), timeout)
+			results[i] = probeResult{Addr: addr, Latency: time.Since(start), Err: err}
+			if err == nil {
+				_ = conn.Close()
+			}
+		}(i, addr)
+	}
+	wg.Wait()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if (results[i].Err == nil) != (results[j].Err == nil) {
+			return results[i].Err == nil
+		}
+		return results[i].Latency < results[j].Latency
+	})
+	return results
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/p2p"
+)
+
+func mustNetAddress(t *testing.T, addr string) *p2p.NetAddress {
+	t.Helper()
+	netAddr, err := p2p.NewNetAddressString(addr)
+	require.NoError(t, err)
+	return netAddr
+}
+
+func TestDedupeAddresses(t *testing.T) {
+	a := mustNetAddress(t, "1111111111111111111111111111111111111111@1.1.1.1:26656")
+	aOther := mustNetAddress(t, "1111111111111111111111111111111111111111@1.1.1.2:26656")
+	b := mustNetAddress(t, "2222222222222222222222222222222222222222@2.2.2.2:26656")
+
+	got := dedupeAddresses([]*p2p.NetAddress{a, b}, []*p2p.NetAddress{aOther, b})
+	assert.Equal(t, []*p2p.NetAddress{a, b}, got)
+}
+
+func TestFilterRoutable(t *testing.T) {
+	public := mustNetAddress(t, "1111111111111111111111111111111111111111@1.1.1.1:26656")
+	private := mustNetAddress(t, "2222222222222222222222222222222222222222@192.168.0.1:26656")
+	local := mustNetAddress(t, "3333333333333333333333333333333333333333@127.0.0.1:26656")
+
+	routable, unroutable := filterRoutable([]*p2p.NetAddress{public, private, local})
+	assert.Equal(t, []*p2p.NetAddress{public}, routable)
+	assert.Equal(t, []*p2p.NetAddress{private, local}, unroutable)
+}
+
+func TestReadWriteAddrBook(t *testing.T) {
+	addrs := []*p2p.NetAddress{
+		mustNetAddress(t, "1111111111111111111111111111111111111111@1.1.1.1:26656"),
+		mustNetAddress(t, "2222222222222222222222222222222222222222@2.2.2.2:26656"),
+	}
+	path := filepath.Join(t.TempDir(), "addrbook.json")
+	writeAddrBook(path, addrs, true)
+
+	got, err := readAddrBook(path)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, addrs, got)
+}
+
+func TestAddrbookMerge(t *testing.T) {
+	public := mustNetAddress(t, "1111111111111111111111111111111111111111@1.1.1.1:26656")
+	private := mustNetAddress(t, "2222222222222222222222222222222222222222@192.168.0.1:26656")
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.json")
+	writeAddrBook(input, []*p2p.NetAddress{public, private}, false)
+
+	testCases := []struct {
+		name         string
+		routableOnly string
+		want         []*p2p.NetAddress
+	}{
+		{
+			name:         "routable only",
+			routableOnly: "true",
+			want:         []*p2p.NetAddress{public},
+		},
+		{
+			name:         "keep unroutable addresses",
+			routableOnly: "false",
+			want:         []*p2p.NetAddress{public, private},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			output := filepath.Join(dir, tc.routableOnly+".json")
+			cmd := addrbookMergeCommand()
+			cmd.SetArgs([]string{output, input, "--" + FlagRoutableOnly + "=" + tc.routableOnly})
+			require.NoError(t, cmd.Execute())
+
+			got, err := readAddrBook(output)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tc.want, got)
+		})
+	}
+}
+
+func TestPeersString(t *testing.T) {
+	addrs := []*p2p.NetAddress{
+		mustNetAddress(t, "1111111111111111111111111111111111111111@1.1.1.1:26656"),
+		mustNetAddress(t, "2222222222222222222222222222222222222222@2.2.2.2:26656"),
+	}
+	want := "1111111111111111111111111111111111111111@1.1.1.1:26656,2222222222222222222222222222222222222222@2.2.2.2:26656"
+	assert.Equal(t, want, peersString(addrs))
+	assert.Equal(t, "", peersString(nil))
+}
+
+// fakeDialer is a Dialer that returns a connection after the configured
+// latency for known addresses and an error for all others.
+type fakeDialer struct {
+	latencies map[string]time.Duration
+}
+
+func (d fakeDialer) DialTimeout(_, address string, _ time.Duration) (net.Conn, error) {
+	latency, ok := d.latencies[address]
+	if !ok {
+		return nil, errors.New("connection refused")
+	}
+	time.Sleep(latency)
+	client, server := net.Pipe()
+	_ = server.Close()
+	return client, nil
+}
+
+func TestProbeAddresses(t *testing.T) {
+	slow := mustNetAddress(t, "1111111111111111111111111111111111111111@1.1.1.1:26656")
+	dead := mustNetAddress(t, "2222222222222222222222222222222222222222@2.2.2.2:26656")
+	fast := mustNetAddress(t, "3333333333333333333333333333333333333333@3.3.3.3:26656")
+
+	dialer := fakeDialer{latencies: map[string]time.Duration{
+		slow.DialString(): 50 * time.Millisecond,
+		fast.DialString(): 0,
+	}}
+
+	results := probeAddresses(dialer, []*p2p.NetAddress{slow, dead, fast}, time.Second, 2)
+	require.Len(t, results, 3)
+	assert.Equal(t, fast, results[0].Addr)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, slow, results[1].Addr)
+	assert.NoError(t, results[1].Err)
+	assert.Equal(t, dead, results[2].Addr)
+	assert.Error(t, results[2].Err)
+}
+
+func TestAddrbookProbeOutputKeepsUnroutablePeers(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	local := mustNetAddress(t, fmt.Sprintf("1111111111111111111111111111111111111111@%s", listener.Addr()))
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.json")
+	writeAddrBook(input, []*p2p.NetAddress{local}, false)
+
+	output := filepath.Join(dir, "live.json")
+	cmd := addrbookProbeCommand()
+	cmd.SetArgs([]string{input, "--" + FlagProbeOutput, output})
+	require.NoError(t, cmd.Execute())
+
+	got, err := readAddrBook(output)
+	require.NoError(t, err)
+	assert.Equal(t, []*p2p.NetAddress{local}, got)
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/celestiaorg/celestia-app/app"
+	bsmoduletypes "github.com/celestiaorg/celestia-app/x/blobstream/types"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/server"
+	"github.com/spf13/cobra"
+	dbm "github.com/tendermint/tm-db"
+)
+
+const (
+	// FlagModules is the comma separated list of modules whose state is
+	// exported. If empty, the full app state is exported.
+	FlagModules = "modules"
+	// FlagOutputDocument is the file the exported module state is written to.
+	FlagOutputDocument = "output-document"
+	// flagTraceStore is the file that KVStore operations are traced to. The
+	// SDK reads it in its export command without registering it.
+	flagTraceStore = "trace-store"
+)
+
+// moduleNameAliases maps the names operators commonly use for a module to the
+// name the module is registered under.
+var moduleNameAliases = map[string]string{
+	"blobstream": bsmoduletypes.ModuleName,
+}
+
+// exportCommand extends the SDK's export command with the ability to export
+// the state of a subset of modules.
+func exportCommand() *cobra.Command {
+	cmd := server.ExportCmd(createAppAndExport, app.DefaultNodeHome)
+	exportAll := cmd.RunE
+	cmd.Long = "Export state to JSON.\n" +
+		"If --modules is provided, only the genesis state of the listed modules is exported as a JSON object keyed by module name. " +
+		"The output can be imported into a test network for reproduction using the genesis.ImportModuleStates modifier.\n"
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		modules, err := cmd.Flags().GetStringSlice(FlagModules)
+		if err != nil {
+			return err
+		}
+		if len(modules) == 0 {
+			return exportAll(cmd, args)
+		}
+		return exportModules(cmd, resolveModuleNames(modules))
+	}
+	cmd.Flags().StringSlice(FlagModules, []string{}, "Comma-separated list of modules to export (e.g. blob,blobstream,mint). If empty, the full app state is exported")
+	cmd.Flags().String(FlagOutputDocument, "", "Write the exported module state to this file instead of stdout. Only used with --modules")
+	cmd.Flags().String(flagTraceStore, "", "Enable KVStore tracing to an output file")
+	return cmd
+}
+
+// exportModules writes the genesis state of modules at the height provided by
+// the height flag to stdout or the output document.
+func exportModules(cmd *cobra.Command, modules []string) error {
+	serverCtx := server.GetServerContextFromCmd(cmd)
+	config := serverCtx.Config
+
+	homeDir, _ := cmd.Flags().GetString(flags.FlagHome)
+	config.SetRoot(homeDir)
+
+	forZeroHeight, _ := cmd.Flags().GetBool(server.FlagForZeroHeight)
+	if forZeroHeight {
+		return fmt.Errorf("--%s can not be combined with --%s", server.FlagForZeroHeight, FlagModules)
+	}
+	height, _ := cmd.Flags().GetInt64(server.FlagHeight)
+
+	db, err := dbm.NewDB("application", server.GetAppDBBackend(serverCtx.Viper), filepath.Join(config.RootDir, "data"))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var traceStore io.Writer
+	if traceStoreFile, _ := cmd.Flags().GetString(flagTraceStore); traceStoreFile != "" {
+		file, err := os.OpenFile(traceStoreFile, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o666)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		traceStore = file
+	}
+
+	capp, err := loadApp(serverCtx.Logger, db, traceStore, height, serverCtx.Viper)
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = cmd.OutOrStdout()
+	outputDocument, _ := cmd.Flags().GetString(FlagOutputDocument)
+	if outputDocument != "" {
+		file, err := os.Create(outputDocument)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if err := capp.ExportModulesGenesis(out, modules); err != nil {
+		return fmt.Errorf("error exporting state: %v", err)
+	}
+	_, err = fmt.Fprintln(out)
+	return err
+}
+
+// resolveModuleNames trims the provided module names and replaces aliases
+// with the name the module is registered under.
+func resolveModuleNames(modules []string) []string {
+	resolved := make([]string, 0, len(modules))
+	for _, module := range modules {
+		module = strings.TrimSpace(module)
+		if module == "" {
+			continue
+		}
+		if name, ok := moduleNameAliases[module]; ok {
+			module = name
+		}
+		resolved = append(resolved, module)
+	}
+	return resolved
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/celestiaorg/celestia-app/app"
+	blobtypes "github.com/celestiaorg/celestia-app/x/blob/types"
+	blobstreamtypes "github.com/celestiaorg/celestia-app/x/blobstream/types"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/server"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/spf13/cobra"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+func genesisCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "genesis",
+		Short: "Genesis file subcommands",
+	}
+	cmd.AddCommand(verifyGenesisCommand())
+	return cmd
+}
+
+func verifyGenesisCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify [file]",
+		Short: "Verify a genesis file against the embedded checksums of known networks",
+		Long: "Verify a genesis file against the embedded checksums of known networks.\n" +
+			"If no argument is provided, the genesis file in the node's config directory is verified.\n" +
+			"The SHA-256 hash of the file is compared to the known hash for its chain-id (celestia, mocha-4, or arabica-10). " +
+			"The genesis file is then validated by every module and a summary of its contents is printed.\n" +
+			"This command does not require network access.\n",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientCtx := client.GetClientContextFromCmd(cmd)
+
+			genesisFile := server.GetServerContextFromCmd(cmd).Config.GenesisFile()
+			if len(args) == 1 {
+				genesisFile = args[0]
+			}
+
+			genDoc, err := tmtypes.GenesisDocFromFile(genesisFile)
+			if err != nil {
+				return fmt.Errorf("error reading genesis file %s: %s", genesisFile, err)
+			}
+
+			hash, err := computeSha256(genesisFile)
+			if err != nil {
+				return fmt.Errorf("error computing sha256 hash: %s", err)
+			}
+			if err := verifyGenesisHash(genDoc.ChainID, hash); err != nil {
+				return err
+			}
+
+			var appState map[string]json.RawMessage
+			if err := json.Unmarshal(genDoc.AppState, &appState); err != nil {
+				return fmt.Errorf("error unmarshalling app state of %s: %s", genesisFile, err)
+			}
+			if err := app.ModuleBasics.ValidateGenesis(clientCtx.Codec, clientCtx.TxConfig, appState); err != nil {
+				return fmt.Errorf("error validating genesis file %s: %s", genesisFile, err)
+			}
+
+			summary, err := summarizeGenesis(clientCtx.Codec, genDoc, appState)
+			if err != nil {
+				return err
+			}
+			summary.SHA256 = hash
+
+			fmt.Printf("File at %s is a valid genesis file\n", genesisFile)
+			summary.Print(clientCtx.Codec)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// verifyGenesisHash returns an error if chainID belongs to a known network and
+// hash does not match the embedded hash of its genesis file. Genesis files of
+// unknown networks are not rejected.
+func verifyGenesisHash(chainID string, hash string) error {
+	knownHash, ok := chainIDToSha256[chainID]
+	if !ok {
+		fmt.Printf("No known SHA-256 hash for chain-id %s, skipping hash verification\n", chainID)
+		return nil
+	}
+	if hash != knownHash {
+		return fmt.Errorf("sha256 hash mismatch for %s: got %s, expected %s", chainID, hash, knownHash)
+	}
+	fmt.Printf("SHA-256 hash verified for %s\n", chainID)
+	return nil
+}
+
+// genesisSummary contains the values of a genesis file that are printed by
+// the verify command.
+type genesisSummary struct {
+	ChainID          string
+	GenesisTime      time.Time
+	SHA256           string
+	AppVersion       uint64
+	ValidatorCount   int
+	TotalSupply      sdk.Coins
+	BlobParams       blobtypes.Params
+	BlobstreamParams blobstreamtypes.Params
+}
+
+// summarizeGenesis extracts a genesisSummary from genDoc. appState must be the
+// decoded app state of genDoc.
+func summarizeGenesis(cdc codec.JSONCodec, genDoc *tmtypes.GenesisDoc, appState map[string]json.RawMessage) (genesisSummary, error) {
+	summary := genesisSummary{
+		ChainID:     genDoc.ChainID,
+		GenesisTime: genDoc.GenesisTime,
+	}
+	if genDoc.ConsensusParams != nil {
+		summary.AppVersion = genDoc.ConsensusParams.Version.AppVersion
+	}
+
+	// Validators are either part of the staking genesis state or created by
+	// the gentxs collected in the genutil genesis state. An exported genesis
+	// also lists the staking validators in the genesis doc, so the validators
+	// of the genesis doc are only counted if the app state has none.
+	stakingGenesis := stakingtypes.GetGenesisStateFromAppState(cdc, appState)
+	genutilGenesis := genutiltypes.GetGenesisStateFromAppState(cdc, appState)
+	summary.ValidatorCount = len(stakingGenesis.Validators) + len(genutilGenesis.GenTxs)
+	if summary.ValidatorCount == 0 {
+		summary.ValidatorCount = len(genDoc.Validators)
+	}
+
+	bankGenesis := banktypes.GetGenesisStateFromAppState(cdc, appState)
+	summary.TotalSupply = bankGenesis.Supply
+	if summary.TotalSupply.Empty() {
+		// The supply is optional in the bank genesis state and computed from
+		// the balances if it isn't set.
+		for _, balance := range bankGenesis.Balances {
+			summary.TotalSupply = summary.TotalSupply.Add(balance.Coins...)
+		}
+	}
+
+	var blobGenesis blobtypes.GenesisState
+	if err := unmarshalModuleGenesis(cdc, appState, blobtypes.ModuleName, &blobGenesis); err != nil {
+		return genesisSummary{}, err
+	}
+	summary.BlobParams = blobGenesis.Params
+
+	var blobstreamGenesis blobstreamtypes.GenesisState
+	if err := unmarshalModuleGenesis(cdc, appState, blobstreamtypes.ModuleName, &blobstreamGenesis); err != nil {
+		return genesisSummary{}, err
+	}
+	if blobstreamGenesis.Params != nil {
+		summary.BlobstreamParams = *blobstreamGenesis.Params
+	}
+
+	return summary, nil
+}
+
+// unmarshalModuleGenesis decodes the genesis state of the module with
+// moduleName into gs. It returns an error if the module has no genesis state.
+func unmarshalModuleGenesis(cdc codec.JSONCodec, appState map[string]json.RawMessage, moduleName string, gs codec.ProtoMarshaler) error {
+	raw, ok := appState[moduleName]
+	if !ok {
+		return fmt.Errorf("genesis state of module %s not found", moduleName)
+	}
+	if err := cdc.UnmarshalJSON(raw, gs); err != nil {
+		return fmt.Errorf("error unmarshalling genesis state of module %s: %s", moduleName, err)
+	}
+	return nil
+}
+
+// Print prints the summary in a human readable format.
+func (s genesisSummary) Print(cdc codec.JSONCodec) {
+	fmt.Printf("Chain ID:            %s\n", s.ChainID)
+	fmt.Printf("Genesis time:        %s\n", s.GenesisTime.UTC().Format(time.RFC3339))
+	if s.SHA256 != "" {
+		fmt.Printf("SHA-256:             %s\n", s.SHA256)
+	}
+	fmt.Printf("Initial app version: %d\n", s.AppVersion)
+	fmt.Printf("Validator count:     %d\n", s.ValidatorCount)
+	fmt.Printf("Total supply:        %s\n", s.TotalSupply)
+	fmt.Printf("Blob params:         %s\n", cdc.MustMarshalJSON(&s.BlobParams))
+	fmt.Printf("Blobstream params:   %s\n", cdc.MustMarshalJSON(&s.BlobstreamParams))
+}
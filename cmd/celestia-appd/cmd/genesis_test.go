@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/celestiaorg/celestia-app/app"
+	"github.com/celestiaorg/celestia-app/app/encoding"
+	blobtypes "github.com/celestiaorg/celestia-app/x/blob/types"
+	blobstreamtypes "github.com/celestiaorg/celestia-app/x/blobstream/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+func TestVerifyGenesisHash(t *testing.T) {
+	knownHash := chainIDToSha256["celestia"]
+
+	assert.NoError(t, verifyGenesisHash("celestia", knownHash))
+	assert.Error(t, verifyGenesisHash("celestia", chainIDToSha256["mocha-4"]))
+	assert.NoError(t, verifyGenesisHash("private", "hash"))
+}
+
+func TestSummarizeGenesis(t *testing.T) {
+	encCfg := encoding.MakeConfig(app.ModuleEncodingRegisters...)
+	appState := app.NewDefaultGenesisState(encCfg.Codec)
+	rawAppState, err := json.Marshal(appState)
+	require.NoError(t, err)
+
+	genesisTime := time.Date(2023, 10, 31, 14, 0, 0, 0, time.UTC)
+	genDoc := &tmtypes.GenesisDoc{
+		ChainID:         "private",
+		GenesisTime:     genesisTime,
+		ConsensusParams: app.DefaultConsensusParams(),
+		AppState:        rawAppState,
+	}
+
+	summary, err := summarizeGenesis(encCfg.Codec, genDoc, appState)
+	require.NoError(t, err)
+	assert.Equal(t, "private", summary.ChainID)
+	assert.Equal(t, genesisTime, summary.GenesisTime)
+	assert.Equal(t, app.DefaultInitialVersion, summary.AppVersion)
+	assert.Equal(t, 0, summary.ValidatorCount)
+	assert.True(t, summary.TotalSupply.Empty())
+	assert.Equal(t, blobtypes.DefaultParams(), summary.BlobParams)
+	assert.Equal(t, *blobstreamtypes.DefaultGenesis().Params, summary.BlobstreamParams)
+
+	delete(appState, blobtypes.ModuleName)
+	_, err = summarizeGenesis(encCfg.Codec, genDoc, appState)
+	assert.Error(t, err)
+}
+
+// TestSummarizeGenesisValidatorCount checks that the validators of an exported
+// genesis, which are listed both in the genesis doc and in the staking genesis
+// state, are counted once.
+func TestSummarizeGenesisValidatorCount(t *testing.T) {
+	encCfg := encoding.MakeConfig(app.ModuleEncodingRegisters...)
+	const numValidators = 3
+
+	stakingGenesis := stakingtypes.DefaultGenesisState()
+	genDocValidators := make([]tmtypes.GenesisValidator, numValidators)
+	for i := 0; i < numValidators; i++ {
+		stakingGenesis.Validators = append(stakingGenesis.Validators, stakingtypes.Validator{
+			OperatorAddress: sdk.ValAddress(ed25519.GenPrivKey().PubKey().Address()).String(),
+			Tokens:          sdk.NewInt(1),
+			DelegatorShares: sdk.OneDec(),
+		})
+		pubKey := ed25519.GenPrivKey().PubKey()
+		genDocValidators[i] = tmtypes.GenesisValidator{Address: pubKey.Address(), PubKey: pubKey, Power: 1}
+	}
+
+	testCases := []struct {
+		name       string
+		staking    *stakingtypes.GenesisState
+		validators []tmtypes.GenesisValidator
+	}{
+		{
+			name:       "exported genesis",
+			staking:    stakingGenesis,
+			validators: genDocValidators,
+		},
+		{
+			name:    "staking validators only",
+			staking: stakingGenesis,
+		},
+		{
+			name:       "genesis doc validators only",
+			staking:    stakingtypes.DefaultGenesisState(),
+			validators: genDocValidators,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			appState := app.NewDefaultGenesisState(encCfg.Codec)
+			appState[stakingtypes.ModuleName] = encCfg.Codec.MustMarshalJSON(tc.staking)
+			genDoc := &tmtypes.GenesisDoc{
+				ChainID:         "private",
+				ConsensusParams: app.DefaultConsensusParams(),
+				Validators:      tc.validators,
+			}
+
+			summary, err := summarizeGenesis(encCfg.Codec, genDoc, appState)
+			require.NoError(t, err)
+			assert.Equal(t, numValidators, summary.ValidatorCount)
+		})
+	}
+}
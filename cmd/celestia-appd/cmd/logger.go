@@ -0,0 +1,419 @@
+package cmd
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/server"
+	serverconfig "github.com/cosmos/cosmos-sdk/server/config"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	tmflags "github.com/tendermint/tendermint/libs/cli/flags"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+const (
+	// FlagLogToFile specifies whether to log to file or not.
+	FlagLogToFile = "log-to-file"
+	// FlagLogFileMaxSize is the size in megabytes after which the log file is
+	// rotated.
+	FlagLogFileMaxSize = "log-file-max-size"
+	// FlagLogFileMaxAge is the age after which the log file is rotated.
+	FlagLogFileMaxAge = "log-file-max-age"
+	// FlagLogFileMaxBackups is the number of rotated log files to keep.
+	FlagLogFileMaxBackups = "log-file-max-backups"
+	// FlagLogFileCompress specifies whether rotated log files are compressed.
+	FlagLogFileCompress = "log-file-compress"
+	// FlagLogModuleLevels sets the log level of individual modules.
+	FlagLogModuleLevels = "log-module-levels"
+
+	defaultLogFileMaxSize    = 100 // megabytes
+	defaultLogFileMaxBackups = 10
+
+	// rotatedLogTimeFormat is the format of the timestamp appended to the name
+	// of rotated log files. It sorts lexicographically in chronological order.
+	rotatedLogTimeFormat = "2006-01-02T15-04-05.000"
+)
+
+// addLogFlags adds the flags that configure logging to rootCmd. All flags can
+// also be set in app.toml.
+func addLogFlags(rootCmd *cobra.Command) {
+	rootCmd.PersistentFlags().String(FlagLogToFile, "", "Write logs directly to a file. If empty, logs are written to stderr")
+	rootCmd.PersistentFlags().Int(FlagLogFileMaxSize, defaultLogFileMaxSize, "Maximum size in megabytes of the log file before it is rotated. 0 disables size based rotation")
+	rootCmd.PersistentFlags().Duration(FlagLogFileMaxAge, 0, "Maximum age of the log file before it is rotated (e.g. 24h). 0 disables age based rotation")
+	rootCmd.PersistentFlags().Int(FlagLogFileMaxBackups, defaultLogFileMaxBackups, "Maximum number of rotated log files to keep. 0 keeps all rotated log files")
+	rootCmd.PersistentFlags().Bool(FlagLogFileCompress, false, "Compress rotated log files using gzip")
+	rootCmd.PersistentFlags().String(FlagLogModuleLevels, "", "Comma-separated list of module:level pairs (e.g. x/qgb:debug,proposal:error). "+
+		"Modules that are not listed log at --log_level. Supported levels are debug, info, error and none")
+}
+
+// logConfigTemplate documents the log options in app.toml. The options are
+// commented out, such that the flag defaults apply unless they are set.
+var logConfigTemplate = fmt.Sprintf(`###############################################################################
+###                           Log Configuration                             ###
+###############################################################################
+
+# Path of the file that logs are written to. If empty, logs are written to
+# stderr.
+# log-to-file = ""
+
+# Maximum size in megabytes of the log file before it is rotated. 0 disables
+# size based rotation.
+# log-file-max-size = %d
+
+# Maximum age of the log file before it is rotated (e.g. "24h"). 0 disables
+# age based rotation.
+# log-file-max-age = "0s"
+
+# Maximum number of rotated log files to keep. 0 keeps all rotated log files.
+# log-file-max-backups = %d
+
+# Compress rotated log files using gzip.
+# log-file-compress = false
+
+# Comma-separated list of module:level pairs (e.g. "x/qgb:debug,proposal:error").
+# Modules that are not listed log at the log_level of config.toml.
+# log-module-levels = ""
+
+`, defaultLogFileMaxSize, defaultLogFileMaxBackups)
+
+// appConfigTemplate returns the app.toml template of the SDK with the log
+// options added after the base configuration, as they are top level keys.
+func appConfigTemplate() string {
+	template := serverconfig.DefaultConfigTemplate
+	firstTable := strings.Index(template, "\n[")
+	if firstTable < 0 {
+		return template + logConfigTemplate
+	}
+	i := strings.LastIndex(template[:firstTable], "\n\n") + 2
+	return template[:i] + logConfigTemplate + template[i:]
+}
+
+// replaceLogger replaces the default logger if logs are written to a file or
+// if log levels are configured per module.
+func replaceLogger(cmd *cobra.Command) error {
+	sctx := server.GetServerContextFromCmd(cmd)
+	v := sctx.Viper
+
+	logFilePath := v.GetString(FlagLogToFile)
+	moduleLevels := v.GetString(FlagLogModuleLevels)
+	if logFilePath == "" && moduleLevels == "" {
+		return nil
+	}
+
+	var out io.Writer = os.Stderr
+	if logFilePath != "" {
+		file, err := newRotatingFile(
+			logFilePath,
+			int64(v.GetInt(FlagLogFileMaxSize))*1024*1024,
+			v.GetDuration(FlagLogFileMaxAge),
+			v.GetInt(FlagLogFileMaxBackups),
+			v.GetBool(FlagLogFileCompress),
+		)
+		if err != nil {
+			return err
+		}
+		out = file
+	}
+
+	logger, err := newLogger(out, v.GetString(flags.FlagLogFormat), v.GetString(flags.FlagLogLevel), moduleLevels)
+	if err != nil {
+		return err
+	}
+
+	sctx.Logger = logger
+	return server.SetCmdServerContext(cmd, sctx)
+}
+
+// newLogger returns a logger that writes to out in the provided format (json
+// or plain). If moduleLevels is not empty, logs are filtered per module and
+// logLevel is used for all modules that are not part of moduleLevels.
+func newLogger(out io.Writer, format string, logLevel string, moduleLevels string) (log.Logger, error) {
+	if strings.ToLower(format) != "json" {
+		_, isFile := out.(*rotatingFile)
+		out = zerolog.ConsoleWriter{Out: out, NoColor: isFile}
+	}
+
+	level, err := zerolog.ParseLevel(logLevel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse log level (%s): %w", logLevel, err)
+	}
+
+	if moduleLevels == "" {
+		return server.ZeroLogWrapper{Logger: zerolog.New(out).Level(level).With().Timestamp().Logger()}, nil
+	}
+
+	// Module specific levels may be more verbose than the default level so
+	// the underlying logger logs everything and filtering is left to the
+	// module aware filter.
+	logger := server.ZeroLogWrapper{Logger: zerolog.New(out).Level(zerolog.DebugLevel).With().Timestamp().Logger()}
+	return tmflags.ParseLogLevel(resolveLogModules(moduleLevels), logger, filterLogLevel(level))
+}
+
+// resolveLogModules replaces module aliases in the module:level pairs of
+// moduleLevels, such that x/blobstream can be used instead of x/qgb.
+func resolveLogModules(moduleLevels string) string {
+	pairs := strings.Split(moduleLevels, ",")
+	for i, pair := range pairs {
+		pair = strings.TrimSpace(pair)
+		for alias, name := range moduleNameAliases {
+			if strings.HasPrefix(pair, "x/"+alias+":") {
+				pair = "x/" + name + strings.TrimPrefix(pair, "x/"+alias)
+			}
+		}
+		pairs[i] = pair
+	}
+	return strings.Join(pairs, ",")
+}
+
+// filterLogLevel maps a zerolog level to the closest level supported by the
+// Tendermint log filter.
+func filterLogLevel(level zerolog.Level) string {
+	switch {
+	case level == zerolog.Disabled:
+		return "none"
+	case level <= zerolog.DebugLevel:
+		return "debug"
+	case level == zerolog.InfoLevel:
+		return "info"
+	default:
+		return "error"
+	}
+}
+
+// rotatingFile is an io.Writer that writes to a file and rotates it once it
+// exceeds maxSize bytes or is older than maxAge. Rotated files are renamed to
+// include the time of rotation and optionally compressed. Only the maxBackups
+// most recent rotated files are kept.
+type rotatingFile struct {
+	mtx sync.Mutex
+	// wg tracks rotated files that are being compressed.
+	wg sync.WaitGroup
+	// cleanupDone is closed once the compression and removal of backups
+	// started by the last rotation has finished. Every rotation waits for the
+	// previous one so that backups are never removed while being compressed.
+	cleanupDone chan struct{}
+
+	path       string
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+	compress   bool
+
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	now      func() time.Time
+}
+
+// newRotatingFile opens the file at path in append mode. maxSize, maxAge and
+// maxBackups are ignored if they are zero.
+func newRotatingFile(path string, maxSize int64, maxAge time.Duration, maxBackups int, compress bool) (*rotatingFile, error) {
+	r := &rotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxAge:     maxAge,
+		maxBackups: maxBackups,
+		compress:   compress,
+		now:        time.Now,
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Write implements io.Writer.
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if r.shouldRotate(int64(len(p))) {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close waits for pending compressions and closes the file.
+func (r *rotatingFile) Close() error {
+	r.wg.Wait()
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return r.file.Close()
+}
+
+func (r *rotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	r.file = file
+	r.size = info.Size()
+	r.openedAt = r.createdAt(info)
+	return nil
+}
+
+// createdAt returns the time the log file described by info was created. An
+// existing log file was created by the most recent rotation, whose time is
+// part of the name of the newest backup. If there is no such backup, the
+// modification time of the file is used. This ensures that the age of a log
+// file is not reset whenever the node restarts.
+func (r *rotatingFile) createdAt(info os.FileInfo) time.Time {
+	if info.Size() == 0 {
+		return r.now()
+	}
+	backups, err := r.backups()
+	if err == nil && len(backups) > 0 {
+		rotatedAt, ok := r.rotatedAt(backups[len(backups)-1])
+		if ok && !rotatedAt.After(info.ModTime()) {
+			return rotatedAt
+		}
+	}
+	return info.ModTime()
+}
+
+// shouldRotate returns true if writing n more bytes would exceed the maximum
+// size or if the file has exceeded its maximum age. An empty file is never
+// rotated.
+func (r *rotatingFile) shouldRotate(n int64) bool {
+	if r.size == 0 {
+		return false
+	}
+	if r.maxSize > 0 && r.size+n > r.maxSize {
+		return true
+	}
+	return r.maxAge > 0 && r.now().Sub(r.openedAt) >= r.maxAge
+}
+
+// rotate renames the current file, opens a new one and removes old backups.
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	backup := r.path + "." + r.now().UTC().Format(rotatedLogTimeFormat)
+	if err := os.Rename(r.path, backup); err != nil {
+		return err
+	}
+	if err := r.open(); err != nil {
+		return err
+	}
+
+	if !r.compress {
+		return r.removeOldBackups()
+	}
+	prev := r.cleanupDone
+	done := make(chan struct{})
+	r.cleanupDone = done
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer close(done)
+		if prev != nil {
+			<-prev
+		}
+		if err := compressFile(backup); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to compress rotated log file %s: %v\n", backup, err)
+		}
+		if err := r.removeOldBackups(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to remove old log files: %v\n", err)
+		}
+	}()
+	return nil
+}
+
+// backups returns the rotated log files sorted from oldest to newest. Only
+// files named after the log file followed by the time of rotation, and
+// optionally the .gz extension, are considered to be rotated log files.
+func (r *rotatingFile) backups() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Dir(r.path))
+	if err != nil {
+		return nil, err
+	}
+	var backups []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(filepath.Dir(r.path), entry.Name())
+		if _, ok := r.rotatedAt(path); ok {
+			backups = append(backups, path)
+		}
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// rotatedAt returns the time of rotation that is part of the name of a rotated
+// log file. It returns false if path is not a rotated log file.
+func (r *rotatingFile) rotatedAt(path string) (time.Time, bool) {
+	suffix, ok := strings.CutPrefix(filepath.Base(path), filepath.Base(r.path)+".")
+	if !ok {
+		return time.Time{}, false
+	}
+	rotatedAt, err := time.Parse(rotatedLogTimeFormat, strings.TrimSuffix(suffix, ".gz"))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return rotatedAt, true
+}
+
+func (r *rotatingFile) removeOldBackups() error {
+	if r.maxBackups <= 0 {
+		return nil
+	}
+	backups, err := r.backups()
+	if err != nil {
+		return err
+	}
+	for len(backups) > r.maxBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// compressFile replaces the file at path with a gzip compressed copy.
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
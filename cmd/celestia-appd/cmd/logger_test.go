@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	serverconfig "github.com/cosmos/cosmos-sdk/server/config"
+	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFileRotatesBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "node.log")
+	r, err := newRotatingFile(path, 10, 0, 2, false)
+	require.NoError(t, err)
+
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	for i := 0; i < 4; i++ {
+		_, err := r.Write([]byte("0123456789"))
+		require.NoError(t, err)
+	}
+	require.NoError(t, r.Close())
+
+	backups, err := r.backups()
+	require.NoError(t, err)
+	// four writes of the maximum size result in three rotations of which
+	// only the two most recent are kept
+	assert.Len(t, backups, 2)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "0123456789", string(data))
+}
+
+func TestRotatingFileRotatesByAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "node.log")
+	r, err := newRotatingFile(path, 0, time.Hour, 0, false)
+	require.NoError(t, err)
+
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+	r.openedAt = now
+
+	_, err = r.Write([]byte("first"))
+	require.NoError(t, err)
+	now = now.Add(30 * time.Minute)
+	_, err = r.Write([]byte("second"))
+	require.NoError(t, err)
+
+	backups, err := r.backups()
+	require.NoError(t, err)
+	assert.Empty(t, backups)
+
+	now = now.Add(time.Hour)
+	_, err = r.Write([]byte("third"))
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+
+	backups, err = r.backups()
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	data, err := os.ReadFile(backups[0])
+	require.NoError(t, err)
+	assert.Equal(t, "firstsecond", string(data))
+}
+
+func TestRotatingFileCompresses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "node.log")
+	r, err := newRotatingFile(path, 5, 0, 0, true)
+	require.NoError(t, err)
+
+	_, err = r.Write([]byte("first"))
+	require.NoError(t, err)
+	_, err = r.Write([]byte("second"))
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+
+	backups, err := r.backups()
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	assert.True(t, strings.HasSuffix(backups[0], ".gz"))
+}
+
+func TestRotatingFileKeepsAgeAcrossRestarts(t *testing.T) {
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name  string
+		setup func(t *testing.T, path string)
+	}{
+		{
+			name: "age from the newest backup",
+			setup: func(t *testing.T, path string) {
+				backup := path + "." + now.Add(-2*time.Hour).Format(rotatedLogTimeFormat) + ".gz"
+				require.NoError(t, os.WriteFile(backup, []byte("old"), 0o600))
+			},
+		},
+		{
+			name: "age from the modification time",
+			setup: func(t *testing.T, path string) {
+				modTime := now.Add(-2 * time.Hour)
+				require.NoError(t, os.Chtimes(path, modTime, modTime))
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "node.log")
+			require.NoError(t, os.WriteFile(path, []byte("before restart"), 0o600))
+			tc.setup(t, path)
+
+			r, err := newRotatingFile(path, 0, time.Hour, 0, false)
+			require.NoError(t, err)
+			r.now = func() time.Time { return now }
+
+			_, err = r.Write([]byte("after restart"))
+			require.NoError(t, err)
+			require.NoError(t, r.Close())
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, "after restart", string(data))
+		})
+	}
+}
+
+func TestRotatingFileIgnoresUnrelatedFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "node.log")
+	unrelated := filepath.Join(dir, "node.log.bak")
+	require.NoError(t, os.WriteFile(unrelated, []byte("keep me"), 0o600))
+
+	r, err := newRotatingFile(path, 5, 0, 1, true)
+	require.NoError(t, err)
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	for i := 0; i < 5; i++ {
+		_, err := r.Write([]byte("01234"))
+		require.NoError(t, err)
+	}
+	require.NoError(t, r.Close())
+
+	backups, err := r.backups()
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	assert.True(t, strings.HasSuffix(backups[0], ".gz"))
+	assert.FileExists(t, unrelated)
+}
+
+func TestNewLoggerModuleLevels(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := newLogger(&buf, "json", "info", "x/blobstream:debug,proposal:error")
+	require.NoError(t, err)
+
+	logger.With("module", "x/qgb").Debug("blobstream debug")
+	logger.With("module", "proposal").Info("proposal info")
+	logger.With("module", "consensus").Debug("consensus debug")
+	logger.With("module", "consensus").Info("consensus info")
+
+	out := buf.String()
+	assert.Contains(t, out, "blobstream debug")
+	assert.NotContains(t, out, "proposal info")
+	assert.NotContains(t, out, "consensus debug")
+	assert.Contains(t, out, "consensus info")
+}
+
+func TestFilterLogLevel(t *testing.T) {
+	assert.Equal(t, "debug", filterLogLevel(zerolog.TraceLevel))
+	assert.Equal(t, "debug", filterLogLevel(zerolog.DebugLevel))
+	assert.Equal(t, "info", filterLogLevel(zerolog.InfoLevel))
+	assert.Equal(t, "error", filterLogLevel(zerolog.WarnLevel))
+	assert.Equal(t, "none", filterLogLevel(zerolog.Disabled))
+}
+
+func TestAppConfigTemplateLogOptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.toml")
+	serverconfig.SetConfigTemplate(appConfigTemplate())
+	serverconfig.WriteConfigFile(path, serverconfig.DefaultConfig())
+
+	// uncommenting the log options must set them as top level keys
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	content = bytes.ReplaceAll(content, []byte("# log-file-max-size = 100"), []byte("log-file-max-size = 5"))
+	require.NoError(t, os.WriteFile(path, content, 0o600))
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	require.NoError(t, v.ReadInConfig())
+	assert.Equal(t, 5, v.GetInt(FlagLogFileMaxSize))
+	assert.True(t, v.IsSet("telemetry.service-name"))
+}
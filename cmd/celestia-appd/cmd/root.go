@@ -9,6 +9,7 @@ import (
 
 	"github.com/celestiaorg/celestia-app/app"
 	"github.com/celestiaorg/celestia-app/app/encoding"
+	"github.com/celestiaorg/celestia-app/x/upgrade"
 	"github.com/cosmos/cosmos-sdk/codec"
 	"github.com/cosmos/cosmos-sdk/simapp/simd/cmd"
 	"github.com/cosmos/cosmos-sdk/x/crisis"
@@ -22,7 +23,6 @@ import (
 	"github.com/cosmos/cosmos-sdk/client/keys"
 	"github.com/cosmos/cosmos-sdk/client/rpc"
 	"github.com/cosmos/cosmos-sdk/server"
-	serverconfig "github.com/cosmos/cosmos-sdk/server/config"
 	servertypes "github.com/cosmos/cosmos-sdk/server/types"
 	"github.com/cosmos/cosmos-sdk/snapshots"
 	snapshottypes "github.com/cosmos/cosmos-sdk/snapshots/types"
@@ -42,8 +42,9 @@ import (
 const (
 	EnvPrefix = "CELESTIA"
 
-	// FlagLogToFile specifies whether to log to file or not.
-	FlagLogToFile = "log-to-file"
+	// UpgradeScheduleFlag configures the upgrade schedules of the node. Each
+	// plan is given as <chain-id>:<start-height>:<end-height>:<app-version>.
+	UpgradeScheduleFlag = "upgrade-schedule"
 )
 
 // NewRootCmd creates a new root command for celestia-appd. It is called once in the
@@ -83,7 +84,7 @@ func NewRootCmd() *cobra.Command {
 			var (
 				tmCfg       = app.DefaultConsensusConfig()
 				appConfig   = app.DefaultAppConfig()
-				appTemplate = serverconfig.DefaultConfigTemplate
+				appTemplate = appConfigTemplate()
 			)
 
 			err = server.InterceptConfigsPreRunHandler(cmd, appTemplate, appConfig, tmCfg)
@@ -91,12 +92,11 @@ func NewRootCmd() *cobra.Command {
 				return err
 			}
 
-			// optionally log to file by replacing the default logger with a file logger
-			if cmd.Flags().Changed(FlagLogToFile) {
-				err = replaceLogger(cmd)
-				if err != nil {
-					return err
-				}
+			// optionally log to file and filter logs per module by replacing
+			// the default logger
+			err = replaceLogger(cmd)
+			if err != nil {
+				return err
 			}
 
 			return setDefaultConsensusParams(cmd)
@@ -104,7 +104,7 @@ func NewRootCmd() *cobra.Command {
 		SilenceUsage: true,
 	}
 
-	rootCmd.PersistentFlags().String(FlagLogToFile, "", "Write logs directly to a file. If empty, logs are written to stderr")
+	addLogFlags(rootCmd)
 	initRootCmd(rootCmd, encodingConfig)
 
 	return rootCmd
@@ -137,9 +137,11 @@ func initRootCmd(rootCmd *cobra.Command, encodingConfig encoding.Config) {
 		commands.CompactGoLevelDBCmd,
 		addrbookCommand(),
 		downloadGenesisCommand(),
+		genesisCommand(),
 	)
 
 	server.AddCommands(rootCmd, app.DefaultNodeHome, NewAppServer, createAppAndExport, addModuleInitFlags)
+	replaceExportCommand(rootCmd)
 
 	// add status, query, tx, and keys subcommands
 	rootCmd.AddCommand(
@@ -151,8 +153,20 @@ func initRootCmd(rootCmd *cobra.Command, encodingConfig encoding.Config) {
 	)
 }
 
+// replaceExportCommand replaces the export command added by the SDK with one
+// that supports exporting the state of individual modules.
+func replaceExportCommand(rootCmd *cobra.Command) {
+	for _, c := range rootCmd.Commands() {
+		if c.Name() == "export" {
+			rootCmd.RemoveCommand(c)
+		}
+	}
+	rootCmd.AddCommand(exportCommand())
+}
+
 func addModuleInitFlags(startCmd *cobra.Command) {
 	crisis.AddModuleInitFlags(startCmd)
+	startCmd.Flags().StringSlice(UpgradeScheduleFlag, nil, "Upgrade plans of the node as <chain-id>:<start-height>:<end-height>:<app-version>. At least 2/3 of the voting power must share a plan for the upgrade to happen")
 }
 
 func queryCommand() *cobra.Command {
@@ -229,11 +243,19 @@ func NewAppServer(logger log.Logger, db dbm.DB, traceStore io.Writer, appOpts se
 		panic(err)
 	}
 
+	var upgradeSchedule map[string]upgrade.Schedule
+	if plans := cast.ToStringSlice(appOpts.Get(UpgradeScheduleFlag)); len(plans) > 0 {
+		upgradeSchedule, err = upgrade.ParseSchedules(plans)
+		if err != nil {
+			panic(err)
+		}
+	}
+
 	return app.New(
 		logger, db, traceStore, true,
 		cast.ToUint(appOpts.Get(server.FlagInvCheckPeriod)),
 		encoding.MakeConfig(app.ModuleEncodingRegisters...), // Ideally, we would reuse the one created by NewRootCmd.
-		nil,
+		upgradeSchedule,
 		appOpts,
 		baseapp.SetPruning(pruningOpts),
 		baseapp.SetMinGasPrices(cast.ToString(appOpts.Get(server.FlagMinGasPrices))),
@@ -252,40 +274,25 @@ func createAppAndExport(
 	logger log.Logger, db dbm.DB, traceStore io.Writer, height int64, forZeroHeight bool, jailWhiteList []string,
 	appOpts servertypes.AppOptions,
 ) (servertypes.ExportedApp, error) {
-	encCfg := encoding.MakeConfig(app.ModuleEncodingRegisters...) // Ideally, we would reuse the one created by NewRootCmd.
-	encCfg.Codec = codec.NewProtoCodec(encCfg.InterfaceRegistry)
-	var capp *app.App
-	if height != -1 {
-		capp = app.New(logger, db, traceStore, false, uint(1), encCfg, nil, appOpts)
-
-		if err := capp.LoadHeight(height); err != nil {
-			return servertypes.ExportedApp{}, err
-		}
-	} else {
-		capp = app.New(logger, db, traceStore, true, uint(1), encCfg, nil, appOpts)
+	capp, err := loadApp(logger, db, traceStore, height, appOpts)
+	if err != nil {
+		return servertypes.ExportedApp{}, err
 	}
-
 	return capp.ExportAppStateAndValidators(forZeroHeight, jailWhiteList)
 }
 
-// replaceLogger optionally replaces the logger with a file logger if the flag
-// is set to something other than the default.
-func replaceLogger(cmd *cobra.Command) error {
-	logFilePath, err := cmd.Flags().GetString(FlagLogToFile)
-	if err != nil {
-		return err
-	}
-
-	if logFilePath == "" {
-		return nil
+// loadApp creates an app from db and loads the state at height. If height is
+// -1, the latest state is loaded.
+func loadApp(logger log.Logger, db dbm.DB, traceStore io.Writer, height int64, appOpts servertypes.AppOptions) (*app.App, error) {
+	encCfg := encoding.MakeConfig(app.ModuleEncodingRegisters...) // Ideally, we would reuse the one created by NewRootCmd.
+	encCfg.Codec = codec.NewProtoCodec(encCfg.InterfaceRegistry)
+	if height == -1 {
+		return app.New(logger, db, traceStore, true, uint(1), encCfg, nil, appOpts), nil
 	}
 
-	file, err := os.OpenFile(logFilePath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
-	if err != nil {
-		return err
+	capp := app.New(logger, db, traceStore, false, uint(1), encCfg, nil, appOpts)
+	if err := capp.LoadHeight(height); err != nil {
+		return nil, err
 	}
-
-	sctx := server.GetServerContextFromCmd(cmd)
-	sctx.Logger = log.NewTMLogger(log.NewSyncWriter(file))
-	return server.SetCmdServerContext(cmd, sctx)
+	return capp, nil
 }
@@ -0,0 +1,150 @@
+package appconsts
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/celestiaorg/rsmt2d"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setUpgradedCodec switches the LatestVersion to the reversed Leopard codec
+// until the end of the test. Tests that use it must not run in parallel.
+func setUpgradedCodec(t *testing.T) {
+	SetCodec(t, NewReversedLeopardCodec())
+	consts := Versioned(LatestVersion)
+	consts.Codec = reversedLeopard
+	SetVersionedConsts(t, consts)
+}
+
+func TestCodec(t *testing.T) {
+	for version := uint64(0); version <= LatestVersion+1; version++ {
+		assert.Equal(t, Versioned(version).Codec, Codec(version).Name(), "codec of v%d", version)
+	}
+	assert.Equal(t, Codec(LatestVersion), DefaultCodec())
+
+	setUpgradedCodec(t)
+	assert.Equal(t, reversedLeopard, Codec(LatestVersion).Name())
+	assert.Equal(t, reversedLeopard, Codec(LatestVersion+1).Name())
+	assert.Equal(t, rsmt2d.Leopard, Codec(LatestVersion-1).Name())
+}
+
+func TestCheckCodecs(t *testing.T) {
+	t.Parallel()
+	require.NoError(t, checkCodecs(versionedConsts, codecs))
+
+	unregistered := Versioned(LatestVersion)
+	unregistered.Codec = "unregistered"
+	err := checkCodecs(map[uint64]VersionedConsts{unregistered.Version: unregistered}, codecs)
+	assert.ErrorContains(t, err, `unregistered codec "unregistered"`)
+}
+
+// TestCodecDataRoots checks that extending the same square with different
+// codecs keeps the original shares but commits to different data.
+func TestCodecDataRoots(t *testing.T) {
+	t.Parallel()
+	const squareSize = 4
+	data := randShares(t, squareSize*squareSize)
+
+	leopard, err := rsmt2d.ComputeExtendedDataSquare(data, rsmt2d.NewLeoRSCodec(), rsmt2d.NewDefaultTree)
+	require.NoError(t, err)
+	reversed, err := rsmt2d.ComputeExtendedDataSquare(data, NewReversedLeopardCodec(), rsmt2d.NewDefaultTree)
+	require.NoError(t, err)
+
+	assert.Equal(t, leopard.FlattenedODS(), reversed.FlattenedODS())
+	leopardRows, err := leopard.RowRoots()
+	require.NoError(t, err)
+	reversedRows, err := reversed.RowRoots()
+	require.NoError(t, err)
+	leopardCols, err := leopard.ColRoots()
+	require.NoError(t, err)
+	reversedCols, err := reversed.ColRoots()
+	require.NoError(t, err)
+	for i := 0; i < 2*squareSize; i++ {
+		assert.NotEqual(t, leopardRows[i], reversedRows[i], "row %d", i)
+		assert.NotEqual(t, leopardCols[i], reversedCols[i], "column %d", i)
+	}
+}
+
+// TestCodecUpgrade simulates an upgrade that switches the codec at a height.
+// The squares of every height must be extended with the codec of the app
+// version of their block: the data roots before the upgrade don't change and
+// the squares of every height can only be repaired with the codec of their
+// version.
+func TestCodecUpgrade(t *testing.T) {
+	const (
+		squareSize    = 4
+		upgradeHeight = 3
+		lastHeight    = 5
+	)
+	setUpgradedCodec(t)
+	versionAt := func(height int) uint64 {
+		if height < upgradeHeight {
+			return LatestVersion - 1
+		}
+		return LatestVersion
+	}
+
+	for height := 1; height <= lastHeight; height++ {
+		t.Run(fmt.Sprintf("height %d", height), func(t *testing.T) {
+			version := versionAt(height)
+			data := randShares(t, squareSize*squareSize)
+			eds, err := rsmt2d.ComputeExtendedDataSquare(data, Codec(version), rsmt2d.NewDefaultTree)
+			require.NoError(t, err)
+			rowRoots, err := eds.RowRoots()
+			require.NoError(t, err)
+			colRoots, err := eds.ColRoots()
+			require.NoError(t, err)
+
+			leopard, err := rsmt2d.ComputeExtendedDataSquare(data, rsmt2d.NewLeoRSCodec(), rsmt2d.NewDefaultTree)
+			require.NoError(t, err)
+			leopardRows, err := leopard.RowRoots()
+			require.NoError(t, err)
+			if height < upgradeHeight {
+				assert.Equal(t, leopardRows, rowRoots)
+			} else {
+				assert.NotEqual(t, leopardRows, rowRoots)
+			}
+
+			// a node that repairs the square from its original shares only
+			// succeeds with the codec of the version of the block
+			require.NoError(t, repairOriginal(t, eds, Codec(version), rowRoots, colRoots))
+			other := Codec(versionAt(upgradeHeight - 1))
+			if height < upgradeHeight {
+				other = Codec(versionAt(upgradeHeight))
+			}
+			var byzantine *rsmt2d.ErrByzantineData
+			assert.True(t, errors.As(repairOriginal(t, eds, other, rowRoots, colRoots), &byzantine))
+		})
+	}
+}
+
+// repairOriginal imports the original shares of the square with the codec and
+// repairs the rest of the square against the roots.
+func repairOriginal(t *testing.T, eds *rsmt2d.ExtendedDataSquare, codec rsmt2d.Codec, rowRoots, colRoots [][]byte) error {
+	width := eds.Width()
+	flattened := eds.Flattened()
+	for row := uint(0); row < width; row++ {
+		for col := uint(0); col < width; col++ {
+			if row >= width/2 || col >= width/2 {
+				flattened[row*width+col] = nil
+			}
+		}
+	}
+	imported, err := rsmt2d.ImportExtendedDataSquare(flattened, codec, rsmt2d.NewDefaultTree)
+	require.NoError(t, err)
+	return imported.Repair(rowRoots, colRoots)
+}
+
+func randShares(t *testing.T, count int) [][]byte {
+	shares := make([][]byte, count)
+	for i := range shares {
+		shares[i] = make([]byte, ShareSize)
+		_, err := rand.Read(shares[i])
+		require.NoError(t, err)
+	}
+	return shares
+}
@@ -1,13 +1,18 @@
 package appconsts
 
-import "time"
+import v1 "github.com/celestiaorg/celestia-app/pkg/appconsts/v1"
 
 const (
-	TimeoutPropose = time.Second * 10
-	TimeoutCommit  = time.Second * 11
-	// GoalBlockTime is the target time interval between blocks. Since the block
-	// interval isn't enforced at consensus, the real block interval isn't
-	// guaranteed to exactly match GoalBlockTime. GoalBlockTime is currently targeted
-	// through static timeouts (i.e. TimeoutPropose, TimeoutCommit).
-	GoalBlockTime = time.Second * 15
+	// Deprecated: the timeouts depend on the app version. Use
+	// Versioned(version).TimeoutPropose or DefaultTimeoutPropose instead.
+	TimeoutPropose = v1.TimeoutPropose
+	// Deprecated: the timeouts depend on the app version. Use
+	// Versioned(version).TimeoutCommit or DefaultTimeoutCommit instead.
+	TimeoutCommit = v1.TimeoutCommit
+	// GoalBlockTime is the target time interval between blocks of the first
+	// app version.
+	//
+	// Deprecated: the goal block time depends on the app version. Use
+	// Versioned(version).GoalBlockTime or DefaultGoalBlockTime instead.
+	GoalBlockTime = v1.GoalBlockTime
 )
@@ -4,7 +4,6 @@ import (
 	"math"
 
 	ns "github.com/celestiaorg/celestia-app/pkg/namespace"
-	"github.com/celestiaorg/rsmt2d"
 	"github.com/tendermint/tendermint/pkg/consts"
 )
 
@@ -88,9 +87,6 @@ var (
 	// hashLength is the length of a hash in bytes.
 	hashLength = NewBaseHashFunc().Size()
 
-	// DefaultCodec is the default codec creator used for data erasure.
-	DefaultCodec = rsmt2d.NewLeoRSCodec
-
 	// SupportedShareVersions is a list of supported share versions.
 	SupportedShareVersions = []uint8{ShareVersionZero}
 )
@@ -0,0 +1,88 @@
+package appconsts
+
+import (
+	"testing"
+
+	"github.com/celestiaorg/rsmt2d"
+	"golang.org/x/exp/slices"
+)
+
+// SetVersionedConsts makes the app version of the constants use them until the
+// end of the test. It replaces package state, so tests that use it must not run
+// in parallel.
+func SetVersionedConsts(t testing.TB, consts VersionedConsts) {
+	previous, ok := versionedConsts[consts.Version]
+	t.Cleanup(func() {
+		if ok {
+			versionedConsts[consts.Version] = previous
+		} else {
+			delete(versionedConsts, consts.Version)
+		}
+	})
+	versionedConsts[consts.Version] = consts
+}
+
+// SetCodec registers the codec until the end of the test, so that app versions
+// set with SetVersionedConsts can use it. It replaces package state, so tests
+// that use it must not run in parallel.
+func SetCodec(t testing.TB, codec rsmt2d.Codec) {
+	previous, ok := codecs[codec.Name()]
+	t.Cleanup(func() {
+		if ok {
+			codecs[codec.Name()] = previous
+		} else {
+			delete(codecs, codec.Name())
+		}
+	})
+	codecs[codec.Name()] = codec
+}
+
+// reversedLeopard is the name of reversedLeopardCodec.
+const reversedLeopard = "ReversedLeopard"
+
+// reversedLeopardCodec is an rsmt2d codec that erasure codes like Leopard but
+// stores the parity shares in reverse order.
+type reversedLeopardCodec struct {
+	leopard rsmt2d.Codec
+}
+
+var _ rsmt2d.Codec = reversedLeopardCodec{}
+
+// NewReversedLeopardCodec returns a codec that no app version uses, which lets
+// tests switch codecs between app versions.
+func NewReversedLeopardCodec() rsmt2d.Codec {
+	return reversedLeopardCodec{leopard: rsmt2d.NewLeoRSCodec()}
+}
+
+func (c reversedLeopardCodec) Encode(data [][]byte) ([][]byte, error) {
+	parity, err := c.leopard.Encode(data)
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(parity)
+	return parity, nil
+}
+
+func (c reversedLeopardCodec) Decode(data [][]byte) ([][]byte, error) {
+	shards := slices.Clone(data)
+	slices.Reverse(shards[len(shards)/2:])
+	decoded, err := c.leopard.Decode(shards)
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(decoded[len(decoded)/2:])
+	copy(data, decoded)
+	return data, nil
+}
+
+func (c reversedLeopardCodec) MaxChunks() int {
+	return c.leopard.MaxChunks()
+}
+
+func (reversedLeopardCodec) Name() string {
+	return reversedLeopard
+}
+
+func (c reversedLeopardCodec) ValidateChunkSize(chunkSize int) error {
+	return c.leopard.ValidateChunkSize(chunkSize)
+}
@@ -1,7 +1,17 @@
 package v1
 
+import (
+	"time"
+
+	"github.com/celestiaorg/rsmt2d"
+)
+
 const (
 	Version              uint64 = 1
 	SquareSizeUpperBound int    = 128
 	SubtreeRootThreshold int    = 64
+	Codec                       = rsmt2d.Leopard
+	TimeoutPropose              = time.Second * 10
+	TimeoutCommit               = time.Second * 11
+	GoalBlockTime               = time.Second * 15
 )
@@ -1,14 +1,88 @@
 package appconsts
 
 import (
+	"fmt"
+	"time"
+
 	v1 "github.com/celestiaorg/celestia-app/pkg/appconsts/v1"
 	v2 "github.com/celestiaorg/celestia-app/pkg/appconsts/v2"
+	"github.com/celestiaorg/rsmt2d"
 )
 
 const (
 	LatestVersion = v2.Version
 )
 
+// VersionedConsts are the constants that every version of the state machine
+// declares in its own package. Changing any of them is a consensus breaking
+// change that requires a new app version. The share size is not versioned:
+// the share encoding is built on the global ShareSize, so every version uses
+// the same share size.
+type VersionedConsts struct {
+	Version uint64
+	// SquareSizeUpperBound is the maximum original square width. The maximum
+	// is decided through governance. See `DefaultGovMaxSquareSize`.
+	SquareSizeUpperBound int
+	// SubtreeRootThreshold works as a target upper bound for the number of
+	// subtree roots in the share commitment. See SubtreeRootThreshold.
+	SubtreeRootThreshold int
+	// Codec is the name of the rsmt2d codec that erasure codes the data
+	// square. It must be registered in codecs.
+	Codec string
+	// TimeoutPropose is how long a validator waits for a proposal.
+	TimeoutPropose time.Duration
+	// TimeoutCommit is how long a validator waits after committing a block
+	// before it starts the next height.
+	TimeoutCommit time.Duration
+	// GoalBlockTime is the target time interval between blocks. Since the
+	// block interval isn't enforced at consensus, the real block interval
+	// isn't guaranteed to exactly match GoalBlockTime. GoalBlockTime is
+	// targeted through the static timeouts TimeoutPropose and TimeoutCommit.
+	GoalBlockTime time.Duration
+}
+
+// versionedConsts registers the constants of every app version.
+var versionedConsts = map[uint64]VersionedConsts{
+	v1.Version: {
+		Version:              v1.Version,
+		SquareSizeUpperBound: v1.SquareSizeUpperBound,
+		SubtreeRootThreshold: v1.SubtreeRootThreshold,
+		Codec:                v1.Codec,
+		TimeoutPropose:       v1.TimeoutPropose,
+		TimeoutCommit:        v1.TimeoutCommit,
+		GoalBlockTime:        v1.GoalBlockTime,
+	},
+	v2.Version: {
+		Version:              v2.Version,
+		SquareSizeUpperBound: v2.SquareSizeUpperBound,
+		SubtreeRootThreshold: v2.SubtreeRootThreshold,
+		Codec:                v2.Codec,
+		TimeoutPropose:       v2.TimeoutPropose,
+		TimeoutCommit:        v2.TimeoutCommit,
+		GoalBlockTime:        v2.GoalBlockTime,
+	},
+}
+
+// Versioned returns the constants of an app version. An unset app version,
+// zero, uses the constants of the first version and versions after the
+// LatestVersion use the constants of the LatestVersion.
+func Versioned(version uint64) VersionedConsts {
+	if version < v1.Version {
+		version = v1.Version
+	}
+	if version > LatestVersion {
+		version = LatestVersion
+	}
+	return versionedConsts[version]
+}
+
+// codecs registers the erasure codecs that app versions can use by name. The
+// codecs are safe for concurrent use, so every square is extended with the same
+// instance.
+var codecs = map[string]rsmt2d.Codec{
+	rsmt2d.Leopard: rsmt2d.NewLeoRSCodec(),
+}
+
 // SubtreeRootThreshold works as a target upper bound for the number of subtree
 // roots in the share commitment. If a blob contains more shares than this
 // number, then the height of the subtree roots will increase by one so that the
@@ -17,18 +91,55 @@ const (
 // SubtreeRootThreshold.
 //
 // The rationale for this value is described in more detail in ADR-013.
-func SubtreeRootThreshold(_ uint64) int {
-	return v1.SubtreeRootThreshold
+func SubtreeRootThreshold(version uint64) int {
+	return Versioned(version).SubtreeRootThreshold
 }
 
 // SquareSizeUpperBound is the maximum original square width possible
 // for a version of the state machine. The maximum is decided through
 // governance. See `DefaultGovMaxSquareSize`.
-func SquareSizeUpperBound(_ uint64) int {
-	return v1.SquareSizeUpperBound
+func SquareSizeUpperBound(version uint64) int {
+	return Versioned(version).SquareSizeUpperBound
+}
+
+// Codec returns the codec that erasure codes the data square of a version of
+// the state machine. Squares of different versions may be extended with
+// different codecs, so a square must always be extended with the codec of the
+// app version of its block. Like Versioned, an unset app version uses the codec
+// of the first version and versions after the LatestVersion use the codec of
+// the LatestVersion.
+func Codec(version uint64) rsmt2d.Codec {
+	return codecs[Versioned(version).Codec]
+}
+
+// DefaultCodec returns the codec of the LatestVersion.
+func DefaultCodec() rsmt2d.Codec {
+	return Codec(LatestVersion)
+}
+
+// checkCodecs returns an error if an app version uses a codec that isn't
+// registered.
+func checkCodecs(consts map[uint64]VersionedConsts, registered map[string]rsmt2d.Codec) error {
+	for _, c := range consts {
+		if _, ok := registered[c.Codec]; !ok {
+			return fmt.Errorf("app version %d uses the unregistered codec %q", c.Version, c.Codec)
+		}
+	}
+	return nil
+}
+
+// init panics if an app version uses an unregistered codec. The constants and
+// codecs are static, so Codec never has to handle a missing codec.
+func init() {
+	if err := checkCodecs(versionedConsts, codecs); err != nil {
+		panic(err)
+	}
 }
 
 var (
 	DefaultSubtreeRootThreshold = SubtreeRootThreshold(LatestVersion)
 	DefaultSquareSizeUpperBound = SquareSizeUpperBound(LatestVersion)
+	DefaultTimeoutPropose       = Versioned(LatestVersion).TimeoutPropose
+	DefaultTimeoutCommit        = Versioned(LatestVersion).TimeoutCommit
+	DefaultGoalBlockTime        = Versioned(LatestVersion).GoalBlockTime
 )
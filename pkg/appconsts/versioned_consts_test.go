@@ -0,0 +1,99 @@
+package appconsts_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/celestiaorg/celestia-app/pkg/appconsts"
+	v1 "github.com/celestiaorg/celestia-app/pkg/appconsts/v1"
+	v2 "github.com/celestiaorg/celestia-app/pkg/appconsts/v2"
+	"github.com/celestiaorg/celestia-app/pkg/blob"
+	"github.com/celestiaorg/celestia-app/pkg/inclusion"
+	appns "github.com/celestiaorg/celestia-app/pkg/namespace"
+	"github.com/celestiaorg/celestia-app/pkg/square"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersioned(t *testing.T) {
+	testCases := []struct {
+		version uint64
+		want    appconsts.VersionedConsts
+	}{
+		{
+			version: v1.Version,
+			want: appconsts.VersionedConsts{
+				Version:              v1.Version,
+				SquareSizeUpperBound: v1.SquareSizeUpperBound,
+				SubtreeRootThreshold: v1.SubtreeRootThreshold,
+				Codec:                v1.Codec,
+				TimeoutPropose:       v1.TimeoutPropose,
+				TimeoutCommit:        v1.TimeoutCommit,
+				GoalBlockTime:        v1.GoalBlockTime,
+			},
+		},
+		{
+			version: v2.Version,
+			want: appconsts.VersionedConsts{
+				Version:              v2.Version,
+				SquareSizeUpperBound: v2.SquareSizeUpperBound,
+				SubtreeRootThreshold: v2.SubtreeRootThreshold,
+				Codec:                v2.Codec,
+				TimeoutPropose:       v2.TimeoutPropose,
+				TimeoutCommit:        v2.TimeoutCommit,
+				GoalBlockTime:        v2.GoalBlockTime,
+			},
+		},
+	}
+	require.Len(t, testCases, int(appconsts.LatestVersion), "every version must be tested")
+	for _, tc := range testCases {
+		got := appconsts.Versioned(tc.version)
+		assert.Equal(t, tc.want, got)
+		assert.Equal(t, tc.want.SquareSizeUpperBound, appconsts.SquareSizeUpperBound(tc.version))
+		assert.Equal(t, tc.want.SubtreeRootThreshold, appconsts.SubtreeRootThreshold(tc.version))
+	}
+}
+
+func TestVersionedOutOfRange(t *testing.T) {
+	assert.Equal(t, appconsts.Versioned(v1.Version), appconsts.Versioned(0))
+	assert.Equal(t, appconsts.Versioned(appconsts.LatestVersion), appconsts.Versioned(appconsts.LatestVersion+1))
+}
+
+// TestVersionedSubtreeRootThreshold checks that squares follow the subtree
+// root threshold of their app version: lowering the threshold of a version
+// pads its blobs to wider subtrees, so the same blobs need a larger square.
+func TestVersionedSubtreeRootThreshold(t *testing.T) {
+	// two blobs of five shares each
+	blobSize := appconsts.FirstSparseShareContentSize + 4*appconsts.ContinuationSparseShareContentSize
+	blobTx := blob.BlobTx{
+		Tx: []byte("pfb"),
+		Blobs: []*blob.Blob{
+			blob.New(appns.MustNewV0(bytes.Repeat([]byte{1}, appns.NamespaceVersionZeroIDSize)), make([]byte, blobSize), appconsts.ShareVersionZero),
+			blob.New(appns.MustNewV0(bytes.Repeat([]byte{2}, appns.NamespaceVersionZeroIDSize)), make([]byte, blobSize), appconsts.ShareVersionZero),
+		},
+	}
+	squareSize := func(t *testing.T) int {
+		builder, err := square.NewBuilder(appconsts.DefaultSquareSizeUpperBound, appconsts.LatestVersion)
+		require.NoError(t, err)
+		require.True(t, builder.AppendBlobTx(blobTx))
+		dataSquare, err := builder.Export()
+		require.NoError(t, err)
+		return dataSquare.Size()
+	}
+
+	// with the threshold of the LatestVersion the blobs aren't padded
+	require.Equal(t, 4, squareSize(t))
+	require.Equal(t, 1, inclusion.SubTreeWidth(5, appconsts.SubtreeRootThreshold(appconsts.LatestVersion)))
+
+	// with a threshold of one every blob needs a single subtree root, so each
+	// blob is aligned to a subtree as wide as its minimum square
+	lowered := appconsts.Versioned(appconsts.LatestVersion)
+	lowered.SubtreeRootThreshold = 1
+	appconsts.SetVersionedConsts(t, lowered)
+	require.Equal(t, 1, appconsts.SubtreeRootThreshold(appconsts.LatestVersion))
+	require.Equal(t, 4, inclusion.SubTreeWidth(5, appconsts.SubtreeRootThreshold(appconsts.LatestVersion)))
+	assert.Equal(t, 8, squareSize(t))
+
+	// the other versions keep their threshold
+	assert.Equal(t, v1.SubtreeRootThreshold, appconsts.SubtreeRootThreshold(v1.Version))
+}
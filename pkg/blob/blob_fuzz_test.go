@@ -0,0 +1,51 @@
+package blob_test
+
+import (
+	"testing"
+
+	"github.com/celestiaorg/celestia-app/pkg/blob"
+	appns "github.com/celestiaorg/celestia-app/pkg/namespace"
+	"github.com/celestiaorg/celestia-app/test/util/blobfactory"
+	"github.com/celestiaorg/celestia-app/test/util/testfactory"
+	"github.com/celestiaorg/celestia-app/test/util/testnode"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/require"
+	tmrand "github.com/tendermint/tendermint/libs/rand"
+)
+
+// FuzzUnmarshalBlobTx uses fuzzing to test the following:
+// - That `UnmarshalBlobTx` never panics
+// - That every tx recognised as a BlobTx passes the basic checks
+// - That a recognised BlobTx is recognised again after being marshalled with `MarshalBlobTx`
+func FuzzUnmarshalBlobTx(f *testing.F) {
+	rand := tmrand.NewRand()
+	rand.Seed(1)
+	signer, err := testnode.NewOfflineSigner()
+	require.NoError(f, err)
+	for _, tx := range blobfactory.RandBlobTxs(signer, rand, 3, 2, 500) {
+		f.Add([]byte(tx))
+	}
+	for _, tx := range blobfactory.GenerateManyRandomRawSendTxsSameSigner(rand, signer, 2) {
+		f.Add([]byte(tx))
+	}
+	for _, tx := range testfactory.GenerateRandomTxs(2, 300) {
+		f.Add([]byte(tx))
+	}
+	f.Fuzz(func(t *testing.T, tx []byte) {
+		bTx, isBlob := blob.UnmarshalBlobTx(tx)
+		if !isBlob {
+			return
+		}
+		require.Equal(t, blob.ProtoBlobTxTypeID, bTx.TypeId)
+		require.NotEmpty(t, bTx.Blobs)
+		for _, b := range bTx.Blobs {
+			require.Len(t, b.NamespaceId, appns.NamespaceIDSize)
+		}
+
+		remarshalled, err := blob.MarshalBlobTx(bTx.Tx, bTx.Blobs...)
+		require.NoError(t, err)
+		bTx2, isBlob := blob.UnmarshalBlobTx(remarshalled)
+		require.True(t, isBlob)
+		require.True(t, proto.Equal(&bTx, &bTx2))
+	})
+}
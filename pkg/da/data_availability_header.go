@@ -62,7 +62,9 @@ func NewDataAvailabilityHeader(eds *rsmt2d.ExtendedDataSquare) (DataAvailability
 	return dah, nil
 }
 
-func ExtendShares(s [][]byte) (*rsmt2d.ExtendedDataSquare, error) {
+// ExtendShares erasure codes the shares of an original data square with the
+// codec of the app version.
+func ExtendShares(s [][]byte, appVersion uint64) (*rsmt2d.ExtendedDataSquare, error) {
 	// Check that the length of the square is a power of 2.
 	if !shares.IsPowerOfTwo(len(s)) {
 		return nil, fmt.Errorf("number of shares is not a power of 2: got %d", len(s))
@@ -71,7 +73,7 @@ func ExtendShares(s [][]byte) (*rsmt2d.ExtendedDataSquare, error) {
 
 	// here we construct a tree
 	// Note: uses the nmt wrapper to construct the tree.
-	return rsmt2d.ComputeExtendedDataSquare(s, appconsts.DefaultCodec(), wrapper.NewConstructor(uint64(squareSize)))
+	return rsmt2d.ComputeExtendedDataSquare(s, appconsts.Codec(appVersion), wrapper.NewConstructor(uint64(squareSize)))
 }
 
 // String returns hex representation of merkle hash of the DAHeader.
@@ -173,12 +175,12 @@ func (dah *DataAvailabilityHeader) SquareSize() int {
 	return len(dah.RowRoots) / 2
 }
 
-// MinDataAvailabilityHeader returns the minimum valid data availability header.
-// It is equal to the data availability header for a block with one tail padding
-// share.
-func MinDataAvailabilityHeader() DataAvailabilityHeader {
+// MinDataAvailabilityHeader returns the minimum valid data availability header
+// of an app version. It is equal to the data availability header for a block
+// with one tail padding share.
+func MinDataAvailabilityHeader(appVersion uint64) DataAvailabilityHeader {
 	s := MinShares()
-	eds, err := ExtendShares(s)
+	eds, err := ExtendShares(s, appVersion)
 	if err != nil {
 		panic(err)
 	}
@@ -25,7 +25,7 @@ func TestNilDataAvailabilityHeaderHashDoesntCrash(t *testing.T) {
 }
 
 func TestMinDataAvailabilityHeader(t *testing.T) {
-	dah := MinDataAvailabilityHeader()
+	dah := MinDataAvailabilityHeader(appconsts.LatestVersion)
 	expectedHash := []byte{0x3d, 0x96, 0xb7, 0xd2, 0x38, 0xe7, 0xe0, 0x45, 0x6f, 0x6a, 0xf8, 0xe7, 0xcd, 0xf0, 0xa6, 0x7b, 0xd6, 0xcf, 0x9c, 0x20, 0x89, 0xec, 0xb5, 0x59, 0xc6, 0x59, 0xdc, 0xaa, 0x1f, 0x88, 0x3, 0x53}
 	require.Equal(t, expectedHash, dah.hash)
 	require.NoError(t, dah.ValidateBasic())
@@ -56,7 +56,7 @@ func TestNewDataAvailabilityHeader(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			eds, err := ExtendShares(tt.shares)
+			eds, err := ExtendShares(tt.shares, appconsts.LatestVersion)
 			require.NoError(t, err)
 			got, err := NewDataAvailabilityHeader(eds)
 			require.NoError(t, err)
@@ -89,7 +89,7 @@ func TestExtendShares(t *testing.T) {
 
 	for _, tt := range tests {
 		tt := tt
-		_, err := ExtendShares(tt.shares)
+		_, err := ExtendShares(tt.shares, appconsts.LatestVersion)
 		if tt.expectedErr {
 			require.NotNil(t, err)
 			continue
@@ -105,7 +105,7 @@ func TestDataAvailabilityHeaderProtoConversion(t *testing.T) {
 	}
 
 	shares := generateShares(appconsts.DefaultSquareSizeUpperBound * appconsts.DefaultSquareSizeUpperBound)
-	eds, err := ExtendShares(shares)
+	eds, err := ExtendShares(shares, appconsts.LatestVersion)
 	require.NoError(t, err)
 	bigdah, err := NewDataAvailabilityHeader(eds)
 	require.NoError(t, err)
@@ -113,7 +113,7 @@ func TestDataAvailabilityHeaderProtoConversion(t *testing.T) {
 	tests := []test{
 		{
 			name: "min",
-			dah:  MinDataAvailabilityHeader(),
+			dah:  MinDataAvailabilityHeader(appconsts.LatestVersion),
 		},
 		{
 			name: "max",
@@ -143,7 +143,7 @@ func Test_DAHValidateBasic(t *testing.T) {
 	maxSize := appconsts.DefaultSquareSizeUpperBound * appconsts.DefaultSquareSizeUpperBound
 
 	shares := generateShares(maxSize)
-	eds, err := ExtendShares(shares)
+	eds, err := ExtendShares(shares, appconsts.LatestVersion)
 	require.NoError(t, err)
 	bigdah, err := NewDataAvailabilityHeader(eds)
 	require.NoError(t, err)
@@ -161,16 +161,16 @@ func Test_DAHValidateBasic(t *testing.T) {
 	tooSmallDah.ColumnRoots = [][]byte{bytes.Repeat([]byte{2}, 32)}
 	tooSmallDah.RowRoots = [][]byte{bytes.Repeat([]byte{2}, 32)}
 	// use a bad hash
-	badHashDah := MinDataAvailabilityHeader()
+	badHashDah := MinDataAvailabilityHeader(appconsts.LatestVersion)
 	badHashDah.hash = []byte{1, 2, 3, 4}
 	// dah with not equal number of roots
-	mismatchDah := MinDataAvailabilityHeader()
+	mismatchDah := MinDataAvailabilityHeader(appconsts.LatestVersion)
 	mismatchDah.ColumnRoots = append(mismatchDah.ColumnRoots, bytes.Repeat([]byte{2}, 32))
 
 	tests := []test{
 		{
 			name: "min",
-			dah:  MinDataAvailabilityHeader(),
+			dah:  MinDataAvailabilityHeader(appconsts.LatestVersion),
 		},
 		{
 			name: "max",
@@ -224,7 +224,7 @@ func TestSquareSize(t *testing.T) {
 	testCases := []testCase{
 		{
 			name: "min data availability header has an original square size of 1",
-			dah:  MinDataAvailabilityHeader(),
+			dah:  MinDataAvailabilityHeader(appconsts.LatestVersion),
 			want: 1,
 		},
 		{
@@ -273,7 +273,7 @@ func sortByteArrays(arr [][]byte) {
 func maxDataAvailabilityHeader(t *testing.T) (dah DataAvailabilityHeader) {
 	shares := generateShares(appconsts.DefaultSquareSizeUpperBound * appconsts.DefaultSquareSizeUpperBound)
 
-	eds, err := ExtendShares(shares)
+	eds, err := ExtendShares(shares, appconsts.LatestVersion)
 	require.NoError(t, err)
 
 	dah, err = NewDataAvailabilityHeader(eds)
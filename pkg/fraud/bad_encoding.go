@@ -0,0 +1,205 @@
+// Package fraud creates and verifies fraud proofs for blocks whose data square
+// was not constructed according to the protocol.
+package fraud
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/celestiaorg/celestia-app/pkg/appconsts"
+	"github.com/celestiaorg/celestia-app/pkg/da"
+	appns "github.com/celestiaorg/celestia-app/pkg/namespace"
+	"github.com/celestiaorg/celestia-app/pkg/wrapper"
+	"github.com/celestiaorg/nmt"
+	"github.com/celestiaorg/rsmt2d"
+)
+
+var (
+	// ErrNoBadEncoding is returned if every row and column of an extended
+	// data square is correctly erasure coded.
+	ErrNoBadEncoding = errors.New("extended data square is correctly erasure coded")
+	// ErrInvalidProof is returned if a bad encoding fraud proof doesn't prove
+	// that an axis was incorrectly erasure coded.
+	ErrInvalidProof = errors.New("invalid bad encoding fraud proof")
+)
+
+// BadEncodingProof proves that a row or column of an extended data square was
+// not erasure coded correctly. It contains the shares of the original half of
+// the axis, each with an inclusion proof to the root of the orthogonal axis it
+// belongs to, along with the root of the axis itself. Re-encoding these shares
+// yields an axis whose root differs from the root committed to in the data
+// availability header.
+type BadEncodingProof struct {
+	// Axis is the type of the incorrectly encoded axis.
+	Axis rsmt2d.Axis
+	// Index is the index of the incorrectly encoded axis.
+	Index uint
+	// Root is the root of the axis in the data availability header.
+	Root []byte
+	// Shares are the shares of the original half of the axis in order.
+	Shares []ShareWithProof
+}
+
+// ShareWithProof is a share along with its inclusion proof to the root of the
+// axis orthogonal to the axis of a BadEncodingProof.
+type ShareWithProof struct {
+	Share []byte
+	Proof nmt.Proof
+}
+
+// prover is implemented by trees that can prove the inclusion of their leaves,
+// such as wrapper.ErasuredNamespacedMerkleTree.
+type prover interface {
+	ProveRange(start, end int) (nmt.Proof, error)
+}
+
+// NewBadEncodingProof searches the rows and then the columns of the extended
+// data square for an axis that is not erasure coded correctly and returns a
+// proof for the first one found. The data availability header must have been
+// computed over the extended data square. The axes are re-encoded with the
+// codec of appVersion, the app version of the block. newTree creates the trees
+// used to compute the roots of the square and must create trees that implement
+// ProveRange, like wrapper.NewConstructor does. ErrNoBadEncoding is returned if
+// the square is correctly erasure coded.
+func NewBadEncodingProof(eds *rsmt2d.ExtendedDataSquare, dah *da.DataAvailabilityHeader, appVersion uint64, newTree rsmt2d.TreeConstructorFn) (*BadEncodingProof, error) {
+	width := eds.Width()
+	if len(dah.RowRoots) != int(width) || len(dah.ColumnRoots) != int(width) {
+		return nil, fmt.Errorf("data availability header has %d row and %d column roots for a square of width %d", len(dah.RowRoots), len(dah.ColumnRoots), width)
+	}
+
+	codec := appconsts.Codec(appVersion)
+	for _, axis := range []rsmt2d.Axis{rsmt2d.Row, rsmt2d.Col} {
+		roots := axisRoots(dah, axis)
+		for i := uint(0); i < width; i++ {
+			root, err := encodedRoot(codec, axisShares(eds, axis, i)[:width/2], axis, i, newTree)
+			if err != nil {
+				return nil, fmt.Errorf("computing root of re-encoded %s %d: %w", axis, i, err)
+			}
+			if !bytes.Equal(root, roots[i]) {
+				return newBadEncodingProof(eds, axis, i, roots[i], newTree)
+			}
+		}
+	}
+	return nil, ErrNoBadEncoding
+}
+
+// newBadEncodingProof creates the proof for the axis by proving each share of
+// its original half against the orthogonal axis.
+func newBadEncodingProof(eds *rsmt2d.ExtendedDataSquare, axis rsmt2d.Axis, index uint, root []byte, newTree rsmt2d.TreeConstructorFn) (*BadEncodingProof, error) {
+	orthogonal := orthogonalAxis(axis)
+	shares := axisShares(eds, axis, index)[:eds.Width()/2]
+	proof := &BadEncodingProof{
+		Axis:   axis,
+		Index:  index,
+		Root:   root,
+		Shares: make([]ShareWithProof, len(shares)),
+	}
+	for j, share := range shares {
+		tree := newTree(orthogonal, uint(j))
+		for _, s := range axisShares(eds, orthogonal, uint(j)) {
+			if err := tree.Push(s); err != nil {
+				return nil, fmt.Errorf("computing %s %d: %w", orthogonal, j, err)
+			}
+		}
+		p, ok := tree.(prover)
+		if !ok {
+			return nil, fmt.Errorf("tree of type %T can't create inclusion proofs", tree)
+		}
+		shareProof, err := p.ProveRange(int(index), int(index)+1)
+		if err != nil {
+			return nil, fmt.Errorf("proving share %d of %s %d: %w", index, orthogonal, j, err)
+		}
+		proof.Shares[j] = ShareWithProof{Share: share, Proof: shareProof}
+	}
+	return proof, nil
+}
+
+// Verify checks that the proof shows that an axis of the square committed to
+// by the data availability header was incorrectly erasure coded with the codec
+// of appVersion, the app version of the block. It doesn't depend on any state
+// other than the block header. An error wrapping ErrInvalidProof is returned if
+// the proof is invalid.
+func (p *BadEncodingProof) Verify(dah *da.DataAvailabilityHeader, appVersion uint64) error {
+	if err := dah.ValidateBasic(); err != nil {
+		return err
+	}
+	if p.Axis != rsmt2d.Row && p.Axis != rsmt2d.Col {
+		return fmt.Errorf("%w: unknown axis %d", ErrInvalidProof, p.Axis)
+	}
+	roots := axisRoots(dah, p.Axis)
+	orthogonalRoots := axisRoots(dah, orthogonalAxis(p.Axis))
+	width := uint(len(roots))
+	if p.Index >= width {
+		return fmt.Errorf("%w: %s %d is out of bounds for a square of width %d", ErrInvalidProof, p.Axis, p.Index, width)
+	}
+	if !bytes.Equal(p.Root, roots[p.Index]) {
+		return fmt.Errorf("%w: root of %s %d differs from the data availability header", ErrInvalidProof, p.Axis, p.Index)
+	}
+	if len(p.Shares) != int(width/2) {
+		return fmt.Errorf("%w: expected %d shares, got %d", ErrInvalidProof, width/2, len(p.Shares))
+	}
+
+	shares := make([][]byte, len(p.Shares))
+	for j, s := range p.Shares {
+		if len(s.Share) != appconsts.ShareSize {
+			return fmt.Errorf("%w: share %d has size %d", ErrInvalidProof, j, len(s.Share))
+		}
+		// the shares of the original half of the axis are in the first half
+		// of their orthogonal axis if the axis itself is in the first half
+		ns := appns.ParitySharesNamespace.Bytes()
+		if p.Index < width/2 {
+			ns = s.Share[:appconsts.NamespaceSize]
+		}
+		if !s.Proof.VerifyInclusion(appconsts.NewBaseHashFunc(), ns, [][]byte{s.Share}, orthogonalRoots[j]) {
+			return fmt.Errorf("%w: share %d is not included in %s %d", ErrInvalidProof, j, orthogonalAxis(p.Axis), j)
+		}
+		shares[j] = s.Share
+	}
+
+	root, err := encodedRoot(appconsts.Codec(appVersion), shares, p.Axis, p.Index, wrapper.NewConstructor(uint64(width/2)))
+	if err != nil {
+		return fmt.Errorf("%w: computing root of re-encoded %s %d: %v", ErrInvalidProof, p.Axis, p.Index, err)
+	}
+	if bytes.Equal(root, p.Root) {
+		return fmt.Errorf("%w: %s %d is correctly erasure coded", ErrInvalidProof, p.Axis, p.Index)
+	}
+	return nil
+}
+
+// encodedRoot erasure codes the original half of an axis with the codec and
+// returns the root of the resulting axis.
+func encodedRoot(codec rsmt2d.Codec, original [][]byte, axis rsmt2d.Axis, index uint, newTree rsmt2d.TreeConstructorFn) ([]byte, error) {
+	parity, err := codec.Encode(original)
+	if err != nil {
+		return nil, err
+	}
+	tree := newTree(axis, index)
+	for _, share := range append(append([][]byte{}, original...), parity...) {
+		if err := tree.Push(share); err != nil {
+			return nil, err
+		}
+	}
+	return tree.Root()
+}
+
+func axisShares(eds *rsmt2d.ExtendedDataSquare, axis rsmt2d.Axis, index uint) [][]byte {
+	if axis == rsmt2d.Row {
+		return eds.Row(index)
+	}
+	return eds.Col(index)
+}
+
+func axisRoots(dah *da.DataAvailabilityHeader, axis rsmt2d.Axis) [][]byte {
+	if axis == rsmt2d.Row {
+		return dah.RowRoots
+	}
+	return dah.ColumnRoots
+}
+
+func orthogonalAxis(axis rsmt2d.Axis) rsmt2d.Axis {
+	if axis == rsmt2d.Row {
+		return rsmt2d.Col
+	}
+	return rsmt2d.Row
+}
@@ -0,0 +1,220 @@
+package fraud_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/celestiaorg/celestia-app/pkg/appconsts"
+	"github.com/celestiaorg/celestia-app/pkg/da"
+	"github.com/celestiaorg/celestia-app/pkg/fraud"
+	"github.com/celestiaorg/celestia-app/pkg/wrapper"
+	"github.com/celestiaorg/celestia-app/test/util/malicious"
+	"github.com/celestiaorg/celestia-app/test/util/testfactory"
+	"github.com/celestiaorg/rsmt2d"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	squareSize = 4
+	appVersion = appconsts.LatestVersion
+)
+
+func TestBadEncodingProof(t *testing.T) {
+	type test struct {
+		name          string
+		row, col      uint
+		expectedAxis  rsmt2d.Axis
+		expectedIndex uint
+	}
+	tests := []test{
+		{
+			name:          "corrupted original share",
+			row:           0,
+			col:           0,
+			expectedAxis:  rsmt2d.Row,
+			expectedIndex: 0,
+		},
+		{
+			name:          "corrupted row parity share",
+			row:           1,
+			col:           squareSize + 2,
+			expectedAxis:  rsmt2d.Row,
+			expectedIndex: 1,
+		},
+		{
+			name:          "corrupted column parity share",
+			row:           squareSize + 1,
+			col:           3,
+			expectedAxis:  rsmt2d.Row,
+			expectedIndex: squareSize + 1,
+		},
+		{
+			name:          "corrupted extended share",
+			row:           2*squareSize - 1,
+			col:           2*squareSize - 1,
+			expectedAxis:  rsmt2d.Row,
+			expectedIndex: 2*squareSize - 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eds, dah := corruptedSquare(t, tt.row, tt.col)
+
+			proof, err := fraud.NewBadEncodingProof(eds, &dah, appVersion, malicious.NewConstructor(squareSize))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedAxis, proof.Axis)
+			assert.Equal(t, tt.expectedIndex, proof.Index)
+			require.Len(t, proof.Shares, squareSize)
+			require.NoError(t, proof.Verify(&dah, appVersion))
+		})
+	}
+}
+
+func TestBadEncodingProofVerifyRejects(t *testing.T) {
+	eds, dah := corruptedSquare(t, 0, 0)
+	honestEDS, honestDAH := honestSquare(t)
+
+	type test struct {
+		name   string
+		modify func(p *fraud.BadEncodingProof)
+		dah    *da.DataAvailabilityHeader
+	}
+	tests := []test{
+		{
+			name: "tampered share",
+			modify: func(p *fraud.BadEncodingProof) {
+				share := bytes.Clone(p.Shares[1].Share)
+				share[len(share)-1] ^= 0xff
+				p.Shares[1].Share = share
+			},
+			dah: &dah,
+		},
+		{
+			name: "missing share",
+			modify: func(p *fraud.BadEncodingProof) {
+				p.Shares = p.Shares[:squareSize-1]
+			},
+			dah: &dah,
+		},
+		{
+			name: "wrong root",
+			modify: func(p *fraud.BadEncodingProof) {
+				p.Root = dah.RowRoots[1]
+			},
+			dah: &dah,
+		},
+		{
+			name: "out of bounds index",
+			modify: func(p *fraud.BadEncodingProof) {
+				p.Index = 2 * squareSize
+			},
+			dah: &dah,
+		},
+		{
+			name:   "different header",
+			modify: func(p *fraud.BadEncodingProof) {},
+			dah:    &honestDAH,
+		},
+		{
+			name: "correctly encoded axis",
+			modify: func(p *fraud.BadEncodingProof) {
+				*p = *honestProof(t, honestEDS, &honestDAH, rsmt2d.Col, 2)
+			},
+			dah: &honestDAH,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proof, err := fraud.NewBadEncodingProof(eds, &dah, appVersion, malicious.NewConstructor(squareSize))
+			require.NoError(t, err)
+			tt.modify(proof)
+			assert.ErrorIs(t, proof.Verify(tt.dah, appVersion), fraud.ErrInvalidProof)
+		})
+	}
+}
+
+func TestNoBadEncoding(t *testing.T) {
+	t.Run("honest square", func(t *testing.T) {
+		eds, dah := honestSquare(t)
+		_, err := fraud.NewBadEncodingProof(eds, &dah, appVersion, wrapper.NewConstructor(squareSize))
+		assert.ErrorIs(t, err, fraud.ErrNoBadEncoding)
+	})
+	t.Run("correctly encoded malicious square", func(t *testing.T) {
+		// namespaces out of order break other rules but not the encoding
+		data := testfactory.GenerateRandNamespacedRawData(squareSize * squareSize)
+		for i, j := 0, len(data)-1; i < j; i, j = i+1, j-1 {
+			data[i], data[j] = data[j], data[i]
+		}
+		eds, err := malicious.ExtendShares(data, appVersion)
+		require.NoError(t, err)
+		dah, err := da.NewDataAvailabilityHeader(eds)
+		require.NoError(t, err)
+
+		_, err = fraud.NewBadEncodingProof(eds, &dah, appVersion, malicious.NewConstructor(squareSize))
+		assert.ErrorIs(t, err, fraud.ErrNoBadEncoding)
+	})
+}
+
+// corruptedSquare returns an extended data square in which the share at row
+// and col was modified after erasure coding, along with the header committing
+// to the corrupted square. Like a malicious block producer, it uses the trees
+// of the malicious package to compute the roots.
+func corruptedSquare(t *testing.T, row, col uint) (*rsmt2d.ExtendedDataSquare, da.DataAvailabilityHeader) {
+	eds, err := malicious.ExtendShares(testfactory.GenerateRandNamespacedRawData(squareSize*squareSize), appVersion)
+	require.NoError(t, err)
+
+	flattened := eds.Flattened()
+	index := row*eds.Width() + col
+	share := bytes.Clone(flattened[index])
+	// keep the namespace of the share so that the trees stay ordered
+	share[len(share)-1] ^= 0xff
+	flattened[index] = share
+
+	corrupted, err := rsmt2d.ImportExtendedDataSquare(flattened, appconsts.Codec(appVersion), malicious.NewConstructor(squareSize))
+	require.NoError(t, err)
+	dah, err := da.NewDataAvailabilityHeader(corrupted)
+	require.NoError(t, err)
+	return corrupted, dah
+}
+
+func honestSquare(t *testing.T) (*rsmt2d.ExtendedDataSquare, da.DataAvailabilityHeader) {
+	eds, err := da.ExtendShares(testfactory.GenerateRandNamespacedRawData(squareSize*squareSize), appVersion)
+	require.NoError(t, err)
+	dah, err := da.NewDataAvailabilityHeader(eds)
+	require.NoError(t, err)
+	return eds, dah
+}
+
+// honestProof creates a proof for an axis of a correctly encoded square by
+// corrupting the root of the axis in a copy of the header.
+func honestProof(t *testing.T, eds *rsmt2d.ExtendedDataSquare, dah *da.DataAvailabilityHeader, axis rsmt2d.Axis, index uint) *fraud.BadEncodingProof {
+	corruptedDAH := *dah
+	roots := append([][]byte{}, dah.RowRoots...)
+	if axis == rsmt2d.Col {
+		roots = append([][]byte{}, dah.ColumnRoots...)
+	}
+	roots[index] = bytes.Clone(roots[index])
+	roots[index][len(roots[index])-1] ^= 0xff
+	if axis == rsmt2d.Row {
+		corruptedDAH.RowRoots = roots
+	} else {
+		corruptedDAH.ColumnRoots = roots
+	}
+
+	proof, err := fraud.NewBadEncodingProof(eds, &corruptedDAH, appVersion, wrapper.NewConstructor(squareSize))
+	require.NoError(t, err)
+	require.Equal(t, axis, proof.Axis)
+	require.Equal(t, index, proof.Index)
+	proof.Root = axisRoot(dah, axis, index)
+	return proof
+}
+
+func axisRoot(dah *da.DataAvailabilityHeader, axis rsmt2d.Axis, index uint) []byte {
+	if axis == rsmt2d.Row {
+		return dah.RowRoots[index]
+	}
+	return dah.ColumnRoots[index]
+}
@@ -41,7 +41,7 @@ func NewTxInclusionProof(txs [][]byte, txIndex, appVersion uint64) (types.ShareP
 	}
 
 	namespace := getTxNamespace(txs[txIndex])
-	return NewShareInclusionProof(dataSquare, namespace, shareRange)
+	return NewShareInclusionProof(dataSquare, namespace, shareRange, appVersion)
 }
 
 func getTxNamespace(tx []byte) (ns appns.Namespace) {
@@ -53,12 +53,14 @@ func getTxNamespace(tx []byte) (ns appns.Namespace) {
 }
 
 // NewShareInclusionProof returns an NMT inclusion proof for a set of shares
-// belonging to the same namespace to the data root.
+// belonging to the same namespace to the data root. The data square is extended
+// with the codec of the app version.
 // Expects the share range to be pre-validated.
 func NewShareInclusionProof(
 	dataSquare square.Square,
 	namespace appns.Namespace,
 	shareRange shares.Range,
+	appVersion uint64,
 ) (types.ShareProof, error) {
 	squareSize := dataSquare.Size()
 	startRow := shareRange.Start / squareSize
@@ -66,7 +68,7 @@ func NewShareInclusionProof(
 	startLeaf := shareRange.Start % squareSize
 	endLeaf := (shareRange.End - 1) % squareSize
 
-	eds, err := da.ExtendShares(shares.ToBytes(dataSquare))
+	eds, err := da.ExtendShares(shares.ToBytes(dataSquare), appVersion)
 	if err != nil {
 		return types.ShareProof{}, err
 	}
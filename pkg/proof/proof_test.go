@@ -2,6 +2,7 @@ package proof_test
 
 import (
 	"bytes"
+	"fmt"
 	"testing"
 
 	tmrand "github.com/tendermint/tendermint/libs/rand"
@@ -75,20 +76,22 @@ func TestNewTxInclusionProof(t *testing.T) {
 		},
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			proof, err := proof.NewTxInclusionProof(
-				tt.txs,
-				tt.txIndex,
-				appconsts.LatestVersion,
-			)
-			if tt.expectErr {
-				assert.Error(t, err)
-				return
-			}
-			assert.NoError(t, err)
-			assert.True(t, proof.VerifyProof())
-		})
+	for version := uint64(1); version <= appconsts.LatestVersion; version++ {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("v%d %s", version, tt.name), func(t *testing.T) {
+				proof, err := proof.NewTxInclusionProof(
+					tt.txs,
+					tt.txIndex,
+					version,
+				)
+				if tt.expectErr {
+					assert.Error(t, err)
+					return
+				}
+				assert.NoError(t, err)
+				assert.True(t, proof.VerifyProof())
+			})
+		}
 	}
 }
 
@@ -109,7 +112,7 @@ func TestNewShareInclusionProof(t *testing.T) {
 	}
 
 	// erasure the data square which we use to create the data root.
-	eds, err := da.ExtendShares(shares.ToBytes(dataSquare))
+	eds, err := da.ExtendShares(shares.ToBytes(dataSquare), appconsts.LatestVersion)
 	require.NoError(t, err)
 
 	// create the new data root by creating the data availability header (merkle
@@ -218,6 +221,7 @@ func TestNewShareInclusionProof(t *testing.T) {
 				dataSquare,
 				tt.namespaceID,
 				shares.NewRange(tt.startingShare, tt.endingShare),
+				appconsts.LatestVersion,
 			)
 			require.NoError(t, err)
 			assert.NoError(t, proof.Validate(dataRoot))
@@ -236,7 +240,7 @@ func TestAllSharesInclusionProof(t *testing.T) {
 	assert.Equal(t, 256, len(dataSquare))
 
 	// erasure the data square which we use to create the data root.
-	eds, err := da.ExtendShares(shares.ToBytes(dataSquare))
+	eds, err := da.ExtendShares(shares.ToBytes(dataSquare), appconsts.LatestVersion)
 	require.NoError(t, err)
 
 	// create the new data root by creating the data availability header (merkle
@@ -252,6 +256,7 @@ func TestAllSharesInclusionProof(t *testing.T) {
 		dataSquare,
 		appns.TxNamespace,
 		shares.NewRange(0, 256),
+		appconsts.LatestVersion,
 	)
 	require.NoError(t, err)
 	assert.NoError(t, proof.Validate(dataRoot))
@@ -105,6 +105,7 @@ func QueryShareInclusionProof(_ sdk.Context, path []string, req abci.RequestQuer
 		dataSquare,
 		nID,
 		shares.NewRange(int(beginShare), int(endShare)),
+		pbb.Header.Version.App,
 	)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,128 @@
+package shares_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/celestiaorg/celestia-app/pkg/appconsts"
+	"github.com/celestiaorg/celestia-app/pkg/blob"
+	appns "github.com/celestiaorg/celestia-app/pkg/namespace"
+	"github.com/celestiaorg/celestia-app/pkg/shares"
+	"github.com/celestiaorg/celestia-app/test/util/testfactory"
+	"github.com/stretchr/testify/require"
+	coretypes "github.com/tendermint/tendermint/types"
+)
+
+// FuzzParseShares uses fuzzing to test that parsing arbitrary shares never
+// panics and that the share sequences returned by `ParseShares` partition the
+// shares that were parsed.
+func FuzzParseShares(f *testing.F) {
+	txShares, pfbShares, _, err := shares.SplitTxs(testfactory.GenerateRandomTxs(5, 700))
+	require.NoError(f, err)
+	blobShares, err := shares.SplitBlobs(testfactory.GenerateRandomlySizedBlobs(3, 2000)...)
+	require.NoError(f, err)
+	padding, err := shares.NamespacePaddingShares(appns.RandomBlobNamespace(), appconsts.ShareVersionZero, 2)
+	require.NoError(f, err)
+
+	f.Add(joinShares(txShares))
+	f.Add(joinShares(blobShares))
+	f.Add(joinShares(append(append(append(txShares, pfbShares...), blobShares...), padding...)))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		rawShares := make([][]byte, 0, len(data)/appconsts.ShareSize)
+		for len(data) >= appconsts.ShareSize {
+			rawShares = append(rawShares, data[:appconsts.ShareSize])
+			data = data[appconsts.ShareSize:]
+		}
+		parsedShares, err := shares.FromBytes(rawShares)
+		require.NoError(t, err)
+
+		sequences, err := shares.ParseShares(parsedShares, false)
+		if err != nil {
+			return
+		}
+		var sequenceShares []shares.Share
+		for _, sequence := range sequences {
+			sequenceShares = append(sequenceShares, sequence.Shares...)
+		}
+		require.Equal(t, rawShares, shares.ToBytes(sequenceShares))
+
+		// ignoring padding may only drop sequences
+		withoutPadding, err := shares.ParseShares(parsedShares, true)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(withoutPadding), len(sequences))
+	})
+}
+
+// FuzzSplitParse uses fuzzing to test that parsing is consistent with
+// splitting, that is that the txs and blobs parsed from the shares they were
+// split into are the same txs and blobs.
+func FuzzSplitParse(f *testing.F) {
+	ns := appns.MustNewV0(bytes.Repeat([]byte{1}, appns.NamespaceVersionZeroIDSize))
+	f.Add(joinTxs(testfactory.GenerateRandomTxs(1, 100)), uint16(100), testfactory.GenerateRandomBlob(100).Data)
+	f.Add(joinTxs(testfactory.GenerateRandomTxs(10, 300)), uint16(300), testfactory.GenerateRandomBlob(5000).Data)
+	f.Add(joinTxs(testfactory.GenerateRandomTxs(3, 1500)), uint16(1500), []byte{})
+	f.Fuzz(func(t *testing.T, txData []byte, txSize uint16, blobData []byte) {
+		if txSize == 0 {
+			t.Skip()
+		}
+		var txs, normalTxs, wrappedTxs coretypes.Txs
+		for len(txData) > 0 {
+			tx := coretypes.Tx(txData[:min(int(txSize), len(txData))])
+			txData = txData[len(tx):]
+			txs = append(txs, tx)
+			if _, isIndexWrapper := coretypes.UnmarshalIndexWrapper(tx); isIndexWrapper {
+				wrappedTxs = append(wrappedTxs, tx)
+			} else {
+				normalTxs = append(normalTxs, tx)
+			}
+		}
+
+		txShares, pfbShares, _, err := shares.SplitTxs(txs)
+		require.NoError(t, err)
+		parsedTxs, err := shares.ParseTxs(txShares)
+		require.NoError(t, err)
+		require.Equal(t, len(normalTxs), len(parsedTxs))
+		for i := range normalTxs {
+			require.Equal(t, normalTxs[i], parsedTxs[i])
+		}
+		parsedWrappedTxs, err := shares.ParseTxs(pfbShares)
+		require.NoError(t, err)
+		require.Equal(t, len(wrappedTxs), len(parsedWrappedTxs))
+		for i := range wrappedTxs {
+			require.Equal(t, wrappedTxs[i], parsedWrappedTxs[i])
+		}
+
+		allShares := append(txShares, pfbShares...)
+		wantSequences := 0
+		if len(normalTxs) > 0 {
+			wantSequences++
+		}
+		if len(wrappedTxs) > 0 {
+			wantSequences++
+		}
+		if len(blobData) > 0 {
+			b := blob.New(ns, blobData, appconsts.ShareVersionZero)
+			blobShares, err := shares.SplitBlobs(b)
+			require.NoError(t, err)
+			require.Len(t, blobShares, shares.SparseSharesNeeded(uint32(len(blobData))))
+			parsedBlobs, err := shares.ParseBlobs(blobShares)
+			require.NoError(t, err)
+			require.Len(t, parsedBlobs, 1)
+			require.Equal(t, b, parsedBlobs[0])
+			allShares = append(allShares, blobShares...)
+			wantSequences++
+		}
+
+		sequences, err := shares.ParseShares(allShares, true)
+		require.NoError(t, err)
+		require.Len(t, sequences, wantSequences)
+	})
+}
+
+func joinShares(s []shares.Share) []byte {
+	return bytes.Join(shares.ToBytes(s), nil)
+}
+
+func joinTxs(txs coretypes.Txs) []byte {
+	return bytes.Join(txs.ToSliceOfBytes(), nil)
+}
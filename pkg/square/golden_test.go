@@ -0,0 +1,260 @@
+package square_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/celestiaorg/celestia-app/app"
+	"github.com/celestiaorg/celestia-app/app/encoding"
+	"github.com/celestiaorg/celestia-app/pkg/appconsts"
+	"github.com/celestiaorg/celestia-app/pkg/da"
+	"github.com/celestiaorg/celestia-app/pkg/inclusion"
+	"github.com/celestiaorg/celestia-app/pkg/shares"
+	"github.com/celestiaorg/celestia-app/pkg/square"
+	"github.com/celestiaorg/celestia-app/pkg/user"
+	"github.com/celestiaorg/celestia-app/test/util/blobfactory"
+	"github.com/celestiaorg/celestia-app/test/util/testfactory"
+	"github.com/celestiaorg/celestia-app/test/util/testnode"
+	blobtypes "github.com/celestiaorg/celestia-app/x/blob/types"
+	"github.com/celestiaorg/rsmt2d"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tmrand "github.com/tendermint/tendermint/libs/rand"
+	coretypes "github.com/tendermint/tendermint/types"
+)
+
+// updateGolden regenerates the golden squares from the golden blocks instead
+// of checking them. The blocks themselves are only generated if their file
+// doesn't exist.
+var updateGolden = flag.Bool("update-golden", false, "regenerate the golden squares of every app version")
+
+const (
+	goldenDir        = "testdata/golden"
+	goldenBlocksFile = "blocks.json"
+)
+
+// goldenBlock is the block data that a golden square is built from.
+type goldenBlock struct {
+	Name          string   `json:"name"`
+	MaxSquareSize int      `json:"max_square_size"`
+	Txs           [][]byte `json:"txs"`
+}
+
+// goldenSquare is the expected layout of the square of a golden block.
+type goldenSquare struct {
+	Name string `json:"name"`
+	// OrderedTxs are the indexes of the txs of the block in the order in
+	// which they were written to the square. Txs that didn't fit are
+	// omitted.
+	OrderedTxs []int  `json:"ordered_txs"`
+	SquareSize int    `json:"square_size"`
+	DataRoot   string `json:"data_root"`
+	// SharesHash is the sha256 hash of all shares of the original square.
+	SharesHash string      `json:"shares_hash"`
+	PFBs       []goldenPFB `json:"pfbs"`
+}
+
+// goldenPFB is the layout of the blobs of a PFB in a golden square.
+type goldenPFB struct {
+	ShareIndexes []uint32 `json:"share_indexes"`
+	// Commitments are computed from the subtree roots of the extended
+	// square.
+	Commitments []string `json:"commitments"`
+}
+
+// TestGoldenSquares checks that the squares built from the golden blocks
+// match the layout recorded for every app version byte for byte. Any
+// difference is a consensus breaking change. If the change is intended, the
+// golden squares are regenerated with `make golden` and the diff of the
+// testdata is reviewed along with the change.
+func TestGoldenSquares(t *testing.T) {
+	blocks := loadGoldenBlocks(t)
+	for version := uint64(1); version <= appconsts.LatestVersion; version++ {
+		path := filepath.Join(goldenDir, fmt.Sprintf("v%d.json", version))
+		got := make([]goldenSquare, len(blocks))
+		for i, block := range blocks {
+			got[i] = computeGoldenSquare(t, block, version)
+		}
+		if *updateGolden {
+			writeGolden(t, path, got)
+			continue
+		}
+
+		var want []goldenSquare
+		bz, err := os.ReadFile(path)
+		require.NoError(t, err, "golden squares of v%d are missing, run `make golden`", version)
+		require.NoError(t, json.Unmarshal(bz, &want))
+		require.Len(t, want, len(blocks), "golden squares of v%d are out of date, run `make golden`", version)
+		for i := range blocks {
+			t.Run(fmt.Sprintf("v%d/%s", version, blocks[i].Name), func(t *testing.T) {
+				assert.Equal(t, want[i], got[i])
+			})
+		}
+	}
+}
+
+// computeGoldenSquare builds the square of the block the way a proposer does
+// and checks that the square is reproduced from the ordered txs the way a
+// validator does.
+func computeGoldenSquare(t *testing.T, block goldenBlock, appVersion uint64) goldenSquare {
+	t.Helper()
+	dataSquare, orderedTxs, err := square.Build(block.Txs, appVersion, block.MaxSquareSize)
+	require.NoError(t, err)
+	constructed, err := square.Construct(orderedTxs, appVersion, block.MaxSquareSize)
+	require.NoError(t, err)
+	require.True(t, dataSquare.Equals(constructed), "constructed square differs from the built square")
+
+	decoder := encoding.MakeConfig(app.ModuleEncodingRegisters...).TxConfig.TxDecoder()
+	deconstructed, err := square.Deconstruct(dataSquare, decoder)
+	require.NoError(t, err)
+	require.Equal(t, orderedTxs, deconstructed.ToSliceOfBytes())
+
+	indexes := make(map[string]int, len(block.Txs))
+	for i, tx := range block.Txs {
+		indexes[string(tx)] = i
+	}
+	result := goldenSquare{
+		Name:       block.Name,
+		OrderedTxs: make([]int, len(orderedTxs)),
+		SquareSize: dataSquare.Size(),
+		PFBs:       []goldenPFB{},
+	}
+	for i, tx := range orderedTxs {
+		index, ok := indexes[string(tx)]
+		require.True(t, ok, "ordered tx %d is not a tx of the block", i)
+		result.OrderedTxs[i] = index
+	}
+
+	sharesHash := sha256.New()
+	for _, share := range dataSquare {
+		sharesHash.Write(share.ToBytes())
+	}
+	result.SharesHash = hex.EncodeToString(sharesHash.Sum(nil))
+
+	cacher := inclusion.NewSubtreeCacher(uint64(dataSquare.Size()))
+	eds, err := rsmt2d.ComputeExtendedDataSquare(shares.ToBytes(dataSquare), appconsts.Codec(appVersion), cacher.Constructor)
+	require.NoError(t, err)
+	dah, err := da.NewDataAvailabilityHeader(eds)
+	require.NoError(t, err)
+	result.DataRoot = hex.EncodeToString(dah.Hash())
+
+	wrappedPFBs, err := dataSquare.WrappedPFBs()
+	require.NoError(t, err)
+	for _, wrappedPFB := range wrappedPFBs {
+		indexWrapper, isIndexWrapper := coretypes.UnmarshalIndexWrapper(wrappedPFB)
+		require.True(t, isIndexWrapper)
+		tx, err := decoder(indexWrapper.Tx)
+		require.NoError(t, err)
+		pfb, ok := tx.GetMsgs()[0].(*blobtypes.MsgPayForBlobs)
+		require.True(t, ok)
+
+		layout := goldenPFB{ShareIndexes: indexWrapper.ShareIndexes}
+		for blobIndex, shareIndex := range indexWrapper.ShareIndexes {
+			commitment, err := inclusion.GetCommitment(cacher, dah, int(shareIndex), shares.SparseSharesNeeded(pfb.BlobSizes[blobIndex]), appconsts.SubtreeRootThreshold(appVersion))
+			require.NoError(t, err)
+			// the layout must allow verifying the commitment that the
+			// user signed
+			require.Equal(t, pfb.ShareCommitments[blobIndex], commitment)
+			layout.Commitments = append(layout.Commitments, hex.EncodeToString(commitment))
+		}
+		result.PFBs = append(result.PFBs, layout)
+	}
+	return result
+}
+
+// loadGoldenBlocks reads the golden blocks. If they don't exist and the
+// golden files are being updated, they are generated first.
+func loadGoldenBlocks(t *testing.T) []goldenBlock {
+	t.Helper()
+	path := filepath.Join(goldenDir, goldenBlocksFile)
+	bz, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && *updateGolden {
+		blocks := generateGoldenBlocks(t)
+		writeGolden(t, path, blocks)
+		return blocks
+	}
+	require.NoError(t, err, "golden blocks are missing, run `make golden`")
+
+	var blocks []goldenBlock
+	require.NoError(t, json.Unmarshal(bz, &blocks))
+	return blocks
+}
+
+// generateGoldenBlocks generates blocks that cover the different kinds of
+// layouts of a square. The blob data is random, which is why the blocks are
+// checked in and never regenerated while they exist.
+func generateGoldenBlocks(t *testing.T) []goldenBlock {
+	t.Helper()
+	encCfg := encoding.MakeConfig(app.ModuleEncodingRegisters...)
+	kr := testfactory.TestKeyring(encCfg.Codec)
+	newSigner := func() *user.Signer {
+		signer, err := user.NewSigner(kr, nil, testnode.TestAddress(), encCfg.TxConfig, testfactory.ChainID, 1, 0)
+		require.NoError(t, err)
+		return signer
+	}
+	rand := tmrand.NewRand()
+	rand.Seed(1)
+
+	toBytes := func(txs []coretypes.Tx) [][]byte {
+		return coretypes.Txs(txs).ToSliceOfBytes()
+	}
+	signer := newSigner()
+	mixedTxs := toBytes(blobfactory.GenerateManyRandomRawSendTxsSameSigner(rand, signer, 10))
+	mixedTxs = append(mixedTxs, toBytes(blobfactory.RandBlobTxs(signer, rand, 5, 1, 600))...)
+	mixedTxs = append(mixedTxs, toBytes(blobfactory.RandBlobTxs(signer, rand, 5, 2, 3000))...)
+	mixedTxs = shuffle(rand, mixedTxs)
+
+	return []goldenBlock{
+		{
+			Name:          "empty",
+			MaxSquareSize: appconsts.DefaultSquareSizeUpperBound,
+			Txs:           [][]byte{},
+		},
+		{
+			Name:          "normal txs",
+			MaxSquareSize: appconsts.DefaultSquareSizeUpperBound,
+			Txs:           toBytes(blobfactory.GenerateManyRandomRawSendTxsSameSigner(rand, newSigner(), 20)),
+		},
+		{
+			Name:          "single blob",
+			MaxSquareSize: appconsts.DefaultSquareSizeUpperBound,
+			Txs:           toBytes(blobfactory.RandBlobTxs(newSigner(), rand, 1, 1, 100)),
+		},
+		{
+			Name:          "blob spanning many rows",
+			MaxSquareSize: appconsts.DefaultSquareSizeUpperBound,
+			Txs:           toBytes(blobfactory.RandBlobTxs(newSigner(), rand, 1, 1, 40000)),
+		},
+		{
+			Name:          "multi blob txs",
+			MaxSquareSize: appconsts.DefaultSquareSizeUpperBound,
+			Txs:           toBytes(blobfactory.RandBlobTxs(newSigner(), rand, 10, 3, 1500)),
+		},
+		{
+			Name:          "mixed txs",
+			MaxSquareSize: appconsts.DefaultSquareSizeUpperBound,
+			Txs:           mixedTxs,
+		},
+		{
+			Name:          "txs exceeding the max square size",
+			MaxSquareSize: 8,
+			Txs:           toBytes(blobfactory.RandBlobTxs(newSigner(), rand, 30, 2, 1000)),
+		},
+	}
+}
+
+func writeGolden(t *testing.T, path string, v any) {
+	t.Helper()
+	bz, err := json.MarshalIndent(v, "", "  ")
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, append(bz, '\n'), 0o644))
+}
@@ -1,20 +1,29 @@
 package square_test
 
 import (
+	"bytes"
+	"encoding/binary"
 	"testing"
 
 	"github.com/celestiaorg/celestia-app/app"
 	"github.com/celestiaorg/celestia-app/app/encoding"
 	"github.com/celestiaorg/celestia-app/pkg/appconsts"
+	pkgblob "github.com/celestiaorg/celestia-app/pkg/blob"
 	"github.com/celestiaorg/celestia-app/pkg/da"
 	"github.com/celestiaorg/celestia-app/pkg/inclusion"
 	"github.com/celestiaorg/celestia-app/pkg/shares"
 	"github.com/celestiaorg/celestia-app/pkg/square"
+	"github.com/celestiaorg/celestia-app/pkg/user"
+	"github.com/celestiaorg/celestia-app/test/util/blobfactory"
+	"github.com/celestiaorg/celestia-app/test/util/testfactory"
 	"github.com/celestiaorg/celestia-app/test/util/testnode"
 	blob "github.com/celestiaorg/celestia-app/x/blob/types"
 	"github.com/celestiaorg/rsmt2d"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/gogo/protobuf/proto"
 	"github.com/stretchr/testify/require"
 	tmrand "github.com/tendermint/tendermint/libs/rand"
+	core "github.com/tendermint/tendermint/types"
 )
 
 // FuzzSquare uses fuzzing to test the following:
@@ -58,7 +67,7 @@ func FuzzSquare(f *testing.F) {
 		require.Equal(t, orderedTxs, recomputedTxs.ToSliceOfBytes())
 
 		cacher := inclusion.NewSubtreeCacher(uint64(s.Size()))
-		eds, err := rsmt2d.ComputeExtendedDataSquare(shares.ToBytes(s), appconsts.DefaultCodec(), cacher.Constructor)
+		eds, err := rsmt2d.ComputeExtendedDataSquare(shares.ToBytes(s), appconsts.Codec(appconsts.LatestVersion), cacher.Constructor)
 		require.NoError(t, err)
 		dah, err := da.NewDataAvailabilityHeader(eds)
 		require.NoError(t, err)
@@ -87,6 +96,136 @@ func FuzzSquare(f *testing.F) {
 	})
 }
 
+// FuzzConstruct uses fuzzing to test the following:
+// - That neither `Construct` nor `Deconstruct` panics on arbitrary txs
+// - That the txs deconstructed from a square construct the same square
+// - That `Deconstruct` returns the txs used for its `Construct`ion if they are well formed
+func FuzzConstruct(f *testing.F) {
+	rand := tmrand.NewRand()
+	rand.Seed(1)
+	signer, err := testnode.NewOfflineSigner()
+	require.NoError(f, err)
+	for _, counts := range [][2]int{{0, 0}, {10, 0}, {0, 5}, {8, 12}} {
+		f.Add(encodeFuzzTxs(generateFuzzSeedTxs(rand, signer, counts[0], counts[1])))
+	}
+	f.Add(encodeFuzzTxs(testfactory.GenerateRandomTxs(5, 300).ToSliceOfBytes()))
+
+	encCfg := encoding.MakeConfig(app.ModuleEncodingRegisters...)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		txs := decodeFuzzTxs(data)
+		// empty txs can't be told apart from the padding of the tx
+		// namespace and are never deconstructed
+		for _, tx := range txs {
+			if len(tx) == 0 {
+				t.Skip()
+			}
+		}
+		s, err := square.Construct(txs, appconsts.LatestVersion, appconsts.DefaultSquareSizeUpperBound)
+		if err != nil {
+			return
+		}
+		deconstructed, err := square.Deconstruct(s, encCfg.TxConfig.TxDecoder())
+		if wellFormed(encCfg.TxConfig, txs) {
+			require.NoError(t, err)
+			require.Equal(t, txs, deconstructed.ToSliceOfBytes())
+		}
+		if err != nil {
+			return
+		}
+		s2, err := square.Construct(deconstructed.ToSliceOfBytes(), appconsts.LatestVersion, appconsts.DefaultSquareSizeUpperBound)
+		require.NoError(t, err)
+		require.True(t, s.Equals(s2))
+	})
+}
+
+// FuzzUnmarshalIndexWrapper uses fuzzing to test the following:
+// - That `UnmarshalIndexWrapper` never panics
+// - That a recognised index wrapper is recognised again after being marshalled with `MarshalIndexWrapper`
+func FuzzUnmarshalIndexWrapper(f *testing.F) {
+	rand := tmrand.NewRand()
+	rand.Seed(1)
+	signer, err := testnode.NewOfflineSigner()
+	require.NoError(f, err)
+	txs := generateFuzzSeedTxs(rand, signer, 5, 5)
+	s, err := square.Construct(txs, appconsts.LatestVersion, appconsts.DefaultSquareSizeUpperBound)
+	require.NoError(f, err)
+	wrappedPFBs, err := s.WrappedPFBs()
+	require.NoError(f, err)
+	for _, wrappedPFB := range wrappedPFBs {
+		f.Add([]byte(wrappedPFB))
+	}
+	for _, tx := range txs {
+		f.Add(tx)
+	}
+	f.Fuzz(func(t *testing.T, tx []byte) {
+		indexWrapper, isIndexWrapper := core.UnmarshalIndexWrapper(tx)
+		if !isIndexWrapper {
+			return
+		}
+		remarshalled, err := core.MarshalIndexWrapper(indexWrapper.Tx, indexWrapper.ShareIndexes...)
+		require.NoError(t, err)
+		indexWrapper2, isIndexWrapper := core.UnmarshalIndexWrapper(remarshalled)
+		require.True(t, isIndexWrapper)
+		require.True(t, proto.Equal(&indexWrapper, &indexWrapper2))
+	})
+}
+
+// generateFuzzSeedTxs generates normalTxCount send txs followed by pfbCount
+// blob txs with up to three blobs each.
+func generateFuzzSeedTxs(rand *tmrand.Rand, signer *user.Signer, normalTxCount, pfbCount int) [][]byte {
+	txs := blobfactory.GenerateManyRandomRawSendTxsSameSigner(rand, signer, normalTxCount)
+	for i := 0; i < pfbCount; i++ {
+		txs = append(txs, blobfactory.RandBlobTxs(signer, rand, 1, i%3+1, rand.Intn(2000)+1)...)
+	}
+	return core.Txs(txs).ToSliceOfBytes()
+}
+
+// wellFormed returns whether the txs are deconstructed from their square
+// byte for byte. This requires every blob tx to be valid and encoded the way
+// `MarshalBlobTx` encodes it.
+func wellFormed(txConfig client.TxConfig, txs [][]byte) bool {
+	for _, tx := range txs {
+		bTx, isBlobTx := pkgblob.UnmarshalBlobTx(tx)
+		if !isBlobTx {
+			continue
+		}
+		if blob.ValidateBlobTx(txConfig, bTx) != nil {
+			return false
+		}
+		remarshalled, err := pkgblob.MarshalBlobTx(bTx.Tx, bTx.Blobs...)
+		if err != nil || !bytes.Equal(tx, remarshalled) {
+			return false
+		}
+	}
+	return true
+}
+
+// encodeFuzzTxs encodes txs as a single fuzz input in which every tx is
+// prefixed by its length.
+func encodeFuzzTxs(txs [][]byte) []byte {
+	var data []byte
+	for _, tx := range txs {
+		data = binary.AppendUvarint(data, uint64(len(tx)))
+		data = append(data, tx...)
+	}
+	return data
+}
+
+// decodeFuzzTxs decodes the txs of a fuzz input that was encoded by
+// encodeFuzzTxs. Trailing bytes that don't form a tx are ignored.
+func decodeFuzzTxs(data []byte) [][]byte {
+	txs := [][]byte{}
+	for len(data) > 0 {
+		size, n := binary.Uvarint(data)
+		if n <= 0 || size > uint64(len(data)-n) {
+			break
+		}
+		txs = append(txs, data[n:n+int(size)])
+		data = data[n+int(size):]
+	}
+	return txs
+}
+
 // contains checks whether subTxs is a subset of allTxs.
 func contains(allTxs [][]byte, subTxs [][]byte) bool {
 	// create a map of allTxs
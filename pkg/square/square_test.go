@@ -226,33 +226,61 @@ func TestSquareShareCommitments(t *testing.T) {
 	signer, err := testnode.NewOfflineSigner()
 	require.NoError(t, err)
 	txs := generateOrderedTxs(signer, rand, numTxs, numTxs, 3, 800)
-	builder, err := square.NewBuilder(appconsts.DefaultSquareSizeUpperBound, appconsts.LatestVersion, txs...)
-	require.NoError(t, err)
+	decoder := encoding.MakeConfig(app.ModuleEncodingRegisters...).TxConfig.TxDecoder()
 
-	dataSquare, err := builder.Export()
-	require.NoError(t, err)
+	for version := uint64(1); version <= appconsts.LatestVersion; version++ {
+		t.Run(fmt.Sprintf("v%d", version), func(t *testing.T) {
+			builder, err := square.NewBuilder(appconsts.SquareSizeUpperBound(version), version, txs...)
+			require.NoError(t, err)
 
-	cacher := inclusion.NewSubtreeCacher(uint64(dataSquare.Size()))
-	eds, err := rsmt2d.ComputeExtendedDataSquare(shares.ToBytes(dataSquare), appconsts.DefaultCodec(), cacher.Constructor)
-	require.NoError(t, err)
-	dah, err := da.NewDataAvailabilityHeader(eds)
-	require.NoError(t, err)
-	decoder := encoding.MakeConfig(app.ModuleEncodingRegisters...).TxConfig.TxDecoder()
+			dataSquare, err := builder.Export()
+			require.NoError(t, err)
 
-	for pfbIndex := 0; pfbIndex < numTxs; pfbIndex++ {
-		wpfb, err := builder.GetWrappedPFB(pfbIndex + numTxs)
-		require.NoError(t, err)
-		tx, err := decoder(wpfb.Tx)
-		require.NoError(t, err)
+			cacher := inclusion.NewSubtreeCacher(uint64(dataSquare.Size()))
+			eds, err := rsmt2d.ComputeExtendedDataSquare(shares.ToBytes(dataSquare), appconsts.Codec(version), cacher.Constructor)
+			require.NoError(t, err)
+			dah, err := da.NewDataAvailabilityHeader(eds)
+			require.NoError(t, err)
 
-		pfb, ok := tx.GetMsgs()[0].(*blobtypes.MsgPayForBlobs)
-		require.True(t, ok)
+			for pfbIndex := 0; pfbIndex < numTxs; pfbIndex++ {
+				wpfb, err := builder.GetWrappedPFB(pfbIndex + numTxs)
+				require.NoError(t, err)
+				tx, err := decoder(wpfb.Tx)
+				require.NoError(t, err)
+
+				pfb, ok := tx.GetMsgs()[0].(*blobtypes.MsgPayForBlobs)
+				require.True(t, ok)
+
+				for blobIndex, shareIndex := range wpfb.ShareIndexes {
+					commitment, err := inclusion.GetCommitment(cacher, dah, int(shareIndex), shares.SparseSharesNeeded(pfb.BlobSizes[blobIndex]), appconsts.SubtreeRootThreshold(version))
+					require.NoError(t, err)
+					require.Equal(t, pfb.ShareCommitments[blobIndex], commitment)
+				}
+			}
+		})
+	}
+}
+
+// TestSquareVersionedConsts checks that squares are built with the constants
+// of their app version.
+func TestSquareVersionedConsts(t *testing.T) {
+	signer, err := testnode.NewOfflineSigner()
+	require.NoError(t, err)
 
-		for blobIndex, shareIndex := range wpfb.ShareIndexes {
-			commitment, err := inclusion.GetCommitment(cacher, dah, int(shareIndex), shares.SparseSharesNeeded(pfb.BlobSizes[blobIndex]), appconsts.DefaultSubtreeRootThreshold)
+	for version := uint64(1); version <= appconsts.LatestVersion; version++ {
+		t.Run(fmt.Sprintf("v%d", version), func(t *testing.T) {
+			maxSquareSize := appconsts.SquareSizeUpperBound(version)
+			builder, err := square.NewBuilder(maxSquareSize, version)
 			require.NoError(t, err)
-			require.Equal(t, pfb.ShareCommitments[blobIndex], commitment)
-		}
+			require.Equal(t, appconsts.SubtreeRootThreshold(version), builder.SubtreeRootThreshold())
+
+			// a blob that needs more shares than the largest square has
+			// doesn't fit
+			blobSize := maxSquareSize * maxSquareSize * appconsts.ContinuationSparseShareContentSize
+			txs := blobfactory.RandBlobTxsWithNamespacesAndSigner(signer, []ns.Namespace{ns.RandomBlobNamespace()}, []int{blobSize})
+			_, err = square.Construct(coretypes.Txs(txs).ToSliceOfBytes(), version, maxSquareSize)
+			require.Error(t, err)
+		})
 	}
 }
 
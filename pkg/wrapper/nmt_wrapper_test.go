@@ -132,8 +132,10 @@ func TestComputeExtendedDataSquare(t *testing.T) {
 	// data for a 4X4 square
 	data := testfactory.GenerateRandNamespacedRawData(squareSize * squareSize)
 
-	_, err := rsmt2d.ComputeExtendedDataSquare(data, appconsts.DefaultCodec(), wrapper.NewConstructor(uint64(squareSize)))
-	assert.NoError(t, err)
+	for version := uint64(1); version <= appconsts.LatestVersion; version++ {
+		_, err := rsmt2d.ComputeExtendedDataSquare(data, appconsts.Codec(version), wrapper.NewConstructor(uint64(squareSize)))
+		assert.NoError(t, err, "v%d", version)
+	}
 }
 
 // generateErasuredData generates random data and then erasure codes it. It
@@ -6,5 +6,10 @@ echo "Running fuzz tests..."
 # fuzzing multiple packages with multiple fuzz tests
 go test -fuzz=FuzzNewInfoByte -fuzztime 1m ./pkg/shares
 go test -fuzz=FuzzValidSequenceLen -fuzztime 1m ./pkg/shares
+go test -fuzz=FuzzParseShares -fuzztime 2m ./pkg/shares
+go test -fuzz=FuzzSplitParse -fuzztime 2m ./pkg/shares
+go test -fuzz=FuzzUnmarshalBlobTx -fuzztime 2m ./pkg/blob
 go test -fuzz=FuzzSquare -fuzztime 5m ./pkg/square
+go test -fuzz=FuzzConstruct -fuzztime 5m ./pkg/square
+go test -fuzz=FuzzUnmarshalIndexWrapper -fuzztime 2m ./pkg/square
 go test -fuzz=FuzzPFBGasEstimation -fuzztime 3m ./x/blob/types
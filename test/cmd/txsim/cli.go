@@ -2,9 +2,11 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/rand"
+	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
@@ -17,6 +19,7 @@ import (
 	"github.com/celestiaorg/celestia-app/test/txsim"
 	"github.com/cosmos/cosmos-sdk/crypto/hd"
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/cobra"
 	flag "github.com/spf13/pflag"
 )
@@ -35,12 +38,25 @@ const (
 // Values for all flags
 var (
 	keyPath, masterAccName, keyMnemonic, grpcEndpoint string
-	blobSizes, blobAmounts                            string
+	blobSizes, blobAmounts, scenarioPath              string
+	metricsAddr, metricsSummary                       string
+	recordPath, replayPath, replayTiming              string
 	seed                                              int64
 	pollTime                                          time.Duration
+	txRate, byteRate                                  float64
+	maxInFlight                                       int
 	send, sendIterations, sendAmount                  int
 	stake, stakeValue, blob                           int
-	useFeegrant, suppressLogs                         bool
+	gov, govVoters, govProposals                      int
+	authz, authzAmount                                int
+	feeAllowance, feeAllowanceSpendLimit              int
+	validator, validatorSelfStake, validatorCycles    int
+	vesting, vestingAmount, vestingAccounts           int
+	unbonding, unbondingStake, unbondings             int
+	adversarial                                       int
+	adversarialAttacks                                []string
+	useFeegrant, suppressLogs, recordBlobData         bool
+	reclaim, revokeFeegrants                          bool
 )
 
 func main() {
@@ -62,7 +78,11 @@ Txsim is a tool for randomized transaction generation on celestia networks. The
 defined sequences; recursive patterns between one or more accounts which will continually submit 
 transactions. You can use flags or environment variables (TXSIM_RPC, TXSIM_GRPC, TXSIM_SEED, 
 TXSIM_POLL, TXSIM_KEYPATH) to configure the client. The keyring provided should have at least one
-well funded account that can act as the master account. The command runs until all sequences error.`,
+well funded account that can act as the master account. Sub-accounts are stored in the keyring
+and reused by subsequent runs with the same keyring, which only top them up as needed. Instead of
+or in addition to the sequence flags, a scenario file can be provided that describes phases of
+weighted sequence mixes. Alternatively, an operation log recorded with --record can be replayed
+with --replay. The command runs until all sequences error.`,
 		Example: "txsim --key-path /path/to/keyring --grpc-endpoint localhost:9090 --seed 1234 --poll-time 1s --blob 5",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var (
@@ -102,13 +122,31 @@ well funded account that can act as the master account. The command runs until a
 				masterAccName = os.Getenv(TxsimMasterAccName)
 			}
 
-			if stake == 0 && send == 0 && blob == 0 {
-				return errors.New("no sequences specified. Use --stake, --send or --blob")
+			hasSequences := stake != 0 || send != 0 || blob != 0 || gov != 0 || authz != 0 || feeAllowance != 0 ||
+				validator != 0 || vesting != 0 || unbonding != 0 || adversarial != 0 || scenarioPath != ""
+			if !hasSequences && replayPath == "" {
+				return errors.New("no sequences specified. Use --stake, --send, --blob, --gov, --authz, --fee-allowance, --validator, --vesting, --unbonding, --adversarial, --scenario or --replay")
+			}
+			if hasSequences && replayPath != "" {
+				return errors.New("--replay only resubmits the recorded operations and can't be combined with sequences or --scenario")
 			}
 
 			// setup the sequences
 			sequences := []txsim.Sequence{}
 
+			var scenario *txsim.Scenario
+			if scenarioPath != "" {
+				scenario, err = txsim.LoadScenario(scenarioPath)
+				if err != nil {
+					return err
+				}
+				scenarioSequences, err := scenario.Sequences()
+				if err != nil {
+					return fmt.Errorf("compiling scenario: %w", err)
+				}
+				sequences = append(sequences, scenarioSequences...)
+			}
+
 			if stake > 0 {
 				sequences = append(sequences, txsim.NewStakeSequence(stakeValue).Clone(stake)...)
 			}
@@ -117,6 +155,41 @@ well funded account that can act as the master account. The command runs until a
 				sequences = append(sequences, txsim.NewSendSequence(2, sendAmount, sendIterations).Clone(send)...)
 			}
 
+			if gov > 0 {
+				sequences = append(sequences, txsim.NewGovSequence(govVoters, govProposals).Clone(gov)...)
+			}
+
+			if authz > 0 {
+				sequences = append(sequences, txsim.NewAuthzSequence(authzAmount).Clone(authz)...)
+			}
+
+			if feeAllowance > 0 {
+				sequences = append(sequences, txsim.NewFeegrantSequence(feeAllowanceSpendLimit).Clone(feeAllowance)...)
+			}
+
+			if validator > 0 {
+				sequences = append(sequences, txsim.NewValidatorSequence(validatorSelfStake, validatorCycles).Clone(validator)...)
+			}
+
+			if vesting > 0 {
+				sequences = append(sequences, txsim.NewVestingSequence(vestingAmount, vestingAccounts).Clone(vesting)...)
+			}
+
+			if unbonding > 0 {
+				sequences = append(sequences, txsim.NewUnbondingSequence(unbondingStake, unbondings).Clone(unbonding)...)
+			}
+
+			if adversarial > 0 {
+				attacks := make([]txsim.Attack, len(adversarialAttacks))
+				for i, attack := range adversarialAttacks {
+					attacks[i] = txsim.Attack(attack)
+					if err := attacks[i].Validate(); err != nil {
+						return err
+					}
+				}
+				sequences = append(sequences, txsim.NewAdversarialSequence(attacks...).Clone(adversarial)...)
+			}
+
 			if blob > 0 {
 				sizes, err := readRange(blobSizes)
 				if err != nil {
@@ -137,6 +210,8 @@ well funded account that can act as the master account. The command runs until a
 					if err != nil {
 						return fmt.Errorf("parsing seed: %w", err)
 					}
+				} else if scenario != nil && scenario.Seed != 0 {
+					seed = scenario.Seed
 				} else {
 					// use a random seed if none is set
 					seed = rand.Int63()
@@ -150,27 +225,83 @@ well funded account that can act as the master account. The command runs until a
 				}
 			}
 
+			// the first endpoint is the primary endpoint of the master account
+			grpcEndpoints := strings.Split(grpcEndpoint, ",")
+			for i := range grpcEndpoints {
+				grpcEndpoints[i] = strings.TrimSpace(grpcEndpoints[i])
+			}
+			grpcEndpoint = grpcEndpoints[0]
+
 			opts := txsim.DefaultOptions().
 				SpecifyMasterAccount(masterAccName).
-				WithSeed(seed)
+				WithSeed(seed).
+				WithEndpoints(grpcEndpoints[1:]...)
 
 			if useFeegrant {
 				opts.UseFeeGrant()
 			}
 
+			if reclaim {
+				opts.ReclaimFunds()
+			}
+
+			if revokeFeegrants {
+				opts.RevokeFeegrants()
+			}
+
+			if txRate > 0 || byteRate > 0 {
+				opts.WithTxRate(txRate).WithByteRate(byteRate).WithMaxInFlight(maxInFlight)
+			}
+
 			if suppressLogs {
 				opts.SuppressLogs()
 			}
 
+			var metrics *txsim.Metrics
+			if metricsAddr != "" || metricsSummary != "" {
+				metrics = txsim.NewMetrics()
+				opts.WithMetrics(metrics)
+			}
+			if metricsAddr != "" {
+				shutdown := serveMetrics(metricsAddr, metrics)
+				defer shutdown()
+			}
+
 			encCfg := encoding.MakeConfig(app.ModuleEncodingRegisters...)
-			err = txsim.Run(
-				cmd.Context(),
-				grpcEndpoint,
-				keys,
-				encCfg,
-				opts,
-				sequences...,
-			)
+			if recordPath != "" {
+				file, err := os.Create(recordPath)
+				if err != nil {
+					return fmt.Errorf("creating operation log: %w", err)
+				}
+				defer file.Close()
+				recorder := txsim.NewRecorder(file, encCfg)
+				if !recordBlobData {
+					recorder.WithoutBlobData()
+				}
+				opts.WithRecorder(recorder)
+			}
+
+			if replayPath != "" {
+				opLog, err := readOperationLog(replayPath)
+				if err != nil {
+					return err
+				}
+				err = txsim.Replay(cmd.Context(), grpcEndpoint, keys, encCfg, opts, opLog, replayTiming)
+			} else {
+				err = txsim.Run(
+					cmd.Context(),
+					grpcEndpoint,
+					keys,
+					encCfg,
+					opts,
+					sequences...,
+				)
+			}
+			if metricsSummary != "" {
+				if err := writeMetricsSummary(metricsSummary, metrics.Summary()); err != nil {
+					return err
+				}
+			}
 			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 				return nil
 			}
@@ -187,7 +318,7 @@ func flags() *flag.FlagSet {
 	flags.StringVar(&keyPath, "key-path", "", "path to the keyring")
 	flags.StringVar(&masterAccName, "master", "", "the account name of the master account. Leaving empty will result in using the account with the most funds.")
 	flags.StringVar(&keyMnemonic, "key-mnemonic", "", "space separated mnemonic for the keyring. The hdpath used is an empty string")
-	flags.StringVar(&grpcEndpoint, "grpc-endpoint", "", "grpc endpoint to a running node")
+	flags.StringVar(&grpcEndpoint, "grpc-endpoint", "", "comma separated grpc endpoints of running nodes. Accounts are spread across the endpoints and fail over if an endpoint goes down")
 	flags.Int64Var(&seed, "seed", 0, "seed for the random number generator")
 	flags.DurationVar(&pollTime, "poll-time", user.DefaultPollTime, "poll time for the transaction client")
 	flags.IntVar(&send, "send", 0, "number of send sequences to run")
@@ -198,11 +329,78 @@ func flags() *flag.FlagSet {
 	flags.IntVar(&blob, "blob", 0, "number of blob sequences to run")
 	flags.StringVar(&blobSizes, "blob-sizes", "100-1000", "range of blob sizes to send")
 	flags.StringVar(&blobAmounts, "blob-amounts", "1", "range of blobs to send per PFB in a sequence")
+	flags.IntVar(&gov, "gov", 0, "number of gov sequences to run")
+	flags.IntVar(&govVoters, "gov-voters", 3, "number of accounts voting on each proposal of a gov sequence")
+	flags.IntVar(&govProposals, "gov-proposals", 10, "number of proposals submitted per gov sequence")
+	flags.IntVar(&authz, "authz", 0, "number of authz sequences to run")
+	flags.IntVar(&authzAmount, "authz-amount", 100, "amount sent by the grantee on behalf of the granter in an authz sequence")
+	flags.IntVar(&feeAllowance, "fee-allowance", 0, "number of feegrant sequences to run in which a granter pays the fees of a grantee")
+	flags.IntVar(&feeAllowanceSpendLimit, "fee-allowance-spend-limit", 1e8, "spend limit of the fee allowance of a feegrant sequence")
+	flags.IntVar(&validator, "validator", 0, "number of validator sequences to run that create, jail and unjail a validator")
+	flags.IntVar(&validatorSelfStake, "validator-self-stake", 1_000_000, "self stake of the validator of a validator sequence")
+	flags.IntVar(&validatorCycles, "validator-cycles", 5, "number of jail and unjail cycles per validator sequence")
+	flags.IntVar(&vesting, "vesting", 0, "number of vesting sequences to run")
+	flags.IntVar(&vestingAmount, "vesting-amount", 1000, "amount vested by each account created by a vesting sequence")
+	flags.IntVar(&vestingAccounts, "vesting-accounts", 100, "number of vesting accounts created per vesting sequence")
+	flags.IntVar(&unbonding, "unbonding", 0, "number of unbonding sequences to run")
+	flags.IntVar(&unbondingStake, "unbonding-stake", 1000, "amount of initial stake per unbonding sequence")
+	flags.IntVar(&unbondings, "unbondings", 5, "number of undelegations per unbonding sequence")
+	flags.IntVar(&adversarial, "adversarial", 0, "number of adversarial sequences to run that submit invalid transactions which must be rejected")
+	flags.StringSliceVar(&adversarialAttacks, "adversarial-attacks", nil, "comma separated attacks of the adversarial sequences (mismatched_commitment, no_blobs, oversized_blob, nonce_gap, duplicate, low_gas_price). Leaving empty uses all attacks")
+	flags.StringVar(&scenarioPath, "scenario", "", "path to a YAML scenario describing phases of sequences to run (see test/txsim/scenarios)")
+	flags.StringVar(&metricsAddr, "metrics-addr", "", "address to serve prometheus metrics on (e.g. :26661). Leaving empty disables the metrics server")
+	flags.StringVar(&metricsSummary, "metrics-summary", "", "file to write a JSON summary of the metrics to at the end of the run")
+	flags.Float64Var(&txRate, "tx-rate", 0, "submit transactions at this rate per second regardless of confirmations (open loop). 0 waits for each transaction of a sequence to be committed")
+	flags.Float64Var(&byteRate, "byte-rate", 0, "submit blobs at this rate in bytes per second regardless of confirmations (open loop). Can be combined with --tx-rate")
+	flags.IntVar(&maxInFlight, "max-in-flight", txsim.DefaultMaxInFlight, "maximum number of unconfirmed transactions in open loop mode")
+	flags.StringVar(&recordPath, "record", "", "file to record all submitted operations to so that they can be replayed with --replay")
+	flags.BoolVar(&recordBlobData, "record-blob-data", true, "record the data of blobs. If false, only their hashes and sizes are recorded and random data of the same size is replayed")
+	flags.StringVar(&replayPath, "replay", "", "operation log recorded with --record to resubmit instead of running sequences. The seed and feegrant setting are taken from the log")
+	flags.StringVar(&replayTiming, "replay-timing", txsim.ReplayRecordedTiming, "timing of a replay: 'recorded' submits operations at their recorded offsets, 'fast' submits them as fast as possible")
 	flags.BoolVar(&useFeegrant, "feegrant", false, "use the feegrant module to pay for fees")
+	flags.BoolVar(&reclaim, "reclaim", false, "send the remaining balances of the sub-accounts back to the master account on shutdown")
+	flags.BoolVar(&revokeFeegrants, "revoke-feegrants", false, "revoke the fee grants of the sub-accounts on shutdown")
 	flags.BoolVar(&suppressLogs, "suppressLogs", false, "disable logging")
 	return flags
 }
 
+// serveMetrics serves the prometheus metrics on addr. The returned function
+// stops the server.
+func serveMetrics(addr string, metrics *txsim.Metrics) func() {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(metrics.Registry(), promhttp.HandlerOpts{}))
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(os.Stderr, "metrics server failed: %v\n", err)
+		}
+	}()
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(ctx)
+	}
+}
+
+// writeMetricsSummary writes the summary as indented JSON to path.
+func writeMetricsSummary(path string, summary txsim.MetricsSummary) error {
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// readOperationLog reads the operation log at path.
+func readOperationLog(path string) (*txsim.OperationLog, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening operation log: %w", err)
+	}
+	defer file.Close()
+	return txsim.ReadOperationLog(file)
+}
+
 // readRange takes a string expected to be of the form "1-10" and returns the corresponding Range.
 // If only one number is set i.e. "5", the range returned is {5, 5}.
 func readRange(r string) (txsim.Range, error) {
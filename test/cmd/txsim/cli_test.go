@@ -34,6 +34,46 @@ func TestTxsimCommandFlags(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestTxsimCommandModuleFlags(t *testing.T) {
+	_, _, grpcAddr := setup(t)
+	cmd := command()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cmd.SetArgs([]string{
+		"--key-mnemonic", testfactory.TestAccMnemo,
+		"--grpc-endpoint", grpcAddr,
+		"--authz", "1",
+		"--fee-allowance", "1",
+		"--vesting", "1",
+		"--gov", "1",
+		"--gov-proposals", "1",
+		"--validator", "1",
+		"--validator-self-stake", "100000000",
+		"--validator-cycles", "1",
+		"--unbonding", "1",
+		"--adversarial", "1",
+		"--adversarial-attacks", "no_blobs,nonce_gap",
+		"--seed", "1234",
+	})
+	err := cmd.ExecuteContext(ctx)
+	require.NoError(t, err)
+}
+
+func TestTxsimCommandReplayWithSequences(t *testing.T) {
+	for _, flags := range [][]string{{"--blob", "1"}, {"--scenario", "scenario.yaml"}} {
+		cmd := command()
+		cmd.SetArgs(append([]string{
+			"--key-mnemonic", testfactory.TestAccMnemo,
+			"--grpc-endpoint", "localhost:9090",
+			"--replay", "operations.jsonl",
+		}, flags...))
+		err := cmd.ExecuteContext(context.Background())
+		require.ErrorContains(t, err, "can't be combined", flags[0])
+	}
+}
+
 func TestTxsimCommandEnvVar(t *testing.T) {
 	_, _, grpcAddr := setup(t)
 	cmd := command()
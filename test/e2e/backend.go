@@ -0,0 +1,110 @@
+package e2e
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// BackendEnv selects the backend that runs the nodes of a testnet. It is
+	// either "knuu", the default, or "local".
+	BackendEnv = "E2E_BACKEND"
+	// BinariesEnv lists the celestia-appd binaries used by the local backend
+	// as comma separated version=path pairs, for example
+	// "latest=./build/celestia-appd,v1.0.0=/tmp/celestia-appd-v1.0.0".
+	BinariesEnv = "E2E_BINARIES"
+)
+
+// Backend creates the instances that run the nodes of a Testnet.
+type Backend interface {
+	// NewInstance creates an instance named name that runs the version of
+	// celestia-appd.
+	NewInstance(name, version string) (Instance, error)
+}
+
+// Instance runs a single celestia-appd node.
+type Instance interface {
+	// Ports returns the ports that the node listens on. They are written to
+	// the configuration of the node.
+	Ports() Ports
+	// AddFile adds the local file src at dest, a path relative to the home
+	// directory of the node. Files must be added before the instance is
+	// started for the first time.
+	AddFile(src, dest string) error
+	// SetArgs sets additional arguments of the start command of the node. It
+	// must be called before the instance is started for the first time.
+	SetArgs(args ...string) error
+	// P2PAddress returns the host and port at which other nodes reach the
+	// node.
+	P2PAddress() (string, error)
+	// Start starts the node, or restarts it after it has been stopped.
+	Start() error
+	// RPCAddress returns the host and port at which the test reaches the RPC
+	// of a started node.
+	RPCAddress() string
+	// GRPCAddress returns the host and port at which the test reaches the
+	// gRPC server of a started node.
+	GRPCAddress() string
+	// Stop stops the node while keeping its files.
+	Stop() error
+	// Destroy stops the node and removes all of its resources.
+	Destroy() error
+}
+
+// LogReader is implemented by instances whose logs can be read by the test.
+type LogReader interface {
+	// Logs returns the output of the node across all of its starts.
+	Logs() ([]byte, error)
+}
+
+// Cleaner is implemented by backends that hold resources beyond those of their
+// instances.
+type Cleaner interface {
+	// Cleanup releases the resources of the backend once all instances have
+	// been destroyed.
+	Cleanup() error
+}
+
+// Ports are the ports that a node listens on.
+type Ports struct {
+	RPC  int
+	P2P  int
+	GRPC int
+}
+
+// BackendFromEnv returns the backend selected by the BackendEnv environment
+// variable. The knuu backend is used by default.
+func BackendFromEnv(name string) (Backend, error) {
+	switch backend := os.Getenv(BackendEnv); backend {
+	case "", "knuu":
+		return NewKnuuBackend(name)
+	case "local":
+		binaries, err := parseBinaries(os.Getenv(BinariesEnv))
+		if err != nil {
+			return nil, err
+		}
+		return NewTempLocalBackend(name, binaries)
+	default:
+		return nil, fmt.Errorf("unknown backend %q", backend)
+	}
+}
+
+// parseBinaries parses comma separated version=path pairs.
+func parseBinaries(s string) (map[string]string, error) {
+	binaries := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		if pair == "" {
+			continue
+		}
+		version, path, ok := strings.Cut(pair, "=")
+		if !ok || version == "" || path == "" {
+			return nil, fmt.Errorf("invalid binary %q: expected version=path", pair)
+		}
+		binaries[version] = path
+	}
+	if len(binaries) == 0 {
+		return nil, fmt.Errorf("the local backend requires binaries to be set with %s", BinariesEnv)
+	}
+	return binaries, nil
+}
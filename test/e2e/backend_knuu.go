@@ -0,0 +1,163 @@
+package e2e
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/celestiaorg/knuu/pkg/knuu"
+)
+
+const (
+	rpcPort       = 26657
+	p2pPort       = 26656
+	grpcPort      = 9090
+	dockerSrcURL  = "ghcr.io/celestiaorg/celestia-app"
+	remoteRootDir = "/home/celestia/.celestia-app"
+)
+
+// KnuuBackend runs the nodes as containers in a Kubernetes cluster using
+// knuu. It relies on Docker and a kubeconfig to access the cluster.
+type KnuuBackend struct{}
+
+var _ Backend = &KnuuBackend{}
+
+// NewKnuuBackend initializes knuu with an identifier derived from the name.
+func NewKnuuBackend(name string) (*KnuuBackend, error) {
+	identifier := fmt.Sprintf("%s_%s", name, time.Now().Format("20060102_150405"))
+	if err := knuu.InitializeWithIdentifier(identifier); err != nil {
+		return nil, err
+	}
+	return &KnuuBackend{}, nil
+}
+
+// NewInstance creates a knuu instance running the docker image of the
+// version.
+func (b *KnuuBackend) NewInstance(name, version string) (Instance, error) {
+	instance, err := knuu.NewInstance(name)
+	if err != nil {
+		return nil, err
+	}
+	err = instance.SetImage(fmt.Sprintf("%s:%s", dockerSrcURL, version))
+	if err != nil {
+		return nil, err
+	}
+	if err := instance.AddPortTCP(rpcPort); err != nil {
+		return nil, err
+	}
+	if err := instance.AddPortTCP(p2pPort); err != nil {
+		return nil, err
+	}
+	if err := instance.AddPortTCP(grpcPort); err != nil {
+		return nil, err
+	}
+	err = instance.SetMemory("200Mi", "200Mi")
+	if err != nil {
+		return nil, err
+	}
+	err = instance.SetCPU("300m")
+	if err != nil {
+		return nil, err
+	}
+	err = instance.AddVolumeWithOwner(remoteRootDir, "1Gi", 10001)
+	if err != nil {
+		return nil, err
+	}
+	args := []string{"start", fmt.Sprintf("--home=%s", remoteRootDir), "--rpc.laddr=tcp://0.0.0.0:26657"}
+	err = instance.SetArgs(args...)
+	if err != nil {
+		return nil, err
+	}
+	return &knuuInstance{instance: instance, args: args, dirs: make(map[string]bool)}, nil
+}
+
+type knuuInstance struct {
+	instance *knuu.Instance
+	// args are the default arguments of the instance.
+	args []string
+	// dirs are the directories that have been created in the image.
+	dirs      map[string]bool
+	committed bool
+
+	rpcProxyPort  int
+	grpcProxyPort int
+}
+
+var _ Instance = &knuuInstance{}
+
+func (i *knuuInstance) Ports() Ports {
+	return Ports{RPC: rpcPort, P2P: p2pPort, GRPC: grpcPort}
+}
+
+func (i *knuuInstance) AddFile(src, dest string) error {
+	dest = filepath.Join(remoteRootDir, dest)
+	dir := filepath.Dir(dest)
+	if !i.dirs[dir] {
+		_, err := i.instance.ExecuteCommand(fmt.Sprintf("mkdir -p %s", dir))
+		if err != nil {
+			return fmt.Errorf("creating directory %s: %w", dir, err)
+		}
+		i.dirs[dir] = true
+	}
+	return i.instance.AddFile(src, dest, "10001:10001")
+}
+
+func (i *knuuInstance) SetArgs(args ...string) error {
+	return i.instance.SetArgs(append(append([]string{}, i.args...), args...)...)
+}
+
+func (i *knuuInstance) P2PAddress() (string, error) {
+	ip, err := i.instance.GetIP()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%v:%d", ip, p2pPort), nil
+}
+
+// Start commits the image on the first start and forwards the RPC and gRPC
+// ports to local ports.
+func (i *knuuInstance) Start() error {
+	if !i.committed {
+		if err := i.instance.Commit(); err != nil {
+			return err
+		}
+		i.committed = true
+	}
+
+	if err := i.instance.Start(); err != nil {
+		return err
+	}
+
+	if err := i.instance.WaitInstanceIsRunning(); err != nil {
+		return err
+	}
+
+	rpcProxyPort, err := i.instance.PortForwardTCP(rpcPort)
+	if err != nil {
+		return fmt.Errorf("forwarding port %d: %w", rpcPort, err)
+	}
+
+	grpcProxyPort, err := i.instance.PortForwardTCP(grpcPort)
+	if err != nil {
+		return fmt.Errorf("forwarding port %d: %w", grpcPort, err)
+	}
+	i.rpcProxyPort = rpcProxyPort
+	i.grpcProxyPort = grpcProxyPort
+	return nil
+}
+
+func (i *knuuInstance) RPCAddress() string {
+	return fmt.Sprintf("127.0.0.1:%d", i.rpcProxyPort)
+}
+
+func (i *knuuInstance) GRPCAddress() string {
+	return fmt.Sprintf("127.0.0.1:%d", i.grpcProxyPort)
+}
+
+func (i *knuuInstance) Stop() error {
+	return i.instance.Stop()
+}
+
+func (i *knuuInstance) Destroy() error {
+	return i.instance.Destroy()
+}
@@ -0,0 +1,214 @@
+package e2e
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/celestiaorg/celestia-app/test/util/testnode"
+)
+
+// localStopTimeout is how long a node is given to shut down gracefully before
+// it is killed.
+const localStopTimeout = 10 * time.Second
+
+// LocalBackend runs the nodes as celestia-appd processes on the local
+// machine. Every node has its own home directory and listens on its own open
+// ports on loopback. Different versions are run by different binaries.
+type LocalBackend struct {
+	dir      string
+	binaries map[string]string
+	// removeDir is set if the backend created dir and removes it on cleanup.
+	removeDir bool
+}
+
+var (
+	_ Backend = &LocalBackend{}
+	_ Cleaner = &LocalBackend{}
+)
+
+// NewLocalBackend creates a backend that stores the home directories and logs
+// of the nodes in dir. binaries maps every version to the path of its
+// celestia-appd binary.
+func NewLocalBackend(dir string, binaries map[string]string) *LocalBackend {
+	return &LocalBackend{dir: dir, binaries: binaries}
+}
+
+// NewTempLocalBackend creates a backend like NewLocalBackend that stores the
+// home directories and logs of the nodes in a new temporary directory named
+// after name. The directory is removed by Cleanup.
+func NewTempLocalBackend(name string, binaries map[string]string) (*LocalBackend, error) {
+	dir, err := os.MkdirTemp("", strings.ReplaceAll(name, "/", "_")+"_*")
+	if err != nil {
+		return nil, err
+	}
+	return &LocalBackend{dir: dir, binaries: binaries, removeDir: true}, nil
+}
+
+// Cleanup removes the directory of the backend if the backend created it.
+func (b *LocalBackend) Cleanup() error {
+	if !b.removeDir {
+		return nil
+	}
+	return os.RemoveAll(b.dir)
+}
+
+// AddBinary sets the path of the celestia-appd binary of the version.
+func (b *LocalBackend) AddBinary(version, path string) {
+	b.binaries[version] = path
+}
+
+func (b *LocalBackend) NewInstance(name, version string) (Instance, error) {
+	binary, ok := b.binaries[version]
+	if !ok {
+		return nil, fmt.Errorf("no binary for version %s", version)
+	}
+	return &localInstance{
+		binary:  binary,
+		home:    filepath.Join(b.dir, name),
+		logPath: filepath.Join(b.dir, name+".log"),
+		ports: Ports{
+			RPC:  testnode.GetFreePort(),
+			P2P:  testnode.GetFreePort(),
+			GRPC: testnode.GetFreePort(),
+		},
+	}, nil
+}
+
+type localInstance struct {
+	binary  string
+	home    string
+	logPath string
+	ports   Ports
+	args    []string
+
+	mtx     sync.Mutex
+	cmd     *exec.Cmd
+	logFile *os.File
+	// done is closed once the process has exited
+	done chan struct{}
+}
+
+var (
+	_ Instance  = &localInstance{}
+	_ LogReader = &localInstance{}
+)
+
+func (i *localInstance) Ports() Ports {
+	return i.ports
+}
+
+func (i *localInstance) AddFile(src, dest string) error {
+	dest = filepath.Join(i.home, dest)
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func (i *localInstance) SetArgs(args ...string) error {
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	i.args = args
+	return nil
+}
+
+func (i *localInstance) P2PAddress() (string, error) {
+	return fmt.Sprintf("127.0.0.1:%d", i.ports.P2P), nil
+}
+
+// Start starts the process of the node. Its output is appended to the log
+// file of the node.
+func (i *localInstance) Start() error {
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	if i.cmd != nil {
+		return errors.New("node is already running")
+	}
+
+	logFile, err := os.OpenFile(i.logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	args := append([]string{"start", fmt.Sprintf("--home=%s", i.home)}, i.args...)
+	cmd := exec.Command(i.binary, args...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	if err := cmd.Start(); err != nil {
+		logFile.Close()
+		return fmt.Errorf("starting %s: %w", i.binary, err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(done)
+	}()
+	i.cmd, i.logFile, i.done = cmd, logFile, done
+	return nil
+}
+
+func (i *localInstance) RPCAddress() string {
+	return fmt.Sprintf("127.0.0.1:%d", i.ports.RPC)
+}
+
+func (i *localInstance) GRPCAddress() string {
+	return fmt.Sprintf("127.0.0.1:%d", i.ports.GRPC)
+}
+
+// Stop interrupts the process of the node and kills it if it doesn't exit
+// within the localStopTimeout. Stopping a stopped node is a no-op.
+func (i *localInstance) Stop() error {
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	if i.cmd == nil {
+		return nil
+	}
+
+	var err error
+	if signalErr := i.cmd.Process.Signal(os.Interrupt); signalErr != nil && !errors.Is(signalErr, os.ErrProcessDone) {
+		err = signalErr
+	}
+	select {
+	case <-i.done:
+	case <-time.After(localStopTimeout):
+		if killErr := i.cmd.Process.Kill(); killErr != nil && !errors.Is(killErr, os.ErrProcessDone) {
+			err = killErr
+		}
+		<-i.done
+	}
+	i.cmd = nil
+	return errors.Join(err, i.logFile.Close())
+}
+
+func (i *localInstance) Logs() ([]byte, error) {
+	return os.ReadFile(i.logPath)
+}
+
+// Destroy stops the node and removes its home directory. The log file is
+// kept.
+func (i *localInstance) Destroy() error {
+	if err := i.Stop(); err != nil {
+		return err
+	}
+	return os.RemoveAll(i.home)
+}
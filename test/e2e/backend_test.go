@@ -0,0 +1,21 @@
+package e2e
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBinaries(t *testing.T) {
+	binaries, err := parseBinaries("latest=./build/celestia-appd,v1.0.0=/tmp/celestia-appd")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"latest": "./build/celestia-appd",
+		"v1.0.0": "/tmp/celestia-appd",
+	}, binaries)
+
+	for _, s := range []string{"", "latest", "=./build/celestia-appd", "latest="} {
+		_, err := parseBinaries(s)
+		require.Error(t, err, s)
+	}
+}
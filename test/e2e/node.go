@@ -5,7 +5,7 @@ import (
 	"os"
 	"path/filepath"
 
-	"github.com/celestiaorg/knuu/pkg/knuu"
+	"github.com/celestiaorg/celestia-app/x/upgrade"
 	serverconfig "github.com/cosmos/cosmos-sdk/server/config"
 	"github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/crypto"
@@ -16,13 +16,9 @@ import (
 )
 
 const (
-	rpcPort       = 26657
-	p2pPort       = 26656
-	grpcPort      = 9090
-	dockerSrcURL  = "ghcr.io/celestiaorg/celestia-app"
 	secp256k1Type = "secp256k1"
 	ed25519Type   = "ed25519"
-	remoteRootDir = "/home/celestia/.celestia-app"
+	chainID       = "testnet"
 )
 
 type Node struct {
@@ -34,48 +30,20 @@ type Node struct {
 	NetworkKey     crypto.PrivKey
 	AccountKey     crypto.PrivKey
 	SelfDelegation int64
-	Instance       *knuu.Instance
-
-	rpcProxyPort  int
-	grpcProxyPort int
+	// UpgradeSchedule is the upgrade schedule that the node is configured
+	// with, if any.
+	UpgradeSchedule upgrade.Schedule
+	Instance        Instance
 }
 
 func NewNode(
+	backend Backend,
 	name, version string,
 	startHeight, selfDelegation int64,
 	peers []string,
 	signerKey, networkKey, accountKey crypto.PrivKey,
 ) (*Node, error) {
-	instance, err := knuu.NewInstance(name)
-	if err != nil {
-		return nil, err
-	}
-	err = instance.SetImage(fmt.Sprintf("%s:%s", dockerSrcURL, version))
-	if err != nil {
-		return nil, err
-	}
-	if err := instance.AddPortTCP(rpcPort); err != nil {
-		return nil, err
-	}
-	if err := instance.AddPortTCP(p2pPort); err != nil {
-		return nil, err
-	}
-	if err := instance.AddPortTCP(grpcPort); err != nil {
-		return nil, err
-	}
-	err = instance.SetMemory("200Mi", "200Mi")
-	if err != nil {
-		return nil, err
-	}
-	err = instance.SetCPU("300m")
-	if err != nil {
-		return nil, err
-	}
-	err = instance.AddVolumeWithOwner(remoteRootDir, "1Gi", 10001)
-	if err != nil {
-		return nil, err
-	}
-	err = instance.SetArgs("start", fmt.Sprintf("--home=%s", remoteRootDir), "--rpc.laddr=tcp://0.0.0.0:26657")
+	instance, err := backend.NewInstance(name, version)
 	if err != nil {
 		return nil, err
 	}
@@ -156,62 +124,31 @@ func (n *Node) Init(genesis types.GenesisDoc, peers []string) error {
 		return fmt.Errorf("writing address book: %w", err)
 	}
 
-	_, err = n.Instance.ExecuteCommand(fmt.Sprintf("mkdir -p %s/config", remoteRootDir))
-	if err != nil {
-		return fmt.Errorf("creating config directory: %w", err)
-	}
-	_, err = n.Instance.ExecuteCommand(fmt.Sprintf("mkdir -p %s/data", remoteRootDir))
-	if err != nil {
-		return fmt.Errorf("creating data directory: %w", err)
-	}
-
-	err = n.Instance.AddFile(configFilePath, filepath.Join(remoteRootDir, "config", "config.toml"), "10001:10001")
-	if err != nil {
-		return fmt.Errorf("adding config file: %w", err)
-	}
-
-	err = n.Instance.AddFile(genesisFilePath, filepath.Join(remoteRootDir, "config", "genesis.json"), "10001:10001")
-	if err != nil {
-		return fmt.Errorf("adding genesis file: %w", err)
-	}
-
-	err = n.Instance.AddFile(appConfigFilePath, filepath.Join(remoteRootDir, "config", "app.toml"), "10001:10001")
-	if err != nil {
-		return fmt.Errorf("adding app config file: %w", err)
+	files := map[string]string{
+		configFilePath:    filepath.Join("config", "config.toml"),
+		genesisFilePath:   filepath.Join("config", "genesis.json"),
+		appConfigFilePath: filepath.Join("config", "app.toml"),
+		pvKeyPath:         filepath.Join("config", "priv_validator_key.json"),
+		pvStatePath:       filepath.Join("data", "priv_validator_state.json"),
+		nodeKeyFilePath:   filepath.Join("config", "node_key.json"),
+		addrBookFile:      filepath.Join("config", "addrbook.json"),
 	}
-
-	err = n.Instance.AddFile(pvKeyPath, filepath.Join(remoteRootDir, "config", "priv_validator_key.json"), "10001:10001")
-	if err != nil {
-		return fmt.Errorf("adding priv_validator_key file: %w", err)
-	}
-
-	err = n.Instance.AddFile(pvStatePath, filepath.Join(remoteRootDir, "data", "priv_validator_state.json"), "10001:10001")
-	if err != nil {
-		return fmt.Errorf("adding priv_validator_state file: %w", err)
-	}
-
-	err = n.Instance.AddFile(nodeKeyFilePath, filepath.Join(remoteRootDir, "config", "node_key.json"), "10001:10001")
-	if err != nil {
-		return fmt.Errorf("adding node_key file: %w", err)
-	}
-
-	err = n.Instance.AddFile(addrBookFile, filepath.Join(remoteRootDir, "config", "addrbook.json"), "10001:10001")
-	if err != nil {
-		return fmt.Errorf("adding addrbook file: %w", err)
+	for src, dest := range files {
+		if err := n.Instance.AddFile(src, dest); err != nil {
+			return fmt.Errorf("adding %s: %w", dest, err)
+		}
 	}
-
-	return n.Instance.Commit()
+	return nil
 }
 
 // AddressP2P returns a P2P endpoint address for the node. This is used for
 // populating the address book. This will look something like:
 // 3314051954fc072a0678ec0cbac690ad8676ab98@61.108.66.220:26656
 func (n Node) AddressP2P(withID bool) string {
-	ip, err := n.Instance.GetIP()
+	addr, err := n.Instance.P2PAddress()
 	if err != nil {
 		panic(err)
 	}
-	addr := fmt.Sprintf("%v:%d", ip, p2pPort)
 	if withID {
 		addr = fmt.Sprintf("%x@%v", n.NetworkKey.PubKey().Address().Bytes(), addr)
 	}
@@ -219,15 +156,15 @@ func (n Node) AddressP2P(withID bool) string {
 }
 
 // AddressRPC returns an RPC endpoint address for the node.
-// This returns the local proxy port that can be used to communicate with the node
+// This returns the local address that can be used to communicate with the node
 func (n Node) AddressRPC() string {
-	return fmt.Sprintf("http://127.0.0.1:%d", n.rpcProxyPort)
+	return fmt.Sprintf("http://%s", n.Instance.RPCAddress())
 }
 
 // AddressGRPC returns a GRPC endpoint address for the node. This returns the
-// local proxy port that can be used to communicate with the node
+// local address that can be used to communicate with the node
 func (n Node) AddressGRPC() string {
-	return fmt.Sprintf("127.0.0.1:%d", n.grpcProxyPort)
+	return n.Instance.GRPCAddress()
 }
 
 func (n Node) IsValidator() bool {
@@ -239,24 +176,10 @@ func (n Node) Client() (*http.HTTP, error) {
 }
 
 func (n *Node) Start() error {
-	if err := n.Instance.Start(); err != nil {
-		return err
-	}
-
-	if err := n.Instance.WaitInstanceIsRunning(); err != nil {
-		return err
-	}
-
-	rpcProxyPort, err := n.Instance.PortForwardTCP(rpcPort)
-	if err != nil {
-		return fmt.Errorf("forwarding port %d: %w", rpcPort, err)
-	}
+	return n.Instance.Start()
+}
 
-	grpcProxyPort, err := n.Instance.PortForwardTCP(grpcPort)
-	if err != nil {
-		return fmt.Errorf("forwarding port %d: %w", grpcPort, err)
-	}
-	n.rpcProxyPort = rpcProxyPort
-	n.grpcProxyPort = grpcProxyPort
-	return nil
+// Stop stops the node while keeping its state so that it can be restarted.
+func (n *Node) Stop() error {
+	return n.Instance.Stop()
 }
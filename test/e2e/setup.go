@@ -124,7 +124,7 @@ func MakeGenesis(nodes []*Node, accounts []*Account) (types.GenesisDoc, error) {
 
 	// Validator set and app hash are set in InitChain
 	return types.GenesisDoc{
-		ChainID:         "testnet",
+		ChainID:         chainID,
 		GenesisTime:     time.Now().UTC(),
 		ConsensusParams: app.DefaultConsensusParams(),
 		AppState:        appState,
@@ -133,9 +133,14 @@ func MakeGenesis(nodes []*Node, accounts []*Account) (types.GenesisDoc, error) {
 }
 
 func MakeConfig(node *Node) (*config.Config, error) {
+	ports := node.Instance.Ports()
 	cfg := config.DefaultConfig()
 	cfg.Moniker = node.Name
-	cfg.RPC.ListenAddress = "tcp://0.0.0.0:26657"
+	cfg.RPC.ListenAddress = fmt.Sprintf("tcp://0.0.0.0:%d", ports.RPC)
+	cfg.P2P.ListenAddress = fmt.Sprintf("tcp://0.0.0.0:%d", ports.P2P)
+	// nodes of the local backend share the loopback address
+	cfg.P2P.AllowDuplicateIP = true
+	cfg.P2P.AddrBookStrict = false
 	cfg.P2P.ExternalAddress = fmt.Sprintf("tcp://%v", node.AddressP2P(false))
 	cfg.P2P.PersistentPeers = strings.Join(node.InitialPeers, ",")
 	cfg.Consensus.TimeoutPropose = time.Second
@@ -159,8 +164,12 @@ func WriteAddressBook(peers []string, file string) error {
 	return nil
 }
 
-func MakeAppConfig(_ *Node) (*serverconfig.Config, error) {
+func MakeAppConfig(node *Node) (*serverconfig.Config, error) {
 	srvCfg := serverconfig.DefaultConfig()
 	srvCfg.MinGasPrices = fmt.Sprintf("0.001%s", app.BondDenom)
+	srvCfg.GRPC.Address = fmt.Sprintf("0.0.0.0:%d", node.Instance.Ports().GRPC)
+	// gRPC-web listens on a fixed port which nodes of the local backend
+	// would share
+	srvCfg.GRPCWeb.Enable = false
 	return srvCfg, srvCfg.ValidateBasic()
 }
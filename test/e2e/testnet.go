@@ -7,7 +7,6 @@ import (
 
 	"github.com/celestiaorg/celestia-app/app"
 	"github.com/celestiaorg/celestia-app/app/encoding"
-	"github.com/celestiaorg/knuu/pkg/knuu"
 	"github.com/cosmos/cosmos-sdk/crypto/hd"
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
 	"github.com/rs/zerolog/log"
@@ -15,30 +14,38 @@ import (
 
 type Testnet struct {
 	seed            int64
+	backend         Backend
 	nodes           []*Node
 	genesisAccounts []*Account
 	keygen          *keyGenerator
 }
 
+// New creates a testnet whose nodes run on the backend selected by the
+// E2E_BACKEND environment variable. By default nodes run on knuu.
 func New(name string, seed int64) (*Testnet, error) {
-	identifier := fmt.Sprintf("%s_%s", name, time.Now().Format("20060102_150405"))
-	if err := knuu.InitializeWithIdentifier(identifier); err != nil {
+	backend, err := BackendFromEnv(name)
+	if err != nil {
 		return nil, err
 	}
+	return NewWithBackend(seed, backend), nil
+}
 
+// NewWithBackend creates a testnet whose nodes run on the backend.
+func NewWithBackend(seed int64, backend Backend) *Testnet {
 	return &Testnet{
 		seed:            seed,
+		backend:         backend,
 		nodes:           make([]*Node, 0),
 		genesisAccounts: make([]*Account, 0),
 		keygen:          newKeyGenerator(seed),
-	}, nil
+	}
 }
 
 func (t *Testnet) CreateGenesisNode(version string, selfDelegation int64) error {
 	signerKey := t.keygen.Generate(ed25519Type)
 	networkKey := t.keygen.Generate(ed25519Type)
 	accountKey := t.keygen.Generate(secp256k1Type)
-	node, err := NewNode(fmt.Sprintf("val%d", len(t.nodes)), version, 0, selfDelegation, nil, signerKey, networkKey, accountKey)
+	node, err := NewNode(t.backend, fmt.Sprintf("val%d", len(t.nodes)), version, 0, selfDelegation, nil, signerKey, networkKey, accountKey)
 	if err != nil {
 		return err
 	}
@@ -59,7 +66,7 @@ func (t *Testnet) CreateNode(version string, startHeight int64) error {
 	signerKey := t.keygen.Generate(ed25519Type)
 	networkKey := t.keygen.Generate(ed25519Type)
 	accountKey := t.keygen.Generate(secp256k1Type)
-	node, err := NewNode(fmt.Sprintf("val%d", len(t.nodes)), version, startHeight, 0, nil, signerKey, networkKey, accountKey)
+	node, err := NewNode(t.backend, fmt.Sprintf("val%d", len(t.nodes)), version, startHeight, 0, nil, signerKey, networkKey, accountKey)
 	if err != nil {
 		return err
 	}
@@ -149,11 +156,12 @@ func (t *Testnet) Start() error {
 			return fmt.Errorf("failed to initialized node %s: %w", node.Name, err)
 		}
 		for i := 0; i < 10; i++ {
+			// the RPC of a node that was just started may not be serving yet
 			resp, err := client.Status(context.Background())
-			if err != nil {
+			if err != nil && i == 9 {
 				return fmt.Errorf("node %s status response: %w", node.Name, err)
 			}
-			if resp.SyncInfo.LatestBlockHeight > 0 {
+			if err == nil && resp.SyncInfo.LatestBlockHeight > 0 {
 				break
 			}
 			if i == 9 {
@@ -172,8 +180,18 @@ func (t *Testnet) Cleanup() {
 			log.Err(err).Msg(fmt.Sprintf("node %s failed to cleanup", node.Name))
 		}
 	}
+	if cleaner, ok := t.backend.(Cleaner); ok {
+		if err := cleaner.Cleanup(); err != nil {
+			log.Err(err).Msg("backend failed to cleanup")
+		}
+	}
 }
 
 func (t *Testnet) Node(i int) *Node {
 	return t.nodes[i]
 }
+
+// Nodes returns all nodes of the testnet in the order they were created.
+func (t *Testnet) Nodes() []*Node {
+	return t.nodes
+}
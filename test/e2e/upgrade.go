@@ -0,0 +1,226 @@
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/celestiaorg/celestia-app/cmd/celestia-appd/cmd"
+	"github.com/celestiaorg/celestia-app/x/upgrade"
+	"github.com/tendermint/tendermint/types"
+)
+
+// maxBlockchainInfoRange is the maximum number of headers returned by a single
+// BlockchainInfo request.
+const maxBlockchainInfoRange = 20
+
+// SetUpgradeSchedule configures the node to upgrade according to the schedule.
+// The network upgrades if the nodes sharing a plan hold at least 2/3 of the
+// voting power. It must be called before the node is started.
+func (n *Node) SetUpgradeSchedule(schedule upgrade.Schedule) error {
+	if err := schedule.ValidateBasic(); err != nil {
+		return err
+	}
+	plans := strings.Join(schedule.FormatPlans(chainID), ",")
+	if err := n.Instance.SetArgs(fmt.Sprintf("--%s=%s", cmd.UpgradeScheduleFlag, plans)); err != nil {
+		return err
+	}
+	n.UpgradeSchedule = schedule
+	return nil
+}
+
+// Height returns the latest height committed by the node. An error is
+// returned if the node can't be reached, for example because it halted.
+func (n *Node) Height(ctx context.Context) (int64, error) {
+	client, err := n.Client()
+	if err != nil {
+		return 0, err
+	}
+	status, err := client.Status(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return status.SyncInfo.LatestBlockHeight, nil
+}
+
+// WaitForHeight blocks until the node has committed the height.
+func (n *Node) WaitForHeight(ctx context.Context, height int64) error {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		// the node may be briefly unreachable, for example while restarting
+		latest, err := n.Height(ctx)
+		if err == nil && latest >= height {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("node %s waiting for height %d: %w", n.Name, height, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// Logs returns the logs of the node. False is returned if the backend doesn't
+// make the logs available.
+func (n *Node) Logs() ([]byte, bool, error) {
+	reader, ok := n.Instance.(LogReader)
+	if !ok {
+		return nil, false, nil
+	}
+	logs, err := reader.Logs()
+	return logs, true, err
+}
+
+// CheckAppVersions checks that the nodes ran the app versions expected by the
+// schedule up to the height. The app version of a block is the version of the
+// state it was built upon, where no app version stands for the initial
+// version. A plan upgrades the network in one of the blocks
+// from its start height minus one up to its end height minus one, so the
+// blocks before the start height of a plan must carry the version that
+// preceded it and the blocks from its end height onwards the version of the
+// plan. Until the first plan starts the network runs the initial version. The
+// app version reported by each node must likewise match its latest height.
+func CheckAppVersions(ctx context.Context, nodes []*Node, schedule upgrade.Schedule, initialVersion uint64, height int64) error {
+	for _, node := range nodes {
+		headers, err := node.headers(ctx, height)
+		if err != nil {
+			return err
+		}
+		lastVersion := initialVersion
+		for _, header := range headers {
+			version := header.Version.App
+			if version == 0 {
+				// InitChain doesn't pass the app version of the genesis state
+				// to Tendermint, so blocks carry no app version until the
+				// first upgrade.
+				version = initialVersion
+			}
+			if minVersion, maxVersion := expectedAppVersions(schedule, initialVersion, header.Height); version < minVersion || version > maxVersion {
+				return fmt.Errorf("node %s: block %d has app version %d, expected %s", node.Name, header.Height, version, formatVersions(minVersion, maxVersion))
+			}
+			if version < lastVersion {
+				return fmt.Errorf("node %s: block %d downgraded the app version from %d to %d", node.Name, header.Height, lastVersion, version)
+			}
+			lastVersion = version
+		}
+
+		client, err := node.Client()
+		if err != nil {
+			return err
+		}
+		info, err := client.ABCIInfo(ctx)
+		if err != nil {
+			return fmt.Errorf("node %s info: %w", node.Name, err)
+		}
+		// the state of the last block is the state the next block is built upon
+		version := info.Response.AppVersion
+		if minVersion, maxVersion := expectedAppVersions(schedule, initialVersion, info.Response.LastBlockHeight+1); version < minVersion || version > maxVersion {
+			return fmt.Errorf("node %s reports app version %d at height %d, expected %s", node.Name, version, info.Response.LastBlockHeight, formatVersions(minVersion, maxVersion))
+		}
+	}
+	return nil
+}
+
+// expectedAppVersions returns the range of app versions that the block at the
+// height may carry according to the schedule.
+func expectedAppVersions(schedule upgrade.Schedule, initialVersion uint64, height int64) (minVersion, maxVersion uint64) {
+	minVersion, maxVersion = initialVersion, initialVersion
+	for _, plan := range schedule {
+		switch {
+		case height >= plan.End:
+			minVersion, maxVersion = plan.Version, plan.Version
+		case height >= plan.Start:
+			maxVersion = plan.Version
+		}
+	}
+	return minVersion, maxVersion
+}
+
+func formatVersions(minVersion, maxVersion uint64) string {
+	if minVersion == maxVersion {
+		return fmt.Sprintf("%d", minVersion)
+	}
+	return fmt.Sprintf("%d to %d", minVersion, maxVersion)
+}
+
+// UpgradeHeight returns the height of the first block up to the height that
+// carries the app version. False is returned if the node didn't upgrade to
+// the version by then.
+func (n *Node) UpgradeHeight(ctx context.Context, version uint64, height int64) (int64, bool, error) {
+	headers, err := n.headers(ctx, height)
+	if err != nil {
+		return 0, false, err
+	}
+	for _, header := range headers {
+		if header.Version.App == version {
+			return header.Height, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// BuildBinary builds celestia-appd from the source of this repository with
+// the ldflags and writes it to the path.
+func BuildBinary(path string, ldflags string) error {
+	out, err := exec.Command("go", "build", "-ldflags", ldflags, "-o", path, "github.com/celestiaorg/celestia-app/cmd/celestia-appd").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("building celestia-appd: %w: %s", err, out)
+	}
+	return nil
+}
+
+// CheckDataRoots checks that the nodes agree on the blocks and their data roots
+// up to the height.
+func CheckDataRoots(ctx context.Context, nodes []*Node, height int64) error {
+	if len(nodes) == 0 {
+		return nil
+	}
+	expected, err := nodes[0].headers(ctx, height)
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes[1:] {
+		headers, err := node.headers(ctx, height)
+		if err != nil {
+			return err
+		}
+		for i, header := range headers {
+			if !bytes.Equal(header.DataHash, expected[i].DataHash) {
+				return fmt.Errorf("node %s: data root of block %d is %X, node %s has %X", node.Name, header.Height, header.DataHash, nodes[0].Name, expected[i].DataHash)
+			}
+			if !bytes.Equal(header.Hash(), expected[i].Hash()) {
+				return fmt.Errorf("node %s: hash of block %d is %X, node %s has %X", node.Name, header.Height, header.Hash(), nodes[0].Name, expected[i].Hash())
+			}
+		}
+	}
+	return nil
+}
+
+// headers returns the headers of the node from height 1 up to the height in
+// ascending order.
+func (n *Node) headers(ctx context.Context, height int64) ([]types.Header, error) {
+	client, err := n.Client()
+	if err != nil {
+		return nil, err
+	}
+	headers := make([]types.Header, 0, height)
+	for minHeight := int64(1); minHeight <= height; minHeight += maxBlockchainInfoRange {
+		maxHeight := min(minHeight+maxBlockchainInfoRange-1, height)
+		resp, err := client.BlockchainInfo(ctx, minHeight, maxHeight)
+		if err != nil {
+			return nil, fmt.Errorf("node %s headers %d-%d: %w", n.Name, minHeight, maxHeight, err)
+		}
+		if len(resp.BlockMetas) != int(maxHeight-minHeight+1) {
+			return nil, fmt.Errorf("node %s returned %d headers for heights %d-%d", n.Name, len(resp.BlockMetas), minHeight, maxHeight)
+		}
+		// block metas are returned in descending order
+		for i := len(resp.BlockMetas) - 1; i >= 0; i-- {
+			headers = append(headers, resp.BlockMetas[i].Header)
+		}
+	}
+	return headers, nil
+}
@@ -0,0 +1,153 @@
+package e2e
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/celestiaorg/celestia-app/app"
+	"github.com/celestiaorg/celestia-app/app/encoding"
+	v1 "github.com/celestiaorg/celestia-app/pkg/appconsts/v1"
+	v2 "github.com/celestiaorg/celestia-app/pkg/appconsts/v2"
+	"github.com/celestiaorg/celestia-app/test/txsim"
+	"github.com/celestiaorg/celestia-app/x/upgrade"
+	"github.com/stretchr/testify/require"
+)
+
+// v1OnlyVersion is the version of the binary built by the test that doesn't
+// support v2.
+const v1OnlyVersion = "v1-only"
+
+// This test runs a testnet with 4 validators that are scheduled to upgrade
+// from v1 to v2 while txsim submits transactions. It asserts that every
+// validator runs v2 after the upgrade and that all validators agree on the
+// data roots across the upgrade. A fifth validator running a version that
+// doesn't support v2 is expected to halt at the upgrade. With the local
+// backend that version is built by the test. With other backends it is only
+// added if E2E_V1_ONLY_VERSION is set, and the panic of the node is only
+// checked if the backend makes the logs of the node available.
+func TestE2EUpgrade(t *testing.T) {
+	if os.Getenv("E2E") == "" {
+		t.Skip("skipping e2e test")
+	}
+
+	if os.Getenv("E2E_VERSION") != "" {
+		latestVersion = os.Getenv("E2E_VERSION")
+	}
+
+	const (
+		scheduledHeight = 20
+		finalHeight     = scheduledHeight + 15
+	)
+	schedule := upgrade.NewSchedule(upgrade.NewPlan(scheduledHeight, scheduledHeight+2, v2.Version))
+
+	testnet, err := New(t.Name(), seed)
+	require.NoError(t, err)
+	t.Cleanup(testnet.Cleanup)
+
+	haltingVersion := os.Getenv("E2E_V1_ONLY_VERSION")
+	if local, ok := testnet.backend.(*LocalBackend); ok && haltingVersion == "" {
+		binary := filepath.Join(t.TempDir(), "celestia-appd-"+v1OnlyVersion)
+		ldflags := fmt.Sprintf("-X github.com/celestiaorg/celestia-app/app.maxSupportedVersion=%d", v1.Version)
+		require.NoError(t, BuildBinary(binary, ldflags))
+		local.AddBinary(v1OnlyVersion, binary)
+		haltingVersion = v1OnlyVersion
+	}
+
+	require.NoError(t, testnet.CreateGenesisNodes(4, latestVersion, 10000000))
+	upgradedNodes := testnet.Nodes()
+	for _, node := range upgradedNodes {
+		require.NoError(t, node.SetUpgradeSchedule(schedule))
+	}
+	if haltingVersion != "" {
+		// the validator holds too little voting power to halt the network
+		require.NoError(t, testnet.CreateGenesisNode(haltingVersion, 1000000))
+	}
+
+	kr, err := testnet.CreateAccount("alice", 1e12)
+	require.NoError(t, err)
+
+	require.NoError(t, testnet.Setup())
+	require.NoError(t, testnet.Start())
+
+	sequences := txsim.NewBlobSequence(txsim.NewRange(200, 4000), txsim.NewRange(1, 3)).Clone(5)
+	sequences = append(sequences, txsim.NewSendSequence(4, 1000, 100).Clone(5)...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	txsimCtx, stopTxsim := context.WithCancel(ctx)
+	defer stopTxsim()
+
+	endpoints := make([]string, len(upgradedNodes))
+	for i, node := range upgradedNodes {
+		endpoints[i] = node.AddressGRPC()
+	}
+	encCfg := encoding.MakeConfig(app.ModuleEncodingRegisters...)
+	opts := txsim.DefaultOptions().WithSeed(seed).WithEndpoints(endpoints[1:]...)
+	txsimErr := make(chan error, 1)
+	go func() {
+		txsimErr <- txsim.Run(txsimCtx, endpoints[0], kr, encCfg, opts, sequences...)
+	}()
+
+	for _, node := range upgradedNodes {
+		require.NoError(t, node.WaitForHeight(ctx, finalHeight))
+	}
+	stopTxsim()
+	err = <-txsimErr
+	require.True(t, errors.Is(err, context.Canceled), err.Error())
+
+	require.NoError(t, CheckAppVersions(ctx, upgradedNodes, schedule, v1.Version, finalHeight))
+	require.NoError(t, CheckDataRoots(ctx, upgradedNodes, finalHeight))
+
+	if haltingVersion != "" {
+		upgradeHeight, ok, err := upgradedNodes[0].UpgradeHeight(ctx, v2.Version, finalHeight)
+		require.NoError(t, err)
+		require.True(t, ok, "network didn't upgrade to v2")
+
+		// the node panics when executing the block that upgrades the network,
+		// which is the block before the first block with the new version. The
+		// block is stored before it is executed, so it is the latest height of
+		// the node.
+		node := testnet.Node(len(upgradedNodes))
+		height, err := node.Height(ctx)
+		require.NoError(t, err)
+		require.Less(t, height, upgradeHeight)
+
+		logs, ok, err := node.Logs()
+		require.NoError(t, err)
+		if !ok {
+			_, local := testnet.backend.(*LocalBackend)
+			require.False(t, local, "the local backend doesn't make the logs of node %s available", node.Name)
+			t.Logf("skipping the check for the panic of node %s, the backend doesn't make its logs available", node.Name)
+			return
+		}
+		require.Contains(t, string(logs), fmt.Sprintf("network has upgraded to version %d which is not supported by this node", v2.Version))
+	}
+}
+
+func TestExpectedAppVersions(t *testing.T) {
+	schedule := upgrade.NewSchedule(upgrade.NewPlan(10, 12, 2), upgrade.NewPlan(20, 20, 3))
+	testCases := []struct {
+		height     int64
+		minVersion uint64
+		maxVersion uint64
+	}{
+		{1, 1, 1},
+		{9, 1, 1},
+		{10, 1, 2},
+		{11, 1, 2},
+		{12, 2, 2},
+		{19, 2, 2},
+		{20, 3, 3},
+		{30, 3, 3},
+	}
+	for _, tc := range testCases {
+		minVersion, maxVersion := expectedAppVersions(schedule, 1, tc.height)
+		require.Equal(t, tc.minVersion, minVersion, tc.height)
+		require.Equal(t, tc.maxVersion, maxVersion, tc.height)
+	}
+}
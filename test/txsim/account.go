@@ -10,28 +10,35 @@ import (
 	"sync"
 	"time"
 
+	errorsmod "cosmossdk.io/errors"
 	"github.com/celestiaorg/celestia-app/app/encoding"
 	"github.com/celestiaorg/celestia-app/pkg/appconsts"
+	"github.com/celestiaorg/celestia-app/pkg/blob"
 	"github.com/celestiaorg/celestia-app/pkg/user"
 	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
 	"github.com/cosmos/cosmos-sdk/crypto/hd"
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
 	"github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
 	bank "github.com/cosmos/cosmos-sdk/x/bank/types"
 	"github.com/cosmos/cosmos-sdk/x/feegrant"
 	"github.com/rs/zerolog/log"
-	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const defaultFee = DefaultGasLimit * appconsts.DefaultMinGasPrice
 
 type AccountManager struct {
-	keys        keyring.Keyring
-	conn        *grpc.ClientConn
+	keys keyring.Keyring
+	// conn queries the first healthy endpoint
+	conn        *endpointPool
 	pending     []*account
 	encCfg      encoding.Config
 	pollTime    time.Duration
 	useFeegrant bool
+	// metrics may be nil if metrics are not recorded
+	metrics *Metrics
 
 	// to protect from concurrent writes to the map
 	mtx          sync.Mutex
@@ -40,17 +47,26 @@ type AccountManager struct {
 	latestHeight uint64
 	lastUpdated  time.Time
 	subaccounts  map[string]*user.Signer
+	// endpoints is the endpoint of every signer by address
+	endpoints map[string]Endpoint
 }
 
+// NewAccountManager creates an account manager that spreads the accounts
+// across the endpoints. The master account uses the first endpoint. Accounts
+// whose endpoint goes down fail over to the next healthy endpoint.
 func NewAccountManager(
 	ctx context.Context,
 	keys keyring.Keyring,
 	encCfg encoding.Config,
 	masterAccName string,
-	conn *grpc.ClientConn,
+	endpoints []Endpoint,
 	pollTime time.Duration,
 	useFeegrant bool,
 ) (*AccountManager, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.New("at least one endpoint is required")
+	}
+
 	records, err := keys.List()
 	if err != nil {
 		return nil, err
@@ -65,7 +81,8 @@ func NewAccountManager(
 		subaccounts: make(map[string]*user.Signer),
 		encCfg:      encCfg,
 		pending:     make([]*account, 0),
-		conn:        conn,
+		conn:        newEndpointPool(endpoints),
+		endpoints:   make(map[string]Endpoint),
 		pollTime:    pollTime,
 		useFeegrant: useFeegrant,
 	}
@@ -144,10 +161,12 @@ func (am *AccountManager) setupMasterAccount(ctx context.Context, masterAccName
 		return fmt.Errorf("error getting master account %s balance: %w", masterAccName, err)
 	}
 
-	am.master, err = user.SetupSigner(ctx, am.keys, am.conn, masterAddress, am.encCfg)
+	endpoint := am.conn.primary()
+	am.master, err = user.SetupSigner(ctx, am.keys, endpoint.Conn, masterAddress, am.encCfg)
 	if err != nil {
 		return err
 	}
+	am.endpoints[masterAddress.String()] = endpoint
 
 	log.Info().
 		Str("address", am.master.Address().String()).
@@ -158,7 +177,8 @@ func (am *AccountManager) setupMasterAccount(ctx context.Context, masterAccName
 }
 
 // AllocateAccounts is used by sequences to specify the number of accounts
-// and the balance of each of those accounts. Not concurrently safe.
+// and the balance of each of those accounts. Accounts of previous runs that
+// are persisted in the keyring are reused. Not concurrently safe.
 func (am *AccountManager) AllocateAccounts(n, balance int) []types.AccAddress {
 	if n < 1 {
 		panic("n must be greater than 0")
@@ -170,9 +190,16 @@ func (am *AccountManager) AllocateAccounts(n, balance int) []types.AccAddress {
 	path := hd.CreateHDPath(types.CoinType, 0, 0).String()
 	addresses := make([]types.AccAddress, n)
 	for i := 0; i < n; i++ {
-		record, _, err := am.keys.NewMnemonic(am.nextAccountName(), keyring.English, path, keyring.DefaultBIP39Passphrase, hd.Secp256k1)
+		name := am.nextAccountName()
+		// the mnemonic of a reused account is unknown. Its private key is
+		// recorded instead.
+		var mnemonic string
+		record, err := am.keys.Key(name)
 		if err != nil {
-			panic(err)
+			record, mnemonic, err = am.keys.NewMnemonic(name, keyring.English, path, keyring.DefaultBIP39Passphrase, hd.Secp256k1)
+			if err != nil {
+				panic(err)
+			}
 		}
 		addresses[i], err = record.GetAddress()
 		if err != nil {
@@ -180,34 +207,56 @@ func (am *AccountManager) AllocateAccounts(n, balance int) []types.AccAddress {
 		}
 
 		am.pending = append(am.pending, &account{
-			address: addresses[i],
-			balance: uint64(balance),
+			address:  addresses[i],
+			balance:  uint64(balance),
+			mnemonic: mnemonic,
 		})
 	}
 	return addresses
 }
 
-// Submit executes on an operation. This is thread safe.
-func (am *AccountManager) Submit(ctx context.Context, op Operation) error {
-	if len(op.Msgs) == 0 {
-		return errors.New("operation must contain at least one message")
+// importAccount adds the recorded account to the keyring and funds it with
+// its balance once the accounts are generated. If the keyring already holds
+// the key of the account, it is reused. Otherwise the key is imported under
+// the next account name that is not taken. Not concurrently safe.
+func (am *AccountManager) importAccount(acc RecordedAccount) (types.AccAddress, error) {
+	privKey, err := acc.privKey()
+	if err != nil {
+		return nil, err
 	}
-
-	var address types.AccAddress
-	for _, msg := range op.Msgs {
-		if err := msg.ValidateBasic(); err != nil {
-			return fmt.Errorf("error validating message: %w", err)
+	address := types.AccAddress(privKey.PubKey().Address())
+	if _, err := am.keys.KeyByAddress(address); err != nil {
+		name := am.nextAccountName()
+		for i := len(am.pending) + len(am.subaccounts) + 1; ; i++ {
+			if _, err := am.keys.Key(name); err != nil {
+				break
+			}
+			name = accountName(i)
 		}
-
-		signers := msg.GetSigners()
-		if len(signers) != 1 {
-			return fmt.Errorf("only a single signer is supported got: %d", len(signers))
+		if acc.Mnemonic != "" {
+			path := hd.CreateHDPath(types.CoinType, 0, 0).String()
+			_, err = am.keys.NewAccount(name, acc.Mnemonic, keyring.DefaultBIP39Passphrase, path, hd.Secp256k1)
+		} else {
+			err = am.keys.ImportPrivKey(name, acc.PrivKeyArmor, recordedKeyPassphrase)
 		}
-
-		if address == nil {
-			address = signers[0]
+		if err != nil {
+			return nil, err
 		}
 	}
+	am.pending = append(am.pending, &account{
+		address:  address,
+		balance:  acc.Balance,
+		mnemonic: acc.Mnemonic,
+	})
+	return address, nil
+}
+
+// Submit executes on an operation. This is thread safe.
+func (am *AccountManager) Submit(ctx context.Context, op Operation) error {
+	address, err := operationSigner(op)
+	if err != nil {
+		return err
+	}
 
 	// If a delay is set, wait for that many blocks to have been produced
 	// before continuing
@@ -222,6 +271,42 @@ func (am *AccountManager) Submit(ctx context.Context, op Operation) error {
 		return err
 	}
 
+	res, err := am.broadcast(ctx, signer, op)
+	if err != nil {
+		return err
+	}
+
+	_, err = am.confirm(ctx, op, res)
+	return err
+}
+
+// operationSigner validates the messages of the operation and returns the
+// address of their signer.
+func operationSigner(op Operation) (types.AccAddress, error) {
+	if len(op.Msgs) == 0 {
+		return nil, errors.New("operation must contain at least one message")
+	}
+
+	var address types.AccAddress
+	for _, msg := range op.Msgs {
+		if err := msg.ValidateBasic(); err != nil {
+			return nil, fmt.Errorf("error validating message: %w", err)
+		}
+
+		signers := msg.GetSigners()
+		if len(signers) != 1 {
+			return nil, fmt.Errorf("only a single signer is supported got: %d", len(signers))
+		}
+
+		if address == nil {
+			address = signers[0]
+		}
+	}
+	return address, nil
+}
+
+// txOptions returns the gas limit, fee and fee granter of the operation.
+func (am *AccountManager) txOptions(op Operation) []user.TxOption {
 	opts := make([]user.TxOption, 0)
 	if op.GasLimit == 0 {
 		opts = append(opts, user.SetGasLimit(DefaultGasLimit), user.SetFee(defaultFee))
@@ -234,48 +319,208 @@ func (am *AccountManager) Submit(ctx context.Context, op Operation) error {
 		}
 	}
 
-	if am.useFeegrant {
+	switch {
+	case op.FeeGranter != nil:
+		opts = append(opts, user.SetFeeGranter(op.FeeGranter))
+	case am.useFeegrant:
 		opts = append(opts, user.SetFeeGranter(am.master.Address()))
 	}
+	return opts
+}
 
-	var res *types.TxResponse
-	if len(op.Blobs) > 0 {
-		res, err = signer.SubmitPayForBlob(ctx, op.Blobs, opts...)
-	} else {
-		res, err = signer.SubmitTx(ctx, op.Msgs, opts...)
+// pendingTx is a transaction that has been broadcast but not yet confirmed.
+type pendingTx struct {
+	*types.TxResponse
+	broadcastAt time.Time
+	// signer is the signer that broadcast the transaction which may differ
+	// from the signer passed to broadcast after a failover.
+	signer *user.Signer
+	// rejected is set if the transaction was rejected as expected by the
+	// operation and thus needs no confirmation.
+	rejected bool
+}
+
+// broadcast signs and broadcasts the transaction of the operation without
+// waiting for it to be committed. The duration of signing and broadcasting
+// is recorded.
+func (am *AccountManager) broadcast(ctx context.Context, signer *user.Signer, op Operation) (pendingTx, error) {
+	opts := am.txOptions(op)
+	// read the sequence number so that it can be restored if the transaction
+	// is rejected and the sequence number is not consumed
+	address, sequence := signer.Address(), nextSequence(signer)
+
+	start := time.Now()
+	var (
+		txBytes []byte
+		err     error
+	)
+	switch {
+	case op.Craft != nil:
+		txBytes, err = op.Craft(signer, opts...)
+	case len(op.Blobs) > 0:
+		txBytes, err = signer.CreatePayForBlob(op.Blobs, opts...)
+	default:
+		txBytes, err = signer.CreateTx(op.Msgs, opts...)
 	}
 	if err != nil {
-		return err
+		am.metrics.observeFailure(nil, err)
+		am.restoreSequence(address, sequence)
+		return pendingTx{}, err
+	}
+	am.metrics.observeSign(time.Since(start))
+
+	start = time.Now()
+	res, err := signer.BroadcastTx(ctx, txBytes)
+	am.metrics.observeEndpoint(am.endpointOf(signer).Address, err)
+	// the signed transaction stays valid on any endpoint so it is
+	// rebroadcast as is
+	for isUnavailable(err) {
+		signer, err = am.failover(signer)
+		if err != nil {
+			am.metrics.observeFailure(nil, err)
+			am.restoreSequence(address, sequence)
+			return pendingTx{}, err
+		}
+		res, err = signer.BroadcastTx(ctx, txBytes)
+		am.metrics.observeEndpoint(am.endpointOf(signer).Address, err)
+	}
+	if err != nil && ctx.Err() != nil {
+		return pendingTx{}, ctx.Err()
+	}
+	if err != nil && status.Code(err) == codes.Unknown && expectsError(op, sdkerrors.ErrTxTooLarge) {
+		// the mempool rejects a tx that exceeds the max block size before
+		// CheckTx, so the rejection carries no ABCI code
+		res = &types.TxResponse{
+			Codespace: sdkerrors.ErrTxTooLarge.Codespace(),
+			Code:      sdkerrors.ErrTxTooLarge.ABCICode(),
+			RawLog:    err.Error(),
+		}
+		err = nil
+	}
+	if len(op.ExpectedErrors) > 0 && res != nil {
+		if err := am.checkRejection(res, op); err != nil {
+			return pendingTx{}, err
+		}
+		am.restoreSequence(address, sequence)
+		return pendingTx{TxResponse: res, broadcastAt: start, signer: signer, rejected: true}, nil
 	}
+	if err == nil && res.Code != 0 {
+		// wrap the registered error of the code so that it can be classified
+		err = fmt.Errorf("tx failed with code %d: %w", res.Code, errorsmod.ABCIError(res.Codespace, res.Code, res.RawLog))
+	}
+	if err != nil {
+		am.metrics.observeFailure(res, err)
+		am.restoreSequence(address, sequence)
+		return pendingTx{}, err
+	}
+	am.metrics.observeBroadcast(time.Since(start))
+	return pendingTx{TxResponse: res, broadcastAt: start, signer: signer}, nil
+}
+
+// confirm waits for the broadcast transaction to be committed and records
+// the time it took to be included. If the endpoint goes down, the
+// transaction is looked up on the next healthy endpoint.
+func (am *AccountManager) confirm(ctx context.Context, op Operation, tx pendingTx) (*types.TxResponse, error) {
+	if tx.rejected {
+		return tx.TxResponse, nil
+	}
+	signer := tx.signer
+	res, err := signer.ConfirmTx(ctx, tx.TxHash)
+	for isUnavailable(err) {
+		if signer, err = am.failover(signer); err != nil {
+			break
+		}
+		res, err = signer.ConfirmTx(ctx, tx.TxHash)
+	}
+	if err != nil {
+		am.metrics.observeFailure(res, err)
+		return res, err
+	}
+	am.metrics.observeCommit(time.Since(tx.broadcastAt), res.Height, blobBytes(op.Blobs))
 
 	// update the latest latestHeight
 	am.setLatestHeight(res.Height)
 
 	log.Info().
 		Int64("height", res.Height).
-		Str("address", address.String()).
+		Str("address", signer.Address().String()).
 		Str("msgs", msgsToString(op.Msgs)).
 		Msg("tx committed")
 
-	return nil
+	return res, nil
+}
+
+// checkRejection verifies that the node rejected the transaction of an
+// adversarial operation with one of the expected errors.
+func (am *AccountManager) checkRejection(res *types.TxResponse, op Operation) error {
+	if res.Code == 0 {
+		am.metrics.observeAdversarial(AdversarialAccepted)
+		log.Error().
+			Str("tx_hash", res.TxHash).
+			Str("msgs", msgsToString(op.Msgs)).
+			Msg("adversarial tx was accepted")
+		return fmt.Errorf("%w: %s", ErrUnexpectedAcceptance, res.TxHash)
+	}
+	for _, expected := range op.ExpectedErrors {
+		if isABCIError(res, expected) {
+			am.metrics.observeAdversarial(AdversarialRejected)
+			log.Info().
+				Str("codespace", res.Codespace).
+				Uint32("code", res.Code).
+				Str("msgs", msgsToString(op.Msgs)).
+				Msg("adversarial tx rejected")
+			return nil
+		}
+	}
+	am.metrics.observeAdversarial(AdversarialUnexpectedError)
+	return fmt.Errorf("%w: got code %d in codespace %s: %s", ErrUnexpectedRejection, res.Code, res.Codespace, res.RawLog)
 }
 
-// Generate the pending accounts by sending the adequate funds. This operation
-// is not concurrently safe.
+// expectsError returns true if the operation expects to be rejected with err.
+func expectsError(op Operation, err *errorsmod.Error) bool {
+	for _, expected := range op.ExpectedErrors {
+		if expected == err {
+			return true
+		}
+	}
+	return false
+}
+
+// Generate the pending accounts by sending the adequate funds. Accounts that
+// exist from a previous run are only topped up to their balance and are only
+// granted a fee allowance if they don't have one yet. This operation is not
+// concurrently safe.
 func (am *AccountManager) GenerateAccounts(ctx context.Context) error {
 	if len(am.pending) == 0 {
 		return nil
 	}
 
+	var grantees map[string]bool
+	if am.useFeegrant {
+		var err error
+		grantees, err = am.feegrantGrantees(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
 	msgs := make([]types.Msg, 0)
 	gasLimit := 0
+	needed := uint64(0)
 	// batch together all the messages needed to create all the accounts
 	for _, acc := range am.pending {
-		if am.balance < acc.balance {
-			return fmt.Errorf("master account has insufficient funds. has: %v needed: %v", am.balance, acc.balance)
+		balance, err := am.getBalance(ctx, acc.address)
+		if err != nil {
+			return err
+		}
+		if balance > 0 {
+			log.Info().
+				Str("address", acc.address.String()).
+				Uint64("balance", balance).
+				Msg("reusing account")
 		}
 
-		if am.useFeegrant {
+		if am.useFeegrant && !grantees[acc.address.String()] {
 			// create a feegrant message so that the master account pays for all the fees of the sub accounts
 			feegrantMsg, err := feegrant.NewMsgGrantAllowance(&feegrant.BasicAllowance{}, am.master.Address(), acc.address)
 			if err != nil {
@@ -285,19 +530,33 @@ func (am *AccountManager) GenerateAccounts(ctx context.Context) error {
 			gasLimit += FeegrantGasLimit
 		}
 
-		bankMsg := bank.NewMsgSend(am.master.Address(), acc.address, types.NewCoins(types.NewInt64Coin(appconsts.BondDenom, int64(acc.balance))))
+		if balance >= acc.balance {
+			continue
+		}
+		topUp := acc.balance - balance
+		needed += topUp
+		bankMsg := bank.NewMsgSend(am.master.Address(), acc.address, types.NewCoins(types.NewInt64Coin(appconsts.BondDenom, int64(topUp))))
 		msgs = append(msgs, bankMsg)
 		gasLimit += SendGasLimit
 	}
+	if am.balance < needed {
+		return fmt.Errorf("master account has insufficient funds. has: %v needed: %v", am.balance, needed)
+	}
 
-	err := am.Submit(ctx, Operation{Msgs: msgs, GasLimit: uint64(gasLimit)})
-	if err != nil {
-		return fmt.Errorf("error funding accounts: %w", err)
+	if len(msgs) > 0 {
+		err := am.Submit(ctx, Operation{Msgs: msgs, GasLimit: uint64(gasLimit)})
+		if err != nil {
+			return fmt.Errorf("error funding accounts: %w", err)
+		}
 	}
 
 	// check that the account now exists
 	for _, acc := range am.pending {
-		signer, err := user.SetupSigner(ctx, am.keys, am.conn, acc.address, am.encCfg)
+		endpoint, err := am.conn.assign()
+		if err != nil {
+			return err
+		}
+		signer, err := user.SetupSigner(ctx, am.keys, endpoint.Conn, acc.address, am.encCfg)
 		if err != nil {
 			return err
 		}
@@ -307,11 +566,13 @@ func (am *AccountManager) GenerateAccounts(ctx context.Context) error {
 		// set the account
 		am.mtx.Lock()
 		am.subaccounts[acc.address.String()] = signer
+		am.endpoints[acc.address.String()] = endpoint
 		am.mtx.Unlock()
 		log.Info().
 			Str("address", acc.address.String()).
 			Uint64("balance", acc.balance).
 			Uint64("account number", signer.AccountNumber()).
+			Str("endpoint", endpoint.Address).
 			Msg("initialized account")
 	}
 
@@ -345,6 +606,85 @@ func (am *AccountManager) getSubAccount(address types.AccAddress) (*user.Signer,
 	return signer, nil
 }
 
+// nextSequence returns the sequence number that the signer signs its next
+// transaction with without consuming it.
+func nextSequence(signer *user.Signer) uint64 {
+	// GetSequence increments the sequence number so it is set back
+	sequence := signer.GetSequence()
+	signer.ForceSetSequence(sequence)
+	return sequence
+}
+
+// restoreSequence sets the sequence number of the account back to sequence
+// after a broadcast that didn't consume it. The signer is looked up as it may
+// have been replaced by a failover.
+func (am *AccountManager) restoreSequence(address types.AccAddress, sequence uint64) {
+	if signer, err := am.getSubAccount(address); err == nil {
+		signer.ForceSetSequence(sequence)
+	}
+}
+
+// endpointOf returns the endpoint the signer submits transactions to.
+func (am *AccountManager) endpointOf(signer *user.Signer) Endpoint {
+	am.mtx.Lock()
+	defer am.mtx.Unlock()
+	return am.endpoints[signer.Address().String()]
+}
+
+// failover marks the endpoint of the signer as down and replaces the signer
+// with a signer of the same account on the next healthy endpoint. The
+// sequence number is carried over. If the signer has already been replaced,
+// the replacement is returned.
+func (am *AccountManager) failover(signer *user.Signer) (*user.Signer, error) {
+	address := signer.Address().String()
+	am.mtx.Lock()
+	defer am.mtx.Unlock()
+
+	current := am.master
+	if !bytes.Equal(am.master.Address(), signer.Address()) {
+		current = am.subaccounts[address]
+	}
+	if current != signer {
+		return current, nil
+	}
+
+	down := am.endpoints[address]
+	am.conn.markDown(down)
+	endpoint, err := am.conn.assign()
+	if err != nil {
+		return nil, fmt.Errorf("failing over from %s: %w", down.Address, err)
+	}
+
+	sequence := nextSequence(signer)
+	replacement, err := user.NewSigner(
+		am.keys,
+		endpoint.Conn,
+		signer.Address(),
+		am.encCfg.TxConfig,
+		signer.ChainID(),
+		signer.AccountNumber(),
+		sequence,
+	)
+	if err != nil {
+		return nil, err
+	}
+	replacement.SetPollTime(am.pollTime)
+
+	if current == am.master {
+		am.master = replacement
+	} else {
+		am.subaccounts[address] = replacement
+	}
+	am.endpoints[address] = endpoint
+	am.metrics.observeFailover(down.Address)
+	log.Warn().
+		Str("address", address).
+		Str("from", down.Address).
+		Str("to", endpoint.Address).
+		Msg("failed over to endpoint")
+	return replacement, nil
+}
+
 func (am *AccountManager) waitDelay(ctx context.Context, blocks uint64) error {
 	latestHeight, err := am.updateHeight(ctx)
 	if err != nil {
@@ -398,12 +738,21 @@ func (am *AccountManager) nextAccountName() string {
 }
 
 type account struct {
-	address types.AccAddress
-	balance uint64
+	address  types.AccAddress
+	balance  uint64
+	mnemonic string
 }
 
 func accountName(n int) string { return fmt.Sprintf("tx-sim-%d", n) }
 
+func blobBytes(blobs []*blob.Blob) int {
+	size := 0
+	for _, b := range blobs {
+		size += len(b.Data)
+	}
+	return size
+}
+
 func msgsToString(msgs []types.Msg) string {
 	msgsStr := make([]string, len(msgs))
 	for i, msg := range msgs {
@@ -0,0 +1,315 @@
+package txsim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+
+	errorsmod "cosmossdk.io/errors"
+	"github.com/celestiaorg/celestia-app/pkg/appconsts"
+	"github.com/celestiaorg/celestia-app/pkg/blob"
+	ns "github.com/celestiaorg/celestia-app/pkg/namespace"
+	"github.com/celestiaorg/celestia-app/pkg/shares"
+	"github.com/celestiaorg/celestia-app/pkg/user"
+	"github.com/celestiaorg/celestia-app/test/util/blobfactory"
+	blobtypes "github.com/celestiaorg/celestia-app/x/blob/types"
+	nodeservice "github.com/cosmos/cosmos-sdk/client/grpc/node"
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	"github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	bank "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/gogo/protobuf/grpc"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	// ErrUnexpectedAcceptance is returned when the node accepts an adversarial
+	// transaction.
+	ErrUnexpectedAcceptance = errors.New("adversarial tx was accepted")
+	// ErrUnexpectedRejection is returned when the node rejects an adversarial
+	// transaction with an error other than the expected ones.
+	ErrUnexpectedRejection = errors.New("adversarial tx was rejected with an unexpected error")
+)
+
+// Attack is a kind of deliberately invalid transaction that the node is
+// expected to reject.
+type Attack string
+
+const (
+	// AttackMismatchedCommitment submits a blob tx whose blob data doesn't
+	// match the share commitment of its PFB.
+	AttackMismatchedCommitment Attack = "mismatched_commitment"
+	// AttackNoBlobs submits a PFB as a regular tx without any blobs.
+	AttackNoBlobs Attack = "no_blobs"
+	// AttackOversizedBlob submits a blob that is one byte larger than the
+	// maximum total blob size allowed by the max square size.
+	AttackOversizedBlob Attack = "oversized_blob"
+	// AttackNonceGap submits a tx with a sequence number ahead of the
+	// account's sequence number.
+	AttackNonceGap Attack = "nonce_gap"
+	// AttackDuplicate submits a valid tx and then broadcasts it again.
+	AttackDuplicate Attack = "duplicate"
+	// AttackLowGasPrice submits a tx with a gas price below the minimum gas
+	// price.
+	AttackLowGasPrice Attack = "low_gas_price"
+)
+
+// Attacks lists all attacks.
+var Attacks = []Attack{
+	AttackMismatchedCommitment,
+	AttackNoBlobs,
+	AttackOversizedBlob,
+	AttackNonceGap,
+	AttackDuplicate,
+	AttackLowGasPrice,
+}
+
+// Validate returns an error if the attack is unknown.
+func (a Attack) Validate() error {
+	for _, attack := range Attacks {
+		if a == attack {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown attack %q", a)
+}
+
+var _ Sequence = &AdversarialSequence{}
+
+// AdversarialSequence sets up an endless sequence whereby a single account
+// submits deliberately invalid transactions picked at random from the provided
+// attacks. Each transaction must be rejected with the error expected for the
+// attack. Any unexpected acceptance or error ends the sequence.
+type AdversarialSequence struct {
+	attacks     []Attack
+	blobSize    Range
+	account     types.AccAddress
+	useFeegrant bool
+	minGasPrice float64
+
+	// duplicate holds the bytes of a valid transaction that is broadcast
+	// again by the next operation.
+	duplicate []byte
+}
+
+// NewAdversarialSequence returns a sequence of the provided attacks. If no
+// attacks are provided, all attacks are used.
+func NewAdversarialSequence(attacks ...Attack) *AdversarialSequence {
+	if len(attacks) == 0 {
+		attacks = Attacks
+	}
+	return &AdversarialSequence{
+		attacks:  attacks,
+		blobSize: NewRange(100, 10_000),
+	}
+}
+
+func (s *AdversarialSequence) Clone(n int) []Sequence {
+	sequenceGroup := make([]Sequence, n)
+	for i := 0; i < n; i++ {
+		sequenceGroup[i] = NewAdversarialSequence(s.attacks...)
+	}
+	return sequenceGroup
+}
+
+func (s *AdversarialSequence) Init(ctx context.Context, querier grpc.ClientConn, allocateAccounts AccountAllocator, _ *rand.Rand, useFeegrant bool) {
+	s.useFeegrant = useFeegrant
+	s.account = allocateAccounts(1, gasFunds(useFeegrant))[0]
+
+	// a node without a minimum gas price accepts any gas price
+	minGasPrice, err := nodeMinGasPrice(ctx, querier)
+	if err != nil || minGasPrice == 0 {
+		log.Warn().Err(err).Msg("skipping the low gas price attack because the node has no known minimum gas price")
+		attacks := make([]Attack, 0, len(s.attacks))
+		for _, attack := range s.attacks {
+			if attack != AttackLowGasPrice {
+				attacks = append(attacks, attack)
+			}
+		}
+		s.attacks = attacks
+	}
+	s.minGasPrice = minGasPrice
+}
+
+func (s *AdversarialSequence) Next(ctx context.Context, querier grpc.ClientConn, rand *rand.Rand) (Operation, error) {
+	if s.duplicate != nil {
+		return s.rebroadcast(), nil
+	}
+	if len(s.attacks) == 0 {
+		return Operation{}, errors.New("none of the attacks can be performed against the node")
+	}
+
+	switch attack := s.attacks[rand.Intn(len(s.attacks))]; attack {
+	case AttackMismatchedCommitment:
+		return s.mismatchedCommitment(rand)
+	case AttackNoBlobs:
+		return s.noBlobs(rand)
+	case AttackOversizedBlob:
+		return s.oversizedBlob(ctx, querier, rand)
+	case AttackNonceGap:
+		return s.nonceGap(rand), nil
+	case AttackDuplicate:
+		return s.original(), nil
+	case AttackLowGasPrice:
+		return Operation{
+			Msgs:           []types.Msg{s.send()},
+			GasLimit:       DefaultGasLimit,
+			GasPrice:       s.minGasPrice / 100,
+			ExpectedErrors: []*errorsmod.Error{sdkerrors.ErrInsufficientFee},
+		}, nil
+	default:
+		return Operation{}, fmt.Errorf("unknown attack %s", attack)
+	}
+}
+
+func (s *AdversarialSequence) mismatchedCommitment(rand *rand.Rand) (Operation, error) {
+	blobs, msg, err := s.randomPFB(rand, s.blobSize.Rand(rand))
+	if err != nil {
+		return Operation{}, err
+	}
+	return Operation{
+		Msgs:     []types.Msg{msg},
+		GasLimit: estimateGas([]int{len(blobs[0].Data)}, s.useFeegrant),
+		Craft: func(signer *user.Signer, opts ...user.TxOption) ([]byte, error) {
+			txBytes, err := signer.CreateTx([]types.Msg{msg}, opts...)
+			if err != nil {
+				return nil, err
+			}
+			// flip the bits of the first byte of the blob after the share
+			// commitment has been computed
+			tampered := *blobs[0]
+			tampered.Data = append([]byte{tampered.Data[0] ^ 0xff}, tampered.Data[1:]...)
+			return blob.MarshalBlobTx(txBytes, &tampered)
+		},
+		ExpectedErrors: []*errorsmod.Error{blobtypes.ErrInvalidShareCommitment},
+	}, nil
+}
+
+func (s *AdversarialSequence) noBlobs(rand *rand.Rand) (Operation, error) {
+	_, msg, err := s.randomPFB(rand, s.blobSize.Rand(rand))
+	if err != nil {
+		return Operation{}, err
+	}
+	return Operation{
+		Msgs: []types.Msg{msg},
+		Craft: func(signer *user.Signer, opts ...user.TxOption) ([]byte, error) {
+			return signer.CreateTx([]types.Msg{msg}, opts...)
+		},
+		ExpectedErrors: []*errorsmod.Error{blobtypes.ErrNoBlobs},
+	}, nil
+}
+
+func (s *AdversarialSequence) oversizedBlob(ctx context.Context, querier grpc.ClientConn, rand *rand.Rand) (Operation, error) {
+	maxSize, err := maxTotalBlobSize(ctx, querier)
+	if err != nil {
+		return Operation{}, err
+	}
+	blobs, msg, err := s.randomPFB(rand, maxSize+1)
+	if err != nil {
+		return Operation{}, err
+	}
+	return Operation{
+		Msgs:     []types.Msg{msg},
+		Blobs:    blobs,
+		GasLimit: estimateGas([]int{maxSize + 1}, s.useFeegrant),
+		// the mempool may reject the tx based on its size before it is checked
+		ExpectedErrors: []*errorsmod.Error{blobtypes.ErrTotalBlobSizeTooLarge, sdkerrors.ErrTxTooLarge},
+	}, nil
+}
+
+func (s *AdversarialSequence) nonceGap(rand *rand.Rand) Operation {
+	msg := s.send()
+	gap := uint64(1 + rand.Intn(10))
+	return Operation{
+		Msgs: []types.Msg{msg},
+		Craft: func(signer *user.Signer, opts ...user.TxOption) ([]byte, error) {
+			sequence := signer.GetSequence()
+			signer.ForceSetSequence(sequence + gap)
+			defer signer.ForceSetSequence(sequence)
+			return signer.CreateTx([]types.Msg{msg}, opts...)
+		},
+		ExpectedErrors: []*errorsmod.Error{sdkerrors.ErrWrongSequence},
+	}
+}
+
+// original returns a valid operation whose transaction is kept so that it
+// can be broadcast again by the next operation.
+func (s *AdversarialSequence) original() Operation {
+	msg := s.send()
+	return Operation{
+		Msgs: []types.Msg{msg},
+		Craft: func(signer *user.Signer, opts ...user.TxOption) ([]byte, error) {
+			txBytes, err := signer.CreateTx([]types.Msg{msg}, opts...)
+			if err != nil {
+				return nil, err
+			}
+			s.duplicate = txBytes
+			return txBytes, nil
+		},
+	}
+}
+
+// rebroadcast returns an operation that broadcasts the kept transaction
+// again. Depending on whether the original transaction has been committed, it
+// is rejected by the mempool cache or for reusing a sequence number.
+func (s *AdversarialSequence) rebroadcast() Operation {
+	txBytes := s.duplicate
+	s.duplicate = nil
+	return Operation{
+		Msgs: []types.Msg{s.send()},
+		Craft: func(*user.Signer, ...user.TxOption) ([]byte, error) {
+			return txBytes, nil
+		},
+		ExpectedErrors: []*errorsmod.Error{sdkerrors.ErrTxInMempoolCache, sdkerrors.ErrWrongSequence},
+	}
+}
+
+// send returns a message sending a single utia from the account to itself.
+func (s *AdversarialSequence) send() types.Msg {
+	return bank.NewMsgSend(s.account, s.account, types.NewCoins(types.NewInt64Coin(appconsts.BondDenom, 1)))
+}
+
+// randomPFB returns a single blob of the given size in a random namespace and
+// the PFB paying for it.
+func (s *AdversarialSequence) randomPFB(rand *rand.Rand, size int) ([]*blob.Blob, *blobtypes.MsgPayForBlobs, error) {
+	namespace := make([]byte, ns.NamespaceVersionZeroIDSize)
+	if _, err := rand.Read(namespace); err != nil {
+		return nil, nil, fmt.Errorf("generating random namespace: %w", err)
+	}
+	blobs := blobfactory.RandBlobsWithNamespace([]ns.Namespace{ns.MustNewV0(namespace)}, []int{size})
+	msg, err := blobtypes.NewMsgPayForBlobs(s.account.String(), blobs...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return blobs, msg, nil
+}
+
+// maxTotalBlobSize returns the maximum total blob size of a PFB as enforced
+// by the MaxTotalBlobSizeDecorator.
+func maxTotalBlobSize(ctx context.Context, querier grpc.ClientConn) (int, error) {
+	params, err := blobtypes.NewQueryClient(querier).Params(ctx, &blobtypes.QueryParamsRequest{})
+	if err != nil {
+		return 0, fmt.Errorf("querying blob params: %w", err)
+	}
+	block, err := tmservice.NewServiceClient(querier).GetLatestBlock(ctx, &tmservice.GetLatestBlockRequest{})
+	if err != nil {
+		return 0, fmt.Errorf("querying latest block: %w", err)
+	}
+	squareSize := min(appconsts.SquareSizeUpperBound(block.SdkBlock.Header.Version.App), int(params.Params.GovMaxSquareSize))
+	return shares.AvailableBytesFromSparseShares(squareSize*squareSize - 1), nil
+}
+
+// nodeMinGasPrice returns the minimum gas price in utia that the node
+// requires to accept a transaction into its mempool.
+func nodeMinGasPrice(ctx context.Context, querier grpc.ClientConn) (float64, error) {
+	resp, err := nodeservice.NewServiceClient(querier).Config(ctx, &nodeservice.ConfigRequest{})
+	if err != nil {
+		return 0, fmt.Errorf("querying node config: %w", err)
+	}
+	prices, err := types.ParseDecCoins(resp.MinimumGasPrice)
+	if err != nil {
+		return 0, fmt.Errorf("parsing minimum gas price %q: %w", resp.MinimumGasPrice, err)
+	}
+	return prices.AmountOf(appconsts.BondDenom).MustFloat64(), nil
+}
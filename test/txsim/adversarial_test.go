@@ -0,0 +1,34 @@
+package txsim
+
+import (
+	"testing"
+
+	errorsmod "cosmossdk.io/errors"
+	blobtypes "github.com/celestiaorg/celestia-app/x/blob/types"
+	"github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRejection(t *testing.T) {
+	am := &AccountManager{metrics: NewMetrics()}
+	op := Operation{
+		Msgs:           []types.Msg{},
+		ExpectedErrors: []*errorsmod.Error{blobtypes.ErrNoBlobs, sdkerrors.ErrWrongSequence},
+	}
+	response := func(err *errorsmod.Error) *types.TxResponse {
+		return &types.TxResponse{Codespace: err.Codespace(), Code: err.ABCICode()}
+	}
+
+	require.NoError(t, am.checkRejection(response(blobtypes.ErrNoBlobs), op))
+	require.NoError(t, am.checkRejection(response(sdkerrors.ErrWrongSequence), op))
+	require.ErrorIs(t, am.checkRejection(&types.TxResponse{TxHash: "ABCD"}, op), ErrUnexpectedAcceptance)
+	require.ErrorIs(t, am.checkRejection(response(sdkerrors.ErrInsufficientFee), op), ErrUnexpectedRejection)
+
+	assert.Equal(t, map[string]int{
+		AdversarialRejected:        2,
+		AdversarialAccepted:        1,
+		AdversarialUnexpectedError: 1,
+	}, am.metrics.Summary().Adversarial)
+}
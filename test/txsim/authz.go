@@ -0,0 +1,90 @@
+package txsim
+
+import (
+	"context"
+	"math/rand"
+
+	"github.com/celestiaorg/celestia-app/pkg/appconsts"
+	"github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	bank "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/gogo/protobuf/grpc"
+)
+
+var _ Sequence = &AuthzSequence{}
+
+// AuthzSequence sets up an endless sequence whereby a granter authorizes a
+// grantee to send funds on its behalf. The grantee then repeatedly executes
+// sends from the granter to itself and returns the funds to the granter.
+// Occasionally the granter revokes the authorization and grants it again.
+type AuthzSequence struct {
+	amount            int
+	revokeProbability int
+
+	granter types.AccAddress
+	grantee types.AccAddress
+	granted bool
+	// received is true if the grantee has not yet returned the funds of the
+	// last send it executed.
+	received bool
+}
+
+func NewAuthzSequence(amount int) *AuthzSequence {
+	return &AuthzSequence{
+		amount:            amount,
+		revokeProbability: 10, // 1 in every 10
+	}
+}
+
+func (s *AuthzSequence) Clone(n int) []Sequence {
+	sequenceGroup := make([]Sequence, n)
+	for i := 0; i < n; i++ {
+		sequenceGroup[i] = NewAuthzSequence(s.amount)
+	}
+	return sequenceGroup
+}
+
+// Init funds the granter with the amount of a single send as the grantee
+// returns the funds of every send it executes.
+func (s *AuthzSequence) Init(_ context.Context, _ grpc.ClientConn, allocateAccounts AccountAllocator, _ *rand.Rand, useFeegrant bool) {
+	s.granter = allocateAccounts(1, s.amount+gasFunds(useFeegrant))[0]
+	s.grantee = allocateAccounts(1, gasFunds(useFeegrant))[0]
+}
+
+func (s *AuthzSequence) Next(_ context.Context, _ grpc.ClientConn, rand *rand.Rand) (Operation, error) {
+	sendMsgType := types.MsgTypeURL(&bank.MsgSend{})
+	amount := types.NewCoins(types.NewInt64Coin(appconsts.BondDenom, int64(s.amount)))
+
+	// the funds are returned regardless of the authorization
+	if s.received {
+		s.received = false
+		return Operation{
+			Msgs: []types.Msg{bank.NewMsgSend(s.grantee, s.granter, amount)},
+		}, nil
+	}
+
+	if !s.granted {
+		msg, err := authz.NewMsgGrant(s.granter, s.grantee, authz.NewGenericAuthorization(sendMsgType), nil)
+		if err != nil {
+			return Operation{}, err
+		}
+		s.granted = true
+		return Operation{Msgs: []types.Msg{msg}}, nil
+	}
+
+	// occasionally revoke the authorization. It is granted again with the
+	// next operation
+	if rand.Intn(s.revokeProbability) == 0 {
+		msg := authz.NewMsgRevoke(s.granter, s.grantee, sendMsgType)
+		s.granted = false
+		return Operation{Msgs: []types.Msg{&msg}}, nil
+	}
+
+	send := bank.NewMsgSend(s.granter, s.grantee, amount)
+	msg := authz.NewMsgExec(s.grantee, []types.Msg{send})
+	s.received = true
+	return Operation{
+		Msgs:  []types.Msg{&msg},
+		Delay: uint64(rand.Int63n(5)),
+	}, nil
+}
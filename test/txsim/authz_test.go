@@ -0,0 +1,47 @@
+package txsim
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	bank "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthzSequenceReturnsFunds(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	accounts := []types.AccAddress{types.AccAddress("granter"), types.AccAddress("grantee")}
+	var granterBalance int
+	seq := NewAuthzSequence(100)
+	seq.Init(context.Background(), nil, func(n, balance int) []types.AccAddress {
+		if granterBalance == 0 {
+			granterBalance = balance - gasFunds(true)
+		}
+		account := accounts[0]
+		accounts = accounts[1:]
+		return []types.AccAddress{account}
+	}, r, true)
+
+	// the granter never runs out of funds to send
+	execs := 0
+	for i := 0; i < 10_000; i++ {
+		op, err := seq.Next(context.Background(), nil, r)
+		require.NoError(t, err)
+		require.Len(t, op.Msgs, 1)
+		require.NoError(t, op.Msgs[0].ValidateBasic())
+		switch msg := op.Msgs[0].(type) {
+		case *authz.MsgExec:
+			execs++
+			granterBalance -= 100
+		case *bank.MsgSend:
+			require.Equal(t, seq.grantee.String(), msg.FromAddress)
+			require.Equal(t, seq.granter.String(), msg.ToAddress)
+			granterBalance += 100
+		}
+		require.GreaterOrEqual(t, granterBalance, 0)
+	}
+	require.Greater(t, execs, 1000)
+}
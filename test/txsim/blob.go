@@ -3,6 +3,7 @@ package txsim
 import (
 	"context"
 	"fmt"
+	"math"
 	"math/rand"
 
 	ns "github.com/celestiaorg/celestia-app/pkg/namespace"
@@ -12,16 +13,26 @@ import (
 	"github.com/gogo/protobuf/grpc"
 )
 
-var _ Sequence = &BlobSequence{}
+var _ AsyncSequence = &BlobSequence{}
 
 // As napkin math, this would cover the cost of 8267 4KB blobs
 const fundsForGas int = 1e9 // 1000 TIA
 
+// gasFunds returns the funds an account needs to pay for its transactions. If
+// the master account pays for the fees through a fee grant, a minimal balance
+// suffices for the account to exist.
+func gasFunds(useFeegrant bool) int {
+	if useFeegrant {
+		return 1
+	}
+	return fundsForGas
+}
+
 // BlobSequence defines a pattern whereby a single user repeatedly sends a pay for blob
 // message roughly every height. The PFB may consist of several blobs
 type BlobSequence struct {
 	namespace   ns.Namespace
-	sizes       Range
+	sizes       Distribution
 	blobsPerPFB Range
 
 	account     types.AccAddress
@@ -42,6 +53,13 @@ func (s *BlobSequence) WithNamespace(namespace ns.Namespace) *BlobSequence {
 	return s
 }
 
+// WithSizeDistribution replaces the uniform distribution of blob sizes with
+// the provided distribution.
+func (s *BlobSequence) WithSizeDistribution(sizes Distribution) *BlobSequence {
+	s.sizes = sizes
+	return s
+}
+
 func (s *BlobSequence) Clone(n int) []Sequence {
 	sequenceGroup := make([]Sequence, n)
 	for i := 0; i < n; i++ {
@@ -95,6 +113,22 @@ func (s *BlobSequence) Next(_ context.Context, _ grpc.ClientConn, rand *rand.Ran
 	}, nil
 }
 
+// Confirm implements AsyncSequence. The operations of a blob sequence don't
+// depend on the outcome of previous operations.
+func (s *BlobSequence) Confirm(Operation, error) {}
+
+// Distribution samples random integers, for example blob sizes.
+type Distribution interface {
+	Rand(rand *rand.Rand) int
+}
+
+var (
+	_ Distribution = Range{}
+	_ Distribution = NormalDistribution{}
+	_ Distribution = ExponentialDistribution{}
+)
+
+// Range is a uniform distribution of integers.
 type Range struct {
 	Min int
 	Max int
@@ -127,3 +161,38 @@ func estimateGas(blobSizes []int, useFeegrant bool) uint64 {
 
 	return blob.DefaultEstimateGas(size) + extra
 }
+
+// NormalDistribution samples from a normal distribution with the provided mean
+// and standard deviation. Samples are clamped to the bounds of the range.
+type NormalDistribution struct {
+	Bounds Range
+	Mean   float64
+	StdDev float64
+}
+
+func (d NormalDistribution) Rand(rand *rand.Rand) int {
+	return d.Bounds.clamp(int(math.Round(rand.NormFloat64()*d.StdDev + d.Mean)))
+}
+
+// ExponentialDistribution samples from an exponential distribution with the
+// provided mean, such that small values are common and large values are rare.
+// Samples are clamped to the bounds of the range.
+type ExponentialDistribution struct {
+	Bounds Range
+	Mean   float64
+}
+
+func (d ExponentialDistribution) Rand(rand *rand.Rand) int {
+	return d.Bounds.clamp(int(math.Round(rand.ExpFloat64() * d.Mean)))
+}
+
+// clamp returns n limited to min (inclusive) and max (inclusive).
+func (r Range) clamp(n int) int {
+	if n < r.Min {
+		return r.Min
+	}
+	if r.Max >= r.Min && n > r.Max {
+		return r.Max
+	}
+	return n
+}
@@ -0,0 +1,129 @@
+package txsim
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// endpointRetryInterval is how long an endpoint that has gone down is avoided
+// before it is tried again.
+const endpointRetryInterval = 30 * time.Second
+
+// ErrNoHealthyEndpoint is returned when all endpoints are down.
+var ErrNoHealthyEndpoint = errors.New("no healthy endpoint")
+
+// Endpoint is a gRPC connection to a node.
+type Endpoint struct {
+	Address string
+	Conn    *grpc.ClientConn
+}
+
+// endpointPool spreads accounts across endpoints and avoids endpoints that
+// have gone down. Queries made through the pool fail over to the next healthy
+// endpoint.
+type endpointPool struct {
+	endpoints []Endpoint
+
+	mtx       sync.Mutex
+	downUntil map[string]time.Time
+	next      int
+}
+
+var _ grpc.ClientConnInterface = &endpointPool{}
+
+func newEndpointPool(endpoints []Endpoint) *endpointPool {
+	return &endpointPool{
+		endpoints: endpoints,
+		downUntil: make(map[string]time.Time),
+	}
+}
+
+// assign returns the next healthy endpoint in a round robin fashion.
+func (p *endpointPool) assign() (Endpoint, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	for i := 0; i < len(p.endpoints); i++ {
+		endpoint := p.endpoints[p.next]
+		p.next = (p.next + 1) % len(p.endpoints)
+		if p.isHealthy(endpoint) {
+			return endpoint, nil
+		}
+	}
+	return Endpoint{}, ErrNoHealthyEndpoint
+}
+
+// healthy returns the healthy endpoints in order of preference.
+func (p *endpointPool) healthy() []Endpoint {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	healthy := make([]Endpoint, 0, len(p.endpoints))
+	for _, endpoint := range p.endpoints {
+		if p.isHealthy(endpoint) {
+			healthy = append(healthy, endpoint)
+		}
+	}
+	return healthy
+}
+
+// markDown avoids the endpoint for the endpointRetryInterval.
+func (p *endpointPool) markDown(endpoint Endpoint) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if !p.isHealthy(endpoint) {
+		return
+	}
+	p.downUntil[endpoint.Address] = time.Now().Add(endpointRetryInterval)
+	log.Warn().Str("endpoint", endpoint.Address).Msg("endpoint is down")
+}
+
+func (p *endpointPool) isHealthy(endpoint Endpoint) bool {
+	return time.Now().After(p.downUntil[endpoint.Address])
+}
+
+// Invoke implements grpc.ClientConnInterface. The call is made against the
+// first healthy endpoint and retried against the next endpoint if the
+// endpoint is unavailable.
+func (p *endpointPool) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	err := ErrNoHealthyEndpoint
+	for _, endpoint := range p.healthy() {
+		err = endpoint.Conn.Invoke(ctx, method, args, reply, opts...)
+		if !isUnavailable(err) {
+			return err
+		}
+		p.markDown(endpoint)
+	}
+	return err
+}
+
+// NewStream implements grpc.ClientConnInterface. Only the creation of the
+// stream fails over to the next endpoint.
+func (p *endpointPool) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	err := ErrNoHealthyEndpoint
+	for _, endpoint := range p.healthy() {
+		var stream grpc.ClientStream
+		stream, err = endpoint.Conn.NewStream(ctx, desc, method, opts...)
+		if !isUnavailable(err) {
+			return stream, err
+		}
+		p.markDown(endpoint)
+	}
+	return nil, err
+}
+
+// primary returns the first endpoint.
+func (p *endpointPool) primary() Endpoint {
+	return p.endpoints[0]
+}
+
+// isUnavailable returns whether the error indicates that the endpoint can't
+// be reached.
+func isUnavailable(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
@@ -0,0 +1,67 @@
+package txsim
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestEndpointPoolAssign(t *testing.T) {
+	endpoints := []Endpoint{{Address: "a"}, {Address: "b"}, {Address: "c"}}
+	pool := newEndpointPool(endpoints)
+
+	// accounts are spread across the endpoints
+	for i := 0; i < 6; i++ {
+		endpoint, err := pool.assign()
+		require.NoError(t, err)
+		assert.Equal(t, endpoints[i%3].Address, endpoint.Address)
+	}
+
+	// endpoints that are down are skipped
+	pool.markDown(endpoints[1])
+	for _, expected := range []string{"a", "c", "a", "c"} {
+		endpoint, err := pool.assign()
+		require.NoError(t, err)
+		assert.Equal(t, expected, endpoint.Address)
+	}
+	assert.Equal(t, []Endpoint{endpoints[0], endpoints[2]}, pool.healthy())
+
+	pool.markDown(endpoints[0])
+	pool.markDown(endpoints[2])
+	_, err := pool.assign()
+	require.ErrorIs(t, err, ErrNoHealthyEndpoint)
+
+	// endpoints are retried after the retry interval
+	pool.downUntil["b"] = time.Now().Add(-time.Second)
+	endpoint, err := pool.assign()
+	require.NoError(t, err)
+	assert.Equal(t, "b", endpoint.Address)
+}
+
+func TestEndpointPoolFailover(t *testing.T) {
+	endpoints := make([]Endpoint, 2)
+	for i, address := range []string{"127.0.0.1:1", "127.0.0.1:2"} {
+		conn, err := grpc.Dial(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		endpoints[i] = Endpoint{Address: address, Conn: conn}
+	}
+	pool := newEndpointPool(endpoints)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	// both endpoints are unreachable so the query fails over to the second
+	// endpoint before failing
+	_, err := tmservice.NewServiceClient(pool).GetLatestBlock(ctx, &tmservice.GetLatestBlockRequest{})
+	require.True(t, isUnavailable(err), err)
+	assert.Empty(t, pool.healthy())
+
+	_, err = tmservice.NewServiceClient(pool).GetLatestBlock(ctx, &tmservice.GetLatestBlockRequest{})
+	require.ErrorIs(t, err, ErrNoHealthyEndpoint)
+}
@@ -0,0 +1,82 @@
+package txsim
+
+import (
+	"context"
+	"math/rand"
+
+	"github.com/celestiaorg/celestia-app/pkg/appconsts"
+	"github.com/cosmos/cosmos-sdk/types"
+	bank "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
+	"github.com/gogo/protobuf/grpc"
+)
+
+var _ Sequence = &FeegrantSequence{}
+
+// FeegrantSequence sets up an endless sequence whereby a granter grants a fee
+// allowance to a grantee which then repeatedly sends a single utia back to
+// the granter with the fees paid by the granter. Occasionally the granter
+// revokes the allowance and grants it again.
+type FeegrantSequence struct {
+	spendLimit        int
+	revokeProbability int
+
+	granter types.AccAddress
+	grantee types.AccAddress
+	granted bool
+}
+
+func NewFeegrantSequence(spendLimit int) *FeegrantSequence {
+	return &FeegrantSequence{
+		spendLimit:        spendLimit,
+		revokeProbability: 20, // 1 in every 20
+	}
+}
+
+func (s *FeegrantSequence) Clone(n int) []Sequence {
+	sequenceGroup := make([]Sequence, n)
+	for i := 0; i < n; i++ {
+		sequenceGroup[i] = NewFeegrantSequence(s.spendLimit)
+	}
+	return sequenceGroup
+}
+
+// Init funds the granter with the fees of the grantee. The grantee only needs
+// the funds that it sends to the granter.
+func (s *FeegrantSequence) Init(_ context.Context, _ grpc.ClientConn, allocateAccounts AccountAllocator, _ *rand.Rand, useFeegrant bool) {
+	s.granter = allocateAccounts(1, s.spendLimit+gasFunds(useFeegrant))[0]
+	s.grantee = allocateAccounts(1, 1000)[0]
+}
+
+func (s *FeegrantSequence) Next(_ context.Context, _ grpc.ClientConn, rand *rand.Rand) (Operation, error) {
+	if !s.granted {
+		allowance := &feegrant.BasicAllowance{
+			SpendLimit: types.NewCoins(types.NewInt64Coin(appconsts.BondDenom, int64(s.spendLimit))),
+		}
+		msg, err := feegrant.NewMsgGrantAllowance(allowance, s.granter, s.grantee)
+		if err != nil {
+			return Operation{}, err
+		}
+		s.granted = true
+		return Operation{
+			Msgs:     []types.Msg{msg},
+			GasLimit: FeegrantGasLimit,
+		}, nil
+	}
+
+	// occasionally revoke the allowance. It is granted again with the next
+	// operation
+	if rand.Intn(s.revokeProbability) == 0 {
+		msg := feegrant.NewMsgRevokeAllowance(s.granter, s.grantee)
+		s.granted = false
+		return Operation{Msgs: []types.Msg{&msg}}, nil
+	}
+
+	return Operation{
+		Msgs: []types.Msg{
+			bank.NewMsgSend(s.grantee, s.granter, types.NewCoins(types.NewInt64Coin(appconsts.BondDenom, 1))),
+		},
+		Delay:      uint64(rand.Int63n(5)),
+		FeeGranter: s.granter,
+	}, nil
+}
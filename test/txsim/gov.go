@@ -0,0 +1,126 @@
+package txsim
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"github.com/celestiaorg/celestia-app/pkg/appconsts"
+	"github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	bank "github.com/cosmos/cosmos-sdk/x/bank/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+	gov "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
+	"github.com/gogo/protobuf/grpc"
+)
+
+var _ Sequence = &GovSequence{}
+
+// GovSequence sets up a sequence whereby a proposer submits a proposal with
+// the minimum deposit, after which each of the voters casts a random vote.
+// This is repeated for the provided number of proposals. The proposals only
+// transfer a single utia from the gov module to the proposer.
+type GovSequence struct {
+	numVoters    int
+	numProposals int
+
+	proposer types.AccAddress
+	voters   []types.AccAddress
+	deposit  types.Coins
+	initErr  error
+
+	// proposalID is the ID of the last submitted proposal or 0 if it has not
+	// been queried yet.
+	proposalID uint64
+	submitted  int
+	votes      int
+}
+
+func NewGovSequence(numVoters, numProposals int) *GovSequence {
+	return &GovSequence{
+		numVoters:    numVoters,
+		numProposals: numProposals,
+	}
+}
+
+func (s *GovSequence) Clone(n int) []Sequence {
+	sequenceGroup := make([]Sequence, n)
+	for i := 0; i < n; i++ {
+		sequenceGroup[i] = NewGovSequence(s.numVoters, s.numProposals)
+	}
+	return sequenceGroup
+}
+
+// Init queries the minimum deposit and funds the proposer with the deposits
+// of all proposals.
+func (s *GovSequence) Init(ctx context.Context, querier grpc.ClientConn, allocateAccounts AccountAllocator, _ *rand.Rand, useFeegrant bool) {
+	resp, err := gov.NewQueryClient(querier).Params(ctx, &gov.QueryParamsRequest{ParamsType: gov.ParamDeposit})
+	if err != nil {
+		s.initErr = fmt.Errorf("querying deposit params: %w", err)
+		return
+	}
+	minDeposit := types.Coins(resp.DepositParams.MinDeposit).AmountOf(appconsts.BondDenom)
+	s.deposit = types.NewCoins(types.NewCoin(appconsts.BondDenom, minDeposit))
+	s.proposer = allocateAccounts(1, int(minDeposit.Int64())*s.numProposals+gasFunds(useFeegrant))[0]
+	if s.numVoters > 0 {
+		s.voters = allocateAccounts(s.numVoters, gasFunds(useFeegrant))
+	}
+}
+
+func (s *GovSequence) Next(ctx context.Context, querier grpc.ClientConn, rand *rand.Rand) (Operation, error) {
+	if s.initErr != nil {
+		return Operation{}, s.initErr
+	}
+
+	// submit the next proposal once all voters have voted on the last one
+	if s.submitted == 0 || s.votes >= s.numVoters {
+		if s.submitted >= s.numProposals {
+			return Operation{}, ErrEndOfSequence
+		}
+		authority := authtypes.NewModuleAddress(govtypes.ModuleName)
+		transfer := bank.NewMsgSend(authority, s.proposer, types.NewCoins(types.NewInt64Coin(appconsts.BondDenom, 1)))
+		msg, err := gov.NewMsgSubmitProposal([]types.Msg{transfer}, s.deposit, s.proposer.String(), "txsim")
+		if err != nil {
+			return Operation{}, err
+		}
+		s.submitted++
+		s.votes = 0
+		s.proposalID = 0
+		return Operation{Msgs: []types.Msg{msg}}, nil
+	}
+
+	if s.proposalID == 0 {
+		id, err := s.latestProposal(ctx, querier)
+		if err != nil {
+			return Operation{}, err
+		}
+		s.proposalID = id
+	}
+
+	options := []gov.VoteOption{gov.OptionYes, gov.OptionNo, gov.OptionAbstain, gov.OptionNoWithVeto}
+	vote := gov.NewMsgVote(s.voters[s.votes], s.proposalID, options[rand.Intn(len(options))], "")
+	s.votes++
+	return Operation{
+		Msgs:  []types.Msg{vote},
+		Delay: uint64(rand.Int63n(2)),
+	}, nil
+}
+
+// latestProposal returns the ID of the last proposal deposited to by the
+// proposer.
+func (s *GovSequence) latestProposal(ctx context.Context, querier grpc.ClientConn) (uint64, error) {
+	resp, err := gov.NewQueryClient(querier).Proposals(ctx, &gov.QueryProposalsRequest{Depositor: s.proposer.String()})
+	if err != nil {
+		return 0, fmt.Errorf("querying proposals: %w", err)
+	}
+	var id uint64
+	for _, proposal := range resp.Proposals {
+		if proposal.Id > id {
+			id = proposal.Id
+		}
+	}
+	if id == 0 {
+		return 0, fmt.Errorf("no proposal found for proposer %s", s.proposer)
+	}
+	return id, nil
+}
@@ -0,0 +1,535 @@
+package txsim
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	errorsmod "cosmossdk.io/errors"
+	blobtypes "github.com/celestiaorg/celestia-app/x/blob/types"
+	"github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Classes of errors that are distinguished when recording failed operations.
+const (
+	ErrClassNonceMismatch   = "nonce_mismatch"
+	ErrClassInsufficientFee = "insufficient_fee"
+	ErrClassTooLarge        = "too_large"
+	ErrClassOutOfGas        = "out_of_gas"
+	ErrClassTimeout         = "timeout"
+	ErrClassMempoolFull     = "mempool_full"
+	ErrClassOther           = "other"
+)
+
+// Outcomes of adversarial transactions.
+const (
+	// AdversarialRejected means the node rejected the transaction with one
+	// of the expected errors.
+	AdversarialRejected = "rejected"
+	// AdversarialAccepted means the node accepted the transaction.
+	AdversarialAccepted = "accepted"
+	// AdversarialUnexpectedError means the node rejected the transaction
+	// with an error that was not expected.
+	AdversarialUnexpectedError = "unexpected_error"
+)
+
+const metricsNamespace = "txsim"
+
+// maxLatencySamples is the number of durations of each step that are kept to
+// estimate the percentiles of the run summary.
+const maxLatencySamples = 10000
+
+// Metrics records the latency, failures and throughput of the operations
+// submitted by txsim. Metrics are exposed to Prometheus through the Registry
+// and summarized at the end of a run. A nil *Metrics records nothing.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	submitted         prometheus.Counter
+	committed         prometheus.Counter
+	failed            *prometheus.CounterVec
+	blobBytes         prometheus.Counter
+	signDuration      prometheus.Histogram
+	broadcastDuration prometheus.Histogram
+	inclusionDuration prometheus.Histogram
+	inFlight          prometheus.Gauge
+	scheduleLag       prometheus.Gauge
+	backpressure      *prometheus.CounterVec
+	adversarial       *prometheus.CounterVec
+	endpointRequests  *prometheus.CounterVec
+	endpointErrors    *prometheus.CounterVec
+	failovers         *prometheus.CounterVec
+
+	mtx               sync.Mutex
+	start             time.Time
+	submittedCount    int
+	committedCount    int
+	failures          map[string]int
+	signTimes         latencySample
+	broadcastTimes    latencySample
+	inclusionTimes    latencySample
+	blobBytesPerBlock map[int64]int
+	maxInFlight       int
+	maxScheduleLag    time.Duration
+	backpressureCount map[string]int
+	adversarialCount  map[string]int
+	endpointCount     map[string]*EndpointSummary
+}
+
+// NewMetrics creates metrics that are registered on a new Prometheus registry.
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		submitted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "txs_submitted_total",
+			Help:      "Number of transactions submitted.",
+		}),
+		committed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "txs_committed_total",
+			Help:      "Number of transactions committed successfully.",
+		}),
+		failed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "txs_failed_total",
+			Help:      "Number of transactions that failed by class of error.",
+		}, []string{"class"}),
+		blobBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "blob_bytes_committed_total",
+			Help:      "Number of blob bytes committed.",
+		}),
+		signDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "sign_duration_seconds",
+			Help:      "Time to build and sign a transaction.",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 10),
+		}),
+		broadcastDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "broadcast_duration_seconds",
+			Help:      "Time to broadcast a transaction until it is accepted by the mempool.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 3, 10),
+		}),
+		inclusionDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "inclusion_duration_seconds",
+			Help:      "Time from the broadcast of a transaction until it is committed.",
+			Buckets:   prometheus.ExponentialBuckets(0.25, 2, 10),
+		}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "txs_in_flight",
+			Help:      "Number of transactions broadcast but not yet confirmed in open-loop mode.",
+		}),
+		scheduleLag: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "schedule_lag_seconds",
+			Help:      "How far the submission of transactions lags behind the target rate in open-loop mode.",
+		}),
+		backpressure: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "backpressure_total",
+			Help:      "Number of times the target rate could not be maintained by reason.",
+		}, []string{"reason"}),
+		adversarial: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "adversarial_txs_total",
+			Help:      "Number of adversarial transactions by outcome.",
+		}, []string{"outcome"}),
+		endpointRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "endpoint_broadcasts_total",
+			Help:      "Number of transactions broadcast by endpoint.",
+		}, []string{"endpoint"}),
+		endpointErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "endpoint_errors_total",
+			Help:      "Number of broadcasts that failed with a gRPC error by endpoint.",
+		}, []string{"endpoint"}),
+		failovers: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "endpoint_failovers_total",
+			Help:      "Number of accounts that failed over from an endpoint that went down.",
+		}, []string{"endpoint"}),
+		start:             time.Now(),
+		failures:          make(map[string]int),
+		blobBytesPerBlock: make(map[int64]int),
+		backpressureCount: make(map[string]int),
+		adversarialCount:  make(map[string]int),
+		endpointCount:     make(map[string]*EndpointSummary),
+	}
+	m.registry.MustRegister(
+		m.submitted,
+		m.committed,
+		m.failed,
+		m.blobBytes,
+		m.signDuration,
+		m.broadcastDuration,
+		m.inclusionDuration,
+		m.inFlight,
+		m.scheduleLag,
+		m.backpressure,
+		m.adversarial,
+		m.endpointRequests,
+		m.endpointErrors,
+		m.failovers,
+	)
+	return m
+}
+
+// Registry returns the Prometheus registry the metrics are registered on.
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+func (m *Metrics) observeSign(d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.signDuration.Observe(d.Seconds())
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.signTimes.add(d)
+}
+
+func (m *Metrics) observeBroadcast(d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.submitted.Inc()
+	m.broadcastDuration.Observe(d.Seconds())
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.submittedCount++
+	m.broadcastTimes.add(d)
+}
+
+// observeCommit records a committed transaction including blobBytes bytes of
+// blobs at height.
+func (m *Metrics) observeCommit(d time.Duration, height int64, blobBytes int) {
+	if m == nil {
+		return
+	}
+	m.committed.Inc()
+	m.inclusionDuration.Observe(d.Seconds())
+	m.blobBytes.Add(float64(blobBytes))
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.committedCount++
+	m.inclusionTimes.add(d)
+	if blobBytes > 0 {
+		m.blobBytesPerBlock[height] += blobBytes
+	}
+}
+
+func (m *Metrics) observeFailure(resp *types.TxResponse, err error) {
+	if m == nil {
+		return
+	}
+	class := classifyError(resp, err)
+	m.failed.WithLabelValues(class).Inc()
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.failures[class]++
+}
+
+func (m *Metrics) observeInFlight(n int) {
+	if m == nil {
+		return
+	}
+	m.inFlight.Set(float64(n))
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if n > m.maxInFlight {
+		m.maxInFlight = n
+	}
+}
+
+func (m *Metrics) observeScheduleLag(lag time.Duration) {
+	if m == nil {
+		return
+	}
+	m.scheduleLag.Set(lag.Seconds())
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if lag > m.maxScheduleLag {
+		m.maxScheduleLag = lag
+	}
+}
+
+func (m *Metrics) observeBackpressure(reason string) {
+	if m == nil {
+		return
+	}
+	m.backpressure.WithLabelValues(reason).Inc()
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.backpressureCount[reason]++
+}
+
+func (m *Metrics) observeAdversarial(outcome string) {
+	if m == nil {
+		return
+	}
+	m.adversarial.WithLabelValues(outcome).Inc()
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.adversarialCount[outcome]++
+}
+
+// observeEndpoint records a broadcast to the endpoint. err is the gRPC error
+// of the broadcast, if any.
+func (m *Metrics) observeEndpoint(endpoint string, err error) {
+	if m == nil {
+		return
+	}
+	m.endpointRequests.WithLabelValues(endpoint).Inc()
+	if err != nil {
+		m.endpointErrors.WithLabelValues(endpoint).Inc()
+	}
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	summary := m.endpointSummary(endpoint)
+	summary.Broadcasts++
+	if err != nil {
+		summary.Errors++
+	}
+}
+
+// observeFailover records an account failing over from the endpoint.
+func (m *Metrics) observeFailover(endpoint string) {
+	if m == nil {
+		return
+	}
+	m.failovers.WithLabelValues(endpoint).Inc()
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.endpointSummary(endpoint).Failovers++
+}
+
+// endpointSummary returns the summary of the endpoint. The caller must hold
+// the lock.
+func (m *Metrics) endpointSummary(endpoint string) *EndpointSummary {
+	summary, ok := m.endpointCount[endpoint]
+	if !ok {
+		summary = &EndpointSummary{}
+		m.endpointCount[endpoint] = summary
+	}
+	return summary
+}
+
+// MetricsSummary summarizes the metrics of a run. Durations are in seconds.
+type MetricsSummary struct {
+	Duration           float64          `json:"duration_seconds"`
+	Submitted          int              `json:"submitted"`
+	Committed          int              `json:"committed"`
+	Failures           map[string]int   `json:"failures"`
+	TxsPerSecond       float64          `json:"txs_per_second"`
+	BlobBytesPerSecond float64          `json:"blob_bytes_per_second"`
+	Sign               LatencySummary   `json:"sign"`
+	Broadcast          LatencySummary   `json:"broadcast"`
+	Inclusion          LatencySummary   `json:"inclusion"`
+	BlobBytesPerBlock  map[int64]int    `json:"blob_bytes_per_block"`
+	BlobBytesInBlocks  DistributionInfo `json:"blob_bytes_in_blocks"`
+	// The following are only recorded in open-loop mode.
+	MaxInFlight    int            `json:"max_in_flight"`
+	MaxScheduleLag float64        `json:"max_schedule_lag_seconds"`
+	Backpressure   map[string]int `json:"backpressure"`
+	// Adversarial counts the outcomes of adversarial transactions.
+	Adversarial map[string]int `json:"adversarial"`
+	// Endpoints summarizes the broadcasts by endpoint.
+	Endpoints map[string]EndpointSummary `json:"endpoints"`
+}
+
+// EndpointSummary describes the broadcasts to an endpoint.
+type EndpointSummary struct {
+	Broadcasts int     `json:"broadcasts"`
+	Errors     int     `json:"errors"`
+	ErrorRate  float64 `json:"error_rate"`
+	Failovers  int     `json:"failovers"`
+}
+
+// LatencySummary describes the distribution of the durations of a step of
+// submitting transactions in seconds.
+type LatencySummary struct {
+	Count int     `json:"count"`
+	Mean  float64 `json:"mean"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P99   float64 `json:"p99"`
+	Max   float64 `json:"max"`
+}
+
+// DistributionInfo describes the distribution of a quantity across blocks.
+type DistributionInfo struct {
+	Blocks int     `json:"blocks"`
+	Mean   float64 `json:"mean"`
+	Max    int     `json:"max"`
+}
+
+// Summary returns a summary of the metrics recorded since they were created.
+func (m *Metrics) Summary() MetricsSummary {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	elapsed := time.Since(m.start).Seconds()
+	summary := MetricsSummary{
+		Duration:          elapsed,
+		Submitted:         m.submittedCount,
+		Committed:         m.committedCount,
+		Failures:          make(map[string]int, len(m.failures)),
+		Sign:              m.signTimes.summary(),
+		Broadcast:         m.broadcastTimes.summary(),
+		Inclusion:         m.inclusionTimes.summary(),
+		BlobBytesPerBlock: make(map[int64]int, len(m.blobBytesPerBlock)),
+		MaxInFlight:       m.maxInFlight,
+		MaxScheduleLag:    m.maxScheduleLag.Seconds(),
+		Backpressure:      make(map[string]int, len(m.backpressureCount)),
+		Adversarial:       make(map[string]int, len(m.adversarialCount)),
+		Endpoints:         make(map[string]EndpointSummary, len(m.endpointCount)),
+	}
+	for class, count := range m.failures {
+		summary.Failures[class] = count
+	}
+	for reason, count := range m.backpressureCount {
+		summary.Backpressure[reason] = count
+	}
+	for outcome, count := range m.adversarialCount {
+		summary.Adversarial[outcome] = count
+	}
+	for endpoint, counts := range m.endpointCount {
+		endpointSummary := *counts
+		if counts.Broadcasts > 0 {
+			endpointSummary.ErrorRate = float64(counts.Errors) / float64(counts.Broadcasts)
+		}
+		summary.Endpoints[endpoint] = endpointSummary
+	}
+
+	totalBlobBytes := 0
+	for height, bytes := range m.blobBytesPerBlock {
+		summary.BlobBytesPerBlock[height] = bytes
+		totalBlobBytes += bytes
+		if bytes > summary.BlobBytesInBlocks.Max {
+			summary.BlobBytesInBlocks.Max = bytes
+		}
+	}
+	summary.BlobBytesInBlocks.Blocks = len(m.blobBytesPerBlock)
+	if len(m.blobBytesPerBlock) > 0 {
+		summary.BlobBytesInBlocks.Mean = float64(totalBlobBytes) / float64(len(m.blobBytesPerBlock))
+	}
+	if elapsed > 0 {
+		summary.TxsPerSecond = float64(m.committedCount) / elapsed
+		summary.BlobBytesPerSecond = float64(totalBlobBytes) / elapsed
+	}
+	return summary
+}
+
+// latencySample records the count, total and maximum of all durations of a
+// step and keeps a uniform random sample of at most maxLatencySamples of them
+// from which the percentiles are estimated.
+type latencySample struct {
+	count   int
+	total   time.Duration
+	max     time.Duration
+	samples []time.Duration
+}
+
+func (s *latencySample) add(d time.Duration) {
+	s.count++
+	s.total += d
+	if d > s.max {
+		s.max = d
+	}
+	if len(s.samples) < maxLatencySamples {
+		s.samples = append(s.samples, d)
+		return
+	}
+	// reservoir sampling keeps every duration with the same probability
+	if i := rand.Intn(s.count); i < maxLatencySamples {
+		s.samples[i] = d
+	}
+}
+
+func (s *latencySample) summary() LatencySummary {
+	if s.count == 0 {
+		return LatencySummary{}
+	}
+	sorted := make([]time.Duration, len(s.samples))
+	copy(sorted, s.samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	percentile := func(p float64) float64 {
+		idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+		if idx < 0 {
+			idx = 0
+		}
+		return sorted[idx].Seconds()
+	}
+	return LatencySummary{
+		Count: s.count,
+		Mean:  (s.total / time.Duration(s.count)).Seconds(),
+		P50:   percentile(0.5),
+		P90:   percentile(0.9),
+		P99:   percentile(0.99),
+		Max:   s.max.Seconds(),
+	}
+}
+
+// errorClasses maps the registered errors of failed transactions to their
+// class.
+var errorClasses = []struct {
+	err   *errorsmod.Error
+	class string
+}{
+	{sdkerrors.ErrWrongSequence, ErrClassNonceMismatch},
+	{sdkerrors.ErrInsufficientFee, ErrClassInsufficientFee},
+	{sdkerrors.ErrTxTooLarge, ErrClassTooLarge},
+	{blobtypes.ErrTotalBlobSizeTooLarge, ErrClassTooLarge},
+	{sdkerrors.ErrOutOfGas, ErrClassOutOfGas},
+	{sdkerrors.ErrTxTimeoutHeight, ErrClassTimeout},
+	{sdkerrors.ErrMempoolIsFull, ErrClassMempoolFull},
+}
+
+// classifyError returns the class of the error of a failed transaction. The
+// ABCI code of the response is preferred, followed by the registered error
+// wrapped by err and the gRPC status code of err.
+func classifyError(resp *types.TxResponse, err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrClassTimeout
+	}
+	if resp != nil && resp.Code != 0 {
+		for _, c := range errorClasses {
+			if isABCIError(resp, c.err) {
+				return c.class
+			}
+		}
+	}
+	for _, c := range errorClasses {
+		if errors.Is(err, c.err) {
+			return c.class
+		}
+	}
+	switch status.Code(err) {
+	case codes.DeadlineExceeded:
+		return ErrClassTimeout
+	case codes.ResourceExhausted:
+		// the transaction exceeds the maximum message size of the endpoint
+		return ErrClassTooLarge
+	default:
+		return ErrClassOther
+	}
+}
+
+func isABCIError(resp *types.TxResponse, err *errorsmod.Error) bool {
+	return resp.Codespace == err.Codespace() && resp.Code == err.ABCICode()
+}
@@ -0,0 +1,88 @@
+package txsim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	errorsmod "cosmossdk.io/errors"
+	blobtypes "github.com/celestiaorg/celestia-app/x/blob/types"
+	"github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestClassifyError(t *testing.T) {
+	response := func(codespace string, code uint32) *types.TxResponse {
+		return &types.TxResponse{Codespace: codespace, Code: code}
+	}
+	testCases := []struct {
+		name string
+		resp *types.TxResponse
+		err  error
+		want string
+	}{
+		{"wrong sequence", response(sdkerrors.RootCodespace, sdkerrors.ErrWrongSequence.ABCICode()), errors.New("tx failed"), ErrClassNonceMismatch},
+		{"insufficient fee", response(sdkerrors.RootCodespace, sdkerrors.ErrInsufficientFee.ABCICode()), errors.New("tx failed"), ErrClassInsufficientFee},
+		{"blob too large", response(blobtypes.ModuleName, blobtypes.ErrTotalBlobSizeTooLarge.ABCICode()), errors.New("tx failed"), ErrClassTooLarge},
+		{"out of gas", response(sdkerrors.RootCodespace, sdkerrors.ErrOutOfGas.ABCICode()), errors.New("tx failed"), ErrClassOutOfGas},
+		{"context deadline", nil, fmt.Errorf("confirming: %w", context.DeadlineExceeded), ErrClassTimeout},
+		{"grpc deadline", nil, status.Error(codes.DeadlineExceeded, "deadline"), ErrClassTimeout},
+		{"wrapped wrong sequence", nil, fmt.Errorf("broadcast: %w", errorsmod.ABCIError(sdkerrors.RootCodespace, sdkerrors.ErrWrongSequence.ABCICode(), "account sequence mismatch")), ErrClassNonceMismatch},
+		{"mempool full", nil, errorsmod.Wrap(sdkerrors.ErrMempoolIsFull, "tx"), ErrClassMempoolFull},
+		{"grpc message too large", nil, status.Error(codes.ResourceExhausted, "message larger than max"), ErrClassTooLarge},
+		{"message is not classified", nil, errors.New("account sequence mismatch, expected 2, got 1"), ErrClassOther},
+		{"unknown", response("other", 1), errors.New("something"), ErrClassOther},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, classifyError(tc.resp, tc.err))
+		})
+	}
+}
+
+func TestMetricsSummary(t *testing.T) {
+	m := NewMetrics()
+	for i := 1; i <= 10; i++ {
+		m.observeSign(time.Millisecond)
+		m.observeBroadcast(10 * time.Millisecond)
+		m.observeCommit(time.Duration(i)*time.Second, int64(i%2+1), 100)
+	}
+	m.observeFailure(nil, context.DeadlineExceeded)
+
+	summary := m.Summary()
+	require.Equal(t, 10, summary.Submitted)
+	require.Equal(t, 10, summary.Committed)
+	assert.Equal(t, map[string]int{ErrClassTimeout: 1}, summary.Failures)
+	assert.Equal(t, map[int64]int{1: 500, 2: 500}, summary.BlobBytesPerBlock)
+	assert.Equal(t, DistributionInfo{Blocks: 2, Mean: 500, Max: 500}, summary.BlobBytesInBlocks)
+	assert.Equal(t, 5.5, summary.Inclusion.Mean)
+	assert.Equal(t, 5.0, summary.Inclusion.P50)
+	assert.Equal(t, 9.0, summary.Inclusion.P90)
+	assert.Equal(t, 10.0, summary.Inclusion.Max)
+	assert.Equal(t, 0.001, summary.Sign.P99)
+
+	// the count, mean and maximum cover all durations while the percentiles
+	// are estimated from a bounded sample
+	for i := 0; i < 2*maxLatencySamples; i++ {
+		m.observeSign(time.Millisecond)
+	}
+	summary = m.Summary()
+	assert.Equal(t, 2*maxLatencySamples+10, summary.Sign.Count)
+	assert.Equal(t, 0.001, summary.Sign.Mean)
+	assert.Len(t, m.signTimes.samples, maxLatencySamples)
+
+	families, err := m.Registry().Gather()
+	require.NoError(t, err)
+	assert.NotEmpty(t, families)
+
+	// a nil metrics records nothing
+	var nilMetrics *Metrics
+	nilMetrics.observeCommit(time.Second, 1, 1)
+	nilMetrics.observeFailure(nil, errors.New("error"))
+}
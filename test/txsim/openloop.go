@@ -0,0 +1,375 @@
+package txsim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/celestiaorg/celestia-app/pkg/user"
+	"github.com/rs/zerolog/log"
+)
+
+// Reasons for which the target rate of the open loop could not be maintained.
+const (
+	// BackpressureMaxInFlight means the maximum number of unconfirmed
+	// transactions has been reached.
+	BackpressureMaxInFlight = "max_in_flight"
+	// BackpressureBehindSchedule means transactions could not be signed and
+	// broadcast fast enough to keep up with the target rate.
+	BackpressureBehindSchedule = "behind_schedule"
+	// BackpressureMempoolFull means the node rejected a transaction because
+	// its mempool is full.
+	BackpressureMempoolFull = "mempool_full"
+)
+
+const (
+	// DefaultMaxInFlight is the default maximum number of transactions that
+	// have been broadcast but not yet confirmed in open-loop mode.
+	DefaultMaxInFlight = 1000
+
+	// behindScheduleThreshold is the lag after which a submission is
+	// reported as being behind schedule.
+	behindScheduleThreshold = time.Second
+	// backpressureLogInterval limits how often backpressure is logged.
+	backpressureLogInterval = 10 * time.Second
+)
+
+// AsyncSequence is a Sequence whose operations may be submitted before the
+// previous operations of the sequence have been committed. In open-loop mode
+// the sequence is notified once each of its operations has been committed or
+// has failed. Sequences that don't implement AsyncSequence have at most one
+// operation in flight at a time.
+type AsyncSequence interface {
+	Sequence
+
+	// Confirm is called with the result of an operation returned by Next. It
+	// is never called concurrently with Next.
+	Confirm(op Operation, err error)
+}
+
+// asyncSequence returns the sequence as an AsyncSequence if it, or the
+// sequence it wraps, supports asynchronous confirmation.
+func asyncSequence(sequence Sequence) (AsyncSequence, bool) {
+	if wrapper, ok := sequence.(interface{ Unwrap() Sequence }); ok {
+		return asyncSequence(wrapper.Unwrap())
+	}
+	async, ok := sequence.(AsyncSequence)
+	return async, ok
+}
+
+// pacer schedules submissions at a constant rate. Each submission has a
+// cost, for example one transaction or its number of blob bytes.
+type pacer struct {
+	// rate is the cost per second. A rate of zero disables the pacer.
+	rate float64
+	next time.Time
+}
+
+// wait blocks until a submission of cost is due and returns how far the
+// submission lags behind the schedule.
+func (p *pacer) wait(ctx context.Context, cost float64) (time.Duration, error) {
+	if p.rate <= 0 {
+		return 0, nil
+	}
+	now := time.Now()
+	if p.next.IsZero() {
+		p.next = now
+	}
+	due := p.next
+	p.next = p.next.Add(time.Duration(cost / p.rate * float64(time.Second)))
+	if wait := due.Sub(now); wait > 0 {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(wait):
+			return 0, nil
+		}
+	}
+	return now.Sub(due), nil
+}
+
+// openLoop submits operations at a target rate regardless of whether the
+// previous transactions have been committed. Every sequence generates its
+// operations on its own goroutine while a single dispatcher paces them.
+// Transactions are pipelined across accounts: the transactions of an account
+// are signed and broadcast in order by a worker of the account while their
+// confirmations are awaited concurrently.
+type openLoop struct {
+	manager   *AccountManager
+	txPacer   pacer
+	bytePacer pacer
+	seed      int64
+	recorder  *Recorder
+
+	// ready receives the operations of the sequences.
+	ready chan openLoopTx
+	// slots holds a value for every transaction in flight.
+	slots    chan struct{}
+	inFlight sync.WaitGroup
+	workers  map[string]chan openLoopTx
+	wg       sync.WaitGroup
+
+	mtx                 sync.Mutex
+	lastBackpressureLog time.Time
+}
+
+type openLoopTx struct {
+	op     Operation
+	signer *user.Signer
+	seqID  int
+	// offset is the elapsed time of the recording at which the sequence
+	// returned the operation.
+	offset time.Duration
+	// results receives the transaction once it is committed or has failed.
+	results chan<- openLoopTx
+	err     error
+}
+
+func newOpenLoop(manager *AccountManager, opts *Options) *openLoop {
+	return &openLoop{
+		manager:   manager,
+		txPacer:   pacer{rate: opts.txRate},
+		bytePacer: pacer{rate: opts.byteRate},
+		seed:      opts.seed,
+		recorder:  opts.recorder,
+		ready:     make(chan openLoopTx),
+		slots:     make(chan struct{}, opts.maxInFlight),
+		workers:   make(map[string]chan openLoopTx),
+	}
+}
+
+// run submits the operations of the sequences until all sequences have ended
+// and all transactions have been confirmed or until the context is cancelled.
+// Delays of operations are ignored as the pace is set by the target rate.
+func (l *openLoop) run(ctx context.Context, sequences []Sequence) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer l.wg.Wait()
+	defer cancel()
+
+	errCh := make(chan error, len(sequences))
+	var sequencesWg sync.WaitGroup
+	for idx, sequence := range sequences {
+		sequencesWg.Add(1)
+		go func(seqID int, sequence Sequence) {
+			defer sequencesWg.Done()
+			if err := l.runSequence(ctx, seqID, sequence); err != nil {
+				errCh <- fmt.Errorf("sequence %d: %w", seqID, err)
+			}
+		}(idx, sequence)
+	}
+	go func() {
+		sequencesWg.Wait()
+		close(l.ready)
+	}()
+
+	for tx := range l.ready {
+		if err := l.schedule(ctx, tx); err != nil {
+			return err
+		}
+	}
+
+	// wait for the remaining transactions to be confirmed
+	confirmed := make(chan struct{})
+	go func() {
+		l.inFlight.Wait()
+		close(confirmed)
+	}()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-confirmed:
+	}
+
+	close(errCh)
+	var finalErr error
+	for err := range errCh {
+		if errors.Is(err, ErrEndOfSequence) {
+			log.Info().Err(err).Msg("sequence terminated")
+			continue
+		}
+		log.Error().Err(err).Msg("sequence failed")
+		finalErr = err
+	}
+	return finalErr
+}
+
+// runSequence hands the operations of the sequence to the dispatcher. A
+// sequence that doesn't support asynchronous confirmation waits for each of
+// its operations to complete and ends on the first failed operation.
+func (l *openLoop) runSequence(ctx context.Context, seqID int, sequence Sequence) error {
+	r := rand.New(rand.NewSource(l.seed))
+	async, isAsync := asyncSequence(sequence)
+	// the number of transactions in flight limits the number of results
+	results := make(chan openLoopTx, cap(l.slots))
+	for {
+		// notify the sequence of the operations that have completed
+		for drained := false; isAsync && !drained; {
+			select {
+			case tx := <-results:
+				if tx.err != nil {
+					log.Error().Err(tx.err).Msg("operation failed")
+				}
+				async.Confirm(tx.op, tx.err)
+			default:
+				drained = true
+			}
+		}
+
+		op, err := sequence.Next(ctx, l.manager.conn, r)
+		if err != nil {
+			return err
+		}
+		address, err := operationSigner(op)
+		if err != nil {
+			return err
+		}
+		signer, err := l.manager.getSubAccount(address)
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case l.ready <- openLoopTx{op: op, signer: signer, seqID: seqID, offset: l.recorder.elapsed(), results: results}:
+		}
+
+		if isAsync {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case tx := <-results:
+			if tx.err != nil {
+				return tx.err
+			}
+		}
+	}
+}
+
+// schedule waits for the transaction to be due and for a free slot before
+// handing it to the worker of its signer. Only context errors are returned.
+func (l *openLoop) schedule(ctx context.Context, tx openLoopTx) error {
+	txLag, err := l.txPacer.wait(ctx, 1)
+	if err != nil {
+		return err
+	}
+	byteLag, err := l.bytePacer.wait(ctx, float64(blobBytes(tx.op.Blobs)))
+	if err != nil {
+		return err
+	}
+	lag := txLag
+	if byteLag > lag {
+		lag = byteLag
+	}
+	l.manager.metrics.observeScheduleLag(lag)
+	if lag > behindScheduleThreshold {
+		l.reportBackpressure(BackpressureBehindSchedule)
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+	default:
+		l.reportBackpressure(BackpressureMaxInFlight)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case l.slots <- struct{}{}:
+		}
+	}
+	l.inFlight.Add(1)
+	l.manager.metrics.observeInFlight(len(l.slots))
+
+	l.worker(ctx, tx.signer) <- tx
+	return nil
+}
+
+// worker returns the queue of the worker that signs and broadcasts the
+// transactions of the signer in order.
+func (l *openLoop) worker(ctx context.Context, signer *user.Signer) chan<- openLoopTx {
+	queue, ok := l.workers[signer.Address().String()]
+	if ok {
+		return queue
+	}
+	// the number of transactions in flight limits the length of the queue
+	queue = make(chan openLoopTx, cap(l.slots))
+	l.workers[signer.Address().String()] = queue
+
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case tx := <-queue:
+				l.broadcast(ctx, tx)
+			}
+		}
+	}()
+	return queue
+}
+
+// broadcast signs and broadcasts the transaction and awaits its confirmation
+// in the background.
+func (l *openLoop) broadcast(ctx context.Context, tx openLoopTx) {
+	// the signer may have been replaced after failing over to another endpoint
+	signer, err := l.manager.getSubAccount(tx.signer.Address())
+	if err != nil {
+		tx.err = err
+		l.release(tx)
+		return
+	}
+	tx.signer = signer
+
+	// a rejected broadcast restores the sequence number of the signer
+	pending, err := l.manager.broadcast(ctx, tx.signer, tx.op)
+	if err != nil {
+		if classifyError(nil, err) == ErrClassMempoolFull {
+			l.reportBackpressure(BackpressureMempoolFull)
+		}
+		tx.err = err
+		l.release(tx)
+		return
+	}
+
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		_, tx.err = l.manager.confirm(ctx, tx.op, pending)
+		l.release(tx)
+	}()
+}
+
+// release records the transaction if it was committed, frees its slot and
+// returns the result to its sequence.
+func (l *openLoop) release(tx openLoopTx) {
+	if tx.err == nil {
+		if err := l.recorder.record(tx.seqID, tx.op, tx.offset); err != nil {
+			tx.err = fmt.Errorf("recording operation: %w", err)
+		}
+	}
+	<-l.slots
+	l.manager.metrics.observeInFlight(len(l.slots))
+	tx.results <- tx
+	l.inFlight.Done()
+}
+
+func (l *openLoop) reportBackpressure(reason string) {
+	l.manager.metrics.observeBackpressure(reason)
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if time.Since(l.lastBackpressureLog) < backpressureLogInterval {
+		return
+	}
+	l.lastBackpressureLog = time.Now()
+	log.Warn().
+		Str("reason", reason).
+		Int("in_flight", len(l.slots)).
+		Msg("unable to maintain target rate")
+}
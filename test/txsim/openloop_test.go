@@ -0,0 +1,85 @@
+package txsim
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/celestiaorg/celestia-app/app"
+	"github.com/celestiaorg/celestia-app/app/encoding"
+	"github.com/celestiaorg/celestia-app/pkg/appconsts"
+	"github.com/cosmos/cosmos-sdk/types"
+	bank "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacer(t *testing.T) {
+	p := pacer{rate: 100}
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		lag, err := p.wait(context.Background(), 1)
+		require.NoError(t, err)
+		assert.Less(t, lag, 50*time.Millisecond)
+	}
+	// ten submissions at 100 per second take at least 90ms
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+
+	// a pacer that has fallen behind reports the lag
+	p.next = time.Now().Add(-time.Second)
+	lag, err := p.wait(context.Background(), 1)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, lag, time.Second)
+
+	// a pacer without a rate never waits
+	lag, err = (&pacer{}).wait(context.Background(), 1e9)
+	require.NoError(t, err)
+	assert.Zero(t, lag)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p = pacer{rate: 1, next: time.Now().Add(time.Hour)}
+	_, err = p.wait(ctx, 1)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestAsyncSequence(t *testing.T) {
+	blobSequence := NewBlobSequence(NewRange(1, 1), NewRange(1, 1))
+	_, ok := asyncSequence(blobSequence)
+	assert.True(t, ok)
+
+	_, ok = asyncSequence(NewSendSequence(2, 1, 1))
+	assert.False(t, ok)
+
+	async, ok := asyncSequence(&phasedSequence{Sequence: blobSequence})
+	require.True(t, ok)
+	assert.Equal(t, blobSequence, async)
+}
+
+func TestOpenLoopRecordsCommittedTransactions(t *testing.T) {
+	encCfg := encoding.MakeConfig(app.ModuleEncodingRegisters...)
+	var buf bytes.Buffer
+	recorder := NewRecorder(&buf, encCfg)
+	require.NoError(t, recorder.writeHeader(nil, 1, false, nil))
+	l := &openLoop{manager: &AccountManager{}, recorder: recorder, slots: make(chan struct{}, 2)}
+
+	sender := types.AccAddress("sender")
+	op := Operation{Msgs: []types.Msg{bank.NewMsgSend(sender, sender, types.NewCoins(types.NewInt64Coin(appconsts.BondDenom, 1)))}}
+	results := make(chan openLoopTx, 2)
+	for seqID, err := range []error{errors.New("rejected"), nil} {
+		l.slots <- struct{}{}
+		l.inFlight.Add(1)
+		l.release(openLoopTx{op: op, seqID: seqID, offset: time.Second, results: results, err: err})
+		require.Equal(t, err, (<-results).err)
+	}
+	require.NoError(t, recorder.Flush())
+
+	// only the committed transaction is recorded
+	opLog, err := ReadOperationLog(&buf)
+	require.NoError(t, err)
+	require.Len(t, opLog.Operations, 1)
+	assert.Equal(t, 1, opLog.Operations[0].Sequence)
+	assert.Equal(t, time.Second, opLog.Operations[0].Offset)
+}
@@ -0,0 +1,160 @@
+package txsim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/celestiaorg/celestia-app/pkg/appconsts"
+	"github.com/celestiaorg/celestia-app/pkg/user"
+	"github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	bank "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
+	"github.com/rs/zerolog/log"
+)
+
+// Reclaim sends the spendable balances of all sub-accounts back to the master
+// account so that long running networks don't drain the master account.
+// Delegated and locked funds are not reclaimed. Unless fee grants are used,
+// each sub-account keeps the fee of the transfer.
+func (am *AccountManager) Reclaim(ctx context.Context) error {
+	// transactions abandoned at the end of the run may still be in the
+	// mempool, so their blocks are awaited before the balances are read
+	if err := am.waitDelay(ctx, 1); err != nil {
+		return fmt.Errorf("waiting for abandoned transactions: %w", err)
+	}
+
+	am.mtx.Lock()
+	signers := make([]*user.Signer, 0, len(am.subaccounts))
+	for _, signer := range am.subaccounts {
+		signers = append(signers, signer)
+	}
+	am.mtx.Unlock()
+
+	var (
+		wg        sync.WaitGroup
+		mtx       sync.Mutex
+		reclaimed uint64
+		errs      []error
+	)
+	for _, signer := range signers {
+		wg.Add(1)
+		go func(signer *user.Signer) {
+			defer wg.Done()
+			amount, err := am.reclaim(ctx, signer)
+			mtx.Lock()
+			defer mtx.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("reclaiming funds of %s: %w", signer.Address(), err))
+				return
+			}
+			reclaimed += amount
+		}(signer)
+	}
+	wg.Wait()
+
+	log.Info().
+		Uint64("amount", reclaimed).
+		Int("accounts", len(signers)).
+		Int("failed", len(errs)).
+		Msg("reclaimed funds")
+	return errors.Join(errs...)
+}
+
+// reclaim sends the spendable balance of the sub-account to the master
+// account and returns the amount sent.
+func (am *AccountManager) reclaim(ctx context.Context, signer *user.Signer) (uint64, error) {
+	// an abandoned transaction may never have reached the mempool so the
+	// sequence number is synchronized with the chain
+	_, sequence, err := user.QueryAccount(ctx, am.endpointOf(signer).Conn, am.encCfg, signer.Address().String())
+	if err != nil {
+		return 0, err
+	}
+	signer.ForceSetSequence(sequence)
+
+	balance, err := am.getSpendableBalance(ctx, signer.Address())
+	if err != nil {
+		return 0, err
+	}
+	fee := uint64(sendFee)
+	if am.useFeegrant {
+		fee = 0
+	}
+	if balance <= fee {
+		return 0, nil
+	}
+
+	amount := balance - fee
+	msg := bank.NewMsgSend(signer.Address(), am.master.Address(), types.NewCoins(types.NewInt64Coin(appconsts.BondDenom, int64(amount))))
+	if err := am.Submit(ctx, Operation{Msgs: []types.Msg{msg}, GasLimit: SendGasLimit}); err != nil {
+		return 0, err
+	}
+	return amount, nil
+}
+
+// RevokeFeegrants revokes the fee allowances that the master account granted
+// to the sub-accounts. Fee grants are revoked after reclaiming funds as the
+// transfers of the sub-accounts rely on them.
+func (am *AccountManager) RevokeFeegrants(ctx context.Context) error {
+	grantees, err := am.feegrantGrantees(ctx)
+	if err != nil {
+		return err
+	}
+
+	am.mtx.Lock()
+	msgs := make([]types.Msg, 0, len(am.subaccounts))
+	for address, signer := range am.subaccounts {
+		if grantees[address] {
+			revoke := feegrant.NewMsgRevokeAllowance(am.master.Address(), signer.Address())
+			msgs = append(msgs, &revoke)
+		}
+	}
+	am.mtx.Unlock()
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	if err := am.Submit(ctx, Operation{Msgs: msgs, GasLimit: uint64(len(msgs) * SendGasLimit)}); err != nil {
+		return fmt.Errorf("error revoking fee grants: %w", err)
+	}
+	log.Info().Int("grants", len(msgs)).Msg("revoked fee grants")
+	return nil
+}
+
+// feegrantGrantees returns the addresses of all accounts that the master
+// account has granted a fee allowance to.
+func (am *AccountManager) feegrantGrantees(ctx context.Context) (map[string]bool, error) {
+	grantees := make(map[string]bool)
+	client := feegrant.NewQueryClient(am.conn)
+	req := &feegrant.QueryAllowancesByGranterRequest{
+		Granter:    am.master.Address().String(),
+		Pagination: &query.PageRequest{},
+	}
+	for {
+		resp, err := client.AllowancesByGranter(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("error getting fee grants of the master account: %w", err)
+		}
+		for _, grant := range resp.Allowances {
+			grantees[grant.Grantee] = true
+		}
+		if resp.Pagination == nil || len(resp.Pagination.NextKey) == 0 {
+			return grantees, nil
+		}
+		req.Pagination.Key = resp.Pagination.NextKey
+	}
+}
+
+// getSpendableBalance returns the balance of the address that is not locked
+// by vesting.
+func (am *AccountManager) getSpendableBalance(ctx context.Context, address types.AccAddress) (uint64, error) {
+	resp, err := bank.NewQueryClient(am.conn).SpendableBalances(ctx, &bank.QuerySpendableBalancesRequest{
+		Address: address.String(),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error getting spendable balance for %s: %w", address.String(), err)
+	}
+	return resp.Balances.AmountOf(appconsts.BondDenom).Uint64(), nil
+}
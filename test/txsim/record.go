@@ -0,0 +1,301 @@
+package txsim
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/celestiaorg/celestia-app/app/encoding"
+	"github.com/celestiaorg/celestia-app/pkg/blob"
+	ns "github.com/celestiaorg/celestia-app/pkg/namespace"
+	"github.com/cosmos/cosmos-sdk/crypto"
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog/log"
+)
+
+// recordVersion is the version of the format of the operation log.
+const recordVersion = 1
+
+// recordedKeyPassphrase encrypts the private keys recorded in the operation
+// log. It only prevents the keys from being read as plain text.
+const recordedKeyPassphrase = "txsim"
+
+// RecordHeader is the first entry of an operation log. It contains everything
+// needed to set up the accounts of the recorded run on a fresh network.
+//
+// The mnemonics or private keys of the accounts are recorded so that the
+// replayed operations are signed by the same addresses. Never record runs with
+// accounts that hold funds of value.
+type RecordHeader struct {
+	Version     int               `json:"version"`
+	Seed        int64             `json:"seed"`
+	UseFeegrant bool              `json:"use_feegrant"`
+	Accounts    []RecordedAccount `json:"accounts"`
+}
+
+// RecordedAccount is an account allocated by a sequence. The mnemonic of an
+// account that was reused from the keyring of an earlier run is unknown, so
+// its armored private key is recorded instead.
+type RecordedAccount struct {
+	Address      string `json:"address"`
+	Balance      uint64 `json:"balance"`
+	Mnemonic     string `json:"mnemonic,omitempty"`
+	PrivKeyArmor string `json:"priv_key_armor,omitempty"`
+}
+
+// privKey returns the private key of the account.
+func (a RecordedAccount) privKey() (cryptotypes.PrivKey, error) {
+	switch {
+	case a.Mnemonic != "":
+		path := hd.CreateHDPath(types.CoinType, 0, 0).String()
+		bz, err := hd.Secp256k1.Derive()(a.Mnemonic, keyring.DefaultBIP39Passphrase, path)
+		if err != nil {
+			return nil, err
+		}
+		return hd.Secp256k1.Generate()(bz), nil
+	case a.PrivKeyArmor != "":
+		privKey, _, err := crypto.UnarmorDecryptPrivKey(a.PrivKeyArmor, recordedKeyPassphrase)
+		return privKey, err
+	default:
+		return nil, fmt.Errorf("account %s has neither a mnemonic nor a private key", a.Address)
+	}
+}
+
+// RecordedOperation is an operation of a sequence. Offset is the time since
+// the start of the run at which the sequence returned the operation. Msgs
+// are encoded as JSON with their type URL. The PFBs of operations with blobs
+// are not recorded as they are derived from the blobs and the signer.
+type RecordedOperation struct {
+	Sequence   int               `json:"sequence"`
+	Offset     time.Duration     `json:"offset"`
+	Signer     string            `json:"signer"`
+	Msgs       []json.RawMessage `json:"msgs,omitempty"`
+	Blobs      []RecordedBlob    `json:"blobs,omitempty"`
+	Delay      uint64            `json:"delay,omitempty"`
+	GasLimit   uint64            `json:"gas_limit,omitempty"`
+	GasPrice   float64           `json:"gas_price,omitempty"`
+	FeeGranter string            `json:"fee_granter,omitempty"`
+}
+
+// RecordedBlob describes a blob. If the data is not recorded, data of the
+// same size is generated deterministically from the hash and the seed of the
+// run when replaying.
+type RecordedBlob struct {
+	NamespaceVersion uint32 `json:"namespace_version"`
+	NamespaceID      []byte `json:"namespace_id"`
+	ShareVersion     uint32 `json:"share_version"`
+	Size             int    `json:"size"`
+	Hash             []byte `json:"hash"`
+	Data             []byte `json:"data,omitempty"`
+}
+
+// Recorder writes every operation committed by txsim to an operation log
+// which can be replayed with Replay. Operations that failed are not recorded
+// as their replay would otherwise change the state that later operations
+// depend on. The log is a stream of JSON values
+// starting with a RecordHeader followed by a RecordedOperation per operation.
+type Recorder struct {
+	encCfg   encoding.Config
+	blobData bool
+
+	mtx   sync.Mutex
+	w     *bufio.Writer
+	enc   *json.Encoder
+	start time.Time
+}
+
+// NewRecorder returns a recorder writing to w. Blob data is recorded unless
+// WithoutBlobData is set.
+func NewRecorder(w io.Writer, encCfg encoding.Config) *Recorder {
+	bw := bufio.NewWriter(w)
+	return &Recorder{
+		encCfg:   encCfg,
+		blobData: true,
+		w:        bw,
+		enc:      json.NewEncoder(bw),
+	}
+}
+
+// WithoutBlobData only records the hashes and sizes of blobs which keeps
+// the log small.
+func (r *Recorder) WithoutBlobData() *Recorder {
+	r.blobData = false
+	return r
+}
+
+// Flush writes any buffered entries to the underlying writer.
+func (r *Recorder) Flush() error {
+	if r == nil {
+		return nil
+	}
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return r.w.Flush()
+}
+
+// writeHeader records the accounts that are about to be generated and marks
+// the start of the run. The private keys of accounts without a mnemonic are
+// exported from keys.
+func (r *Recorder) writeHeader(keys keyring.Keyring, seed int64, useFeegrant bool, accounts []*account) error {
+	if r == nil {
+		return nil
+	}
+	header := RecordHeader{
+		Version:     recordVersion,
+		Seed:        seed,
+		UseFeegrant: useFeegrant,
+		Accounts:    make([]RecordedAccount, len(accounts)),
+	}
+	for i, acc := range accounts {
+		header.Accounts[i] = RecordedAccount{
+			Address:  acc.address.String(),
+			Balance:  acc.balance,
+			Mnemonic: acc.mnemonic,
+		}
+		if acc.mnemonic != "" {
+			continue
+		}
+		armor, err := keys.ExportPrivKeyArmorByAddress(acc.address, recordedKeyPassphrase)
+		if err != nil {
+			return fmt.Errorf("exporting the private key of account %s: %w", acc.address, err)
+		}
+		header.Accounts[i].PrivKeyArmor = armor
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.start = time.Now()
+	return r.enc.Encode(header)
+}
+
+// elapsed returns the time since the start of the run.
+func (r *Recorder) elapsed() time.Duration {
+	if r == nil {
+		return 0
+	}
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return time.Since(r.start)
+}
+
+// record appends the committed operation of the sequence to the log. offset
+// is the elapsed time at which the sequence returned the operation.
+// Operations that craft their own transactions can't be recorded and are
+// skipped.
+func (r *Recorder) record(seqID int, op Operation, offset time.Duration) error {
+	if r == nil {
+		return nil
+	}
+	if op.Craft != nil {
+		log.Warn().Int("sequence", seqID).Msg("skipping recording of crafted operation")
+		return nil
+	}
+	signer, err := operationSigner(op)
+	if err != nil {
+		return err
+	}
+
+	recorded := RecordedOperation{
+		Sequence: seqID,
+		Offset:   offset,
+		Signer:   signer.String(),
+		Delay:    op.Delay,
+		GasLimit: op.GasLimit,
+		GasPrice: op.GasPrice,
+	}
+	if op.FeeGranter != nil {
+		recorded.FeeGranter = op.FeeGranter.String()
+	}
+	if len(op.Blobs) > 0 {
+		recorded.Blobs = make([]RecordedBlob, len(op.Blobs))
+		for i, b := range op.Blobs {
+			recorded.Blobs[i] = r.recordBlob(b)
+		}
+	} else {
+		recorded.Msgs = make([]json.RawMessage, len(op.Msgs))
+		for i, msg := range op.Msgs {
+			bz, err := r.encCfg.Codec.MarshalInterfaceJSON(msg)
+			if err != nil {
+				return fmt.Errorf("encoding %s: %w", types.MsgTypeURL(msg), err)
+			}
+			recorded.Msgs[i] = bz
+		}
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return r.enc.Encode(recorded)
+}
+
+func (r *Recorder) recordBlob(b *blob.Blob) RecordedBlob {
+	hash := sha256.Sum256(b.Data)
+	recorded := RecordedBlob{
+		NamespaceVersion: b.NamespaceVersion,
+		NamespaceID:      b.NamespaceId,
+		ShareVersion:     b.ShareVersion,
+		Size:             len(b.Data),
+		Hash:             hash[:],
+	}
+	if r.blobData {
+		recorded.Data = b.Data
+	}
+	return recorded
+}
+
+// OperationLog is a decoded operation log.
+type OperationLog struct {
+	Header     RecordHeader
+	Operations []RecordedOperation
+}
+
+// ReadOperationLog decodes an operation log written by a Recorder.
+func ReadOperationLog(r io.Reader) (*OperationLog, error) {
+	dec := json.NewDecoder(r)
+	var opLog OperationLog
+	if err := dec.Decode(&opLog.Header); err != nil {
+		return nil, fmt.Errorf("decoding header: %w", err)
+	}
+	if opLog.Header.Version != recordVersion {
+		return nil, fmt.Errorf("unsupported operation log version %d", opLog.Header.Version)
+	}
+	for {
+		var op RecordedOperation
+		err := dec.Decode(&op)
+		if errors.Is(err, io.EOF) {
+			return &opLog, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decoding operation %d: %w", len(opLog.Operations), err)
+		}
+		opLog.Operations = append(opLog.Operations, op)
+	}
+}
+
+// toBlob returns the recorded blob. If the data was not recorded, random data
+// is derived from the hash of the original data and the seed.
+func (b RecordedBlob) toBlob(seed int64) (*blob.Blob, error) {
+	namespace, err := ns.New(uint8(b.NamespaceVersion), b.NamespaceID)
+	if err != nil {
+		return nil, err
+	}
+	data := b.Data
+	if data == nil {
+		if len(b.Hash) < 8 {
+			return nil, fmt.Errorf("blob hash must be at least 8 bytes, got %d", len(b.Hash))
+		}
+		r := rand.New(rand.NewSource(seed ^ int64(binary.BigEndian.Uint64(b.Hash))))
+		data = make([]byte, b.Size)
+		_, _ = r.Read(data)
+	}
+	return blob.New(namespace, data, uint8(b.ShareVersion)), nil
+}
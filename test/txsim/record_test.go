@@ -0,0 +1,86 @@
+package txsim
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/celestiaorg/celestia-app/app"
+	"github.com/celestiaorg/celestia-app/app/encoding"
+	"github.com/celestiaorg/celestia-app/pkg/appconsts"
+	ns "github.com/celestiaorg/celestia-app/pkg/namespace"
+	"github.com/celestiaorg/celestia-app/pkg/user"
+	"github.com/celestiaorg/celestia-app/test/util/blobfactory"
+	blobtypes "github.com/celestiaorg/celestia-app/x/blob/types"
+	"github.com/cosmos/cosmos-sdk/types"
+	bank "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	encCfg := encoding.MakeConfig(app.ModuleEncodingRegisters...)
+	sender := types.AccAddress("sender")
+	receiver := types.AccAddress("receiver")
+	blobs := blobfactory.RandBlobsWithNamespace([]ns.Namespace{ns.MustNewV0([]byte("rollup1"))}, []int{100})
+	pfb, err := blobtypes.NewMsgPayForBlobs(sender.String(), blobs...)
+	require.NoError(t, err)
+
+	send := Operation{
+		Msgs:       []types.Msg{bank.NewMsgSend(sender, receiver, types.NewCoins(types.NewInt64Coin(appconsts.BondDenom, 10)))},
+		Delay:      2,
+		FeeGranter: receiver,
+	}
+	pay := Operation{Msgs: []types.Msg{pfb}, Blobs: blobs, GasLimit: 100_000, GasPrice: 0.2}
+
+	for _, blobData := range []bool{true, false} {
+		var buf bytes.Buffer
+		recorder := NewRecorder(&buf, encCfg)
+		if !blobData {
+			recorder.WithoutBlobData()
+		}
+		accounts := []*account{{address: sender, balance: 1000, mnemonic: "mnemonic"}}
+		require.NoError(t, recorder.writeHeader(nil, 42, true, accounts))
+		require.NoError(t, recorder.record(0, send, time.Millisecond))
+		require.NoError(t, recorder.record(1, pay, 2*time.Millisecond))
+		crafted := Operation{
+			Msgs:  send.Msgs,
+			Craft: func(*user.Signer, ...user.TxOption) ([]byte, error) { return nil, nil },
+		}
+		require.NoError(t, recorder.record(0, crafted, 3*time.Millisecond))
+		require.NoError(t, recorder.Flush())
+
+		opLog, err := ReadOperationLog(&buf)
+		require.NoError(t, err)
+		assert.Equal(t, int64(42), opLog.Header.Seed)
+		assert.True(t, opLog.Header.UseFeegrant)
+		assert.Equal(t, []RecordedAccount{{Address: sender.String(), Balance: 1000, Mnemonic: "mnemonic"}}, opLog.Header.Accounts)
+		// the crafted operation is not recorded
+		require.Len(t, opLog.Operations, 2)
+		assert.Equal(t, time.Millisecond, opLog.Operations[0].Offset)
+		assert.Equal(t, 2*time.Millisecond, opLog.Operations[1].Offset)
+
+		sequences := replaySequences(opLog, encCfg, ReplayRecordedTiming)
+		require.Len(t, sequences, 2)
+
+		op, err := sequences[0].Next(context.Background(), nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, send.Delay, op.Delay)
+		assert.Equal(t, send.FeeGranter, op.FeeGranter)
+		require.Len(t, op.Msgs, 1)
+		assert.Equal(t, send.Msgs[0].String(), op.Msgs[0].String())
+		_, err = sequences[0].Next(context.Background(), nil, nil)
+		require.ErrorIs(t, err, ErrEndOfSequence)
+
+		op, err = sequences[1].Next(context.Background(), nil, nil)
+		require.NoError(t, err)
+		require.Len(t, op.Blobs, 1)
+		assert.Equal(t, blobs[0].NamespaceId, op.Blobs[0].NamespaceId)
+		assert.Len(t, op.Blobs[0].Data, 100)
+		assert.Equal(t, blobData, bytes.Equal(blobs[0].Data, op.Blobs[0].Data))
+		assert.Equal(t, pay.GasLimit, op.GasLimit)
+		require.Len(t, op.Msgs, 1)
+		assert.NoError(t, op.Msgs[0].ValidateBasic())
+	}
+}
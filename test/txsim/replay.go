@@ -0,0 +1,194 @@
+package txsim
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/celestiaorg/celestia-app/app/encoding"
+	blobtypes "github.com/celestiaorg/celestia-app/x/blob/types"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	"github.com/cosmos/cosmos-sdk/types"
+	"github.com/gogo/protobuf/grpc"
+)
+
+// Timings of a replay.
+const (
+	// ReplayRecordedTiming submits every operation at the offset at which it
+	// was recorded relative to the start of the run.
+	ReplayRecordedTiming = "recorded"
+	// ReplayAsFastAsPossible submits every operation as soon as the previous
+	// operation of its sequence has completed. Delays are ignored.
+	ReplayAsFastAsPossible = "fast"
+)
+
+// Replay resubmits the operations of an operation log against a fresh
+// network. The accounts of the recorded run are recreated from their
+// mnemonics or private keys and funded by the master account so that every
+// operation is signed by the same address as in the recorded run. The seed
+// and the use of fee grants are taken from the log.
+//
+// Operations that depend on the state of the recorded network, for example
+// delegations to its validators, can only be replayed against a network with
+// the same genesis.
+func Replay(
+	ctx context.Context,
+	grpcEndpoint string,
+	keys keyring.Keyring,
+	encCfg encoding.Config,
+	opts *Options,
+	opLog *OperationLog,
+	timing string,
+) error {
+	if timing != ReplayRecordedTiming && timing != ReplayAsFastAsPossible {
+		return fmt.Errorf("unknown replay timing %q", timing)
+	}
+	opts.seed = opLog.Header.Seed
+	opts.useFeeGrant = opLog.Header.UseFeegrant
+	opts.Fill()
+
+	manager, err := setupAccountManager(ctx, grpcEndpoint, keys, encCfg, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, acc := range opLog.Header.Accounts {
+		address, err := manager.importAccount(acc)
+		if err != nil {
+			return fmt.Errorf("importing account %s: %w", acc.Address, err)
+		}
+		if address.String() != acc.Address {
+			return fmt.Errorf("recorded key of account %s derives address %s", acc.Address, address)
+		}
+	}
+
+	return run(ctx, manager, opts, replaySequences(opLog, encCfg, timing))
+}
+
+// replaySequences splits the recorded operations by their sequence.
+func replaySequences(opLog *OperationLog, encCfg encoding.Config, timing string) []Sequence {
+	clock := &replayClock{}
+	bySequence := make(map[int][]RecordedOperation)
+	for _, op := range opLog.Operations {
+		bySequence[op.Sequence] = append(bySequence[op.Sequence], op)
+	}
+	ids := make([]int, 0, len(bySequence))
+	for id := range bySequence {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	sequences := make([]Sequence, len(ids))
+	for i, id := range ids {
+		sequences[i] = &replaySequence{
+			ops:    bySequence[id],
+			encCfg: encCfg,
+			seed:   opLog.Header.Seed,
+			timing: timing,
+			clock:  clock,
+		}
+	}
+	return sequences
+}
+
+// replayClock is the start of a replay. It is shared by all sequences of the
+// replay and starts with the first operation.
+type replayClock struct {
+	once  sync.Once
+	start time.Time
+}
+
+func (c *replayClock) elapsed() time.Duration {
+	c.once.Do(func() { c.start = time.Now() })
+	return time.Since(c.start)
+}
+
+var _ Sequence = &replaySequence{}
+
+// replaySequence returns the recorded operations of a sequence in order.
+type replaySequence struct {
+	ops    []RecordedOperation
+	encCfg encoding.Config
+	seed   int64
+	timing string
+	clock  *replayClock
+	next   int
+}
+
+// Clone is not supported as every replayed sequence submits the operations of
+// a single recorded sequence.
+func (s *replaySequence) Clone(n int) []Sequence {
+	panic("replayed sequences can't be cloned")
+}
+
+// Init is a no-op. The accounts of the recorded run are imported by Replay.
+func (s *replaySequence) Init(context.Context, grpc.ClientConn, AccountAllocator, *rand.Rand, bool) {}
+
+func (s *replaySequence) Next(ctx context.Context, _ grpc.ClientConn, _ *rand.Rand) (Operation, error) {
+	if s.next >= len(s.ops) {
+		return Operation{}, ErrEndOfSequence
+	}
+	recorded := s.ops[s.next]
+	s.next++
+
+	op, err := s.operation(recorded)
+	if err != nil {
+		return Operation{}, err
+	}
+	if s.timing == ReplayAsFastAsPossible {
+		op.Delay = 0
+		return op, nil
+	}
+
+	if wait := recorded.Offset - s.clock.elapsed(); wait > 0 {
+		select {
+		case <-ctx.Done():
+			return Operation{}, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+	return op, nil
+}
+
+// operation decodes the recorded operation.
+func (s *replaySequence) operation(recorded RecordedOperation) (Operation, error) {
+	op := Operation{
+		Delay:    recorded.Delay,
+		GasLimit: recorded.GasLimit,
+		GasPrice: recorded.GasPrice,
+	}
+	if recorded.FeeGranter != "" {
+		granter, err := types.AccAddressFromBech32(recorded.FeeGranter)
+		if err != nil {
+			return Operation{}, fmt.Errorf("decoding fee granter: %w", err)
+		}
+		op.FeeGranter = granter
+	}
+
+	if len(recorded.Blobs) > 0 {
+		for _, b := range recorded.Blobs {
+			decoded, err := b.toBlob(s.seed)
+			if err != nil {
+				return Operation{}, fmt.Errorf("decoding blob: %w", err)
+			}
+			op.Blobs = append(op.Blobs, decoded)
+		}
+		msg, err := blobtypes.NewMsgPayForBlobs(recorded.Signer, op.Blobs...)
+		if err != nil {
+			return Operation{}, err
+		}
+		op.Msgs = []types.Msg{msg}
+		return op, nil
+	}
+
+	op.Msgs = make([]types.Msg, len(recorded.Msgs))
+	for i, bz := range recorded.Msgs {
+		if err := s.encCfg.Codec.UnmarshalInterfaceJSON(bz, &op.Msgs[i]); err != nil {
+			return Operation{}, fmt.Errorf("decoding msg: %w", err)
+		}
+	}
+	return op, nil
+}
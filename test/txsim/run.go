@@ -18,12 +18,21 @@ import (
 
 const DefaultSeed = 900183116
 
+// cleanupTimeout bounds reclaiming funds and revoking fee grants at the end
+// of a run.
+const cleanupTimeout = time.Minute
+
 // Run is the entrypoint function for starting the txsim client. The lifecycle of the client is managed
 // through the context. At least one grpc and rpc endpoint must be provided. The client relies on a
 // single funded master account present in the keyring. The client allocates subaccounts for sequences
 // at runtime. A seed can be provided for deterministic randomness. The pollTime dictates the frequency
 // that the client should check for updates from state and that transactions have been committed.
 //
+// By default each sequence waits for its transaction to be committed before
+// submitting the next one (closed loop). If a tx or byte rate is set in the
+// options, transactions are instead submitted at that rate regardless of
+// confirmations (open loop).
+//
 // This should be used for testing purposes only.
 //
 // All sequences can be scaled up using the `Clone` method. This allows for a single sequence that
@@ -39,9 +48,37 @@ func Run(
 	opts.Fill()
 	r := rand.New(rand.NewSource(opts.seed))
 
-	conn, err := grpc.Dial(grpcEndpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	manager, err := setupAccountManager(ctx, grpcEndpoint, keys, encCfg, opts)
 	if err != nil {
-		return fmt.Errorf("dialing %s: %w", grpcEndpoint, err)
+		return err
+	}
+
+	// Initialize each of the sequences by allowing them to allocate accounts.
+	for _, sequence := range sequences {
+		sequence.Init(ctx, manager.conn, manager.AllocateAccounts, r, opts.useFeeGrant)
+	}
+
+	return run(ctx, manager, opts, sequences)
+}
+
+// setupAccountManager dials the nodes and creates the account manager that
+// handles the transactions of all accounts. The grpcEndpoint is the primary
+// endpoint followed by any endpoints added through WithEndpoints.
+func setupAccountManager(
+	ctx context.Context,
+	grpcEndpoint string,
+	keys keyring.Keyring,
+	encCfg encoding.Config,
+	opts *Options,
+) (*AccountManager, error) {
+	addresses := append([]string{grpcEndpoint}, opts.endpoints...)
+	endpoints := make([]Endpoint, len(addresses))
+	for i, address := range addresses {
+		conn, err := grpc.Dial(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, fmt.Errorf("dialing %s: %w", address, err)
+		}
+		endpoints[i] = Endpoint{Address: address, Conn: conn}
 	}
 
 	if opts.suppressLogger {
@@ -49,22 +86,38 @@ func Run(
 		zerolog.SetGlobalLevel(zerolog.Disabled)
 	}
 
-	// Create the account manager to handle account transactions.
-	manager, err := NewAccountManager(ctx, keys, encCfg, opts.masterAcc, conn, opts.pollTime, opts.useFeeGrant)
+	manager, err := NewAccountManager(ctx, keys, encCfg, opts.masterAcc, endpoints, opts.pollTime, opts.useFeeGrant)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	manager.metrics = opts.metrics
+	return manager, nil
+}
 
-	// Initialize each of the sequences by allowing them to allocate accounts.
-	for _, sequence := range sequences {
-		sequence.Init(ctx, manager.conn, manager.AllocateAccounts, r, opts.useFeeGrant)
-	}
+// run generates the accounts allocated by the initialized sequences and runs
+// the sequences until they have all ended.
+func run(ctx context.Context, manager *AccountManager, opts *Options, sequences []Sequence) error {
+	accounts := manager.pending
 
 	// Generate the allotted accounts on chain by sending them sufficient funds
 	if err := manager.GenerateAccounts(ctx); err != nil {
 		return err
 	}
 
+	if err := opts.recorder.writeHeader(manager.keys, opts.seed, opts.useFeeGrant, accounts); err != nil {
+		return fmt.Errorf("recording header: %w", err)
+	}
+	defer func() {
+		if err := opts.recorder.Flush(); err != nil {
+			log.Error().Err(err).Msg("flushing operation log")
+		}
+	}()
+	defer cleanup(ctx, manager, opts)
+
+	if opts.isOpenLoop() {
+		return newOpenLoop(manager, opts).run(ctx, sequences)
+	}
+
 	errCh := make(chan error, len(sequences))
 
 	// Spin up a task group to run each of the sequences concurrently.
@@ -77,13 +130,19 @@ func Run(
 			for {
 				ops, err := sequence.Next(ctx, manager.conn, r)
 				if err != nil {
-					errCh <- fmt.Errorf("sequence %d: %w", seqID, err)
+					errCh <- fmt.Errorf("sequence %d: %w", seqID, interrupted(ctx, err))
 					return
 				}
 
 				// Submit the messages to the chain.
+				offset := opts.recorder.elapsed()
 				if err := manager.Submit(ctx, ops); err != nil {
-					errCh <- fmt.Errorf("sequence %d: %w", seqID, err)
+					errCh <- fmt.Errorf("sequence %d: %w", seqID, interrupted(ctx, err))
+					return
+				}
+
+				if err := opts.recorder.record(seqID, ops, offset); err != nil {
+					errCh <- fmt.Errorf("sequence %d: recording operation: %w", seqID, err)
 					return
 				}
 				opNum++
@@ -108,12 +167,51 @@ func Run(
 	return finalErr
 }
 
+// interrupted returns the error of the context if it ended. The gRPC calls
+// that are in flight when the context ends fail with their own status, such
+// as DeadlineExceeded, which doesn't wrap the error of the context.
+func interrupted(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil && !errors.Is(err, ErrEndOfSequence) {
+		return ctxErr
+	}
+	return err
+}
+
+// cleanup reclaims the funds of the sub-accounts and revokes their fee grants
+// if requested. It runs after the context of the run has been cancelled and
+// is therefore bounded by the cleanupTimeout.
+func cleanup(ctx context.Context, manager *AccountManager, opts *Options) {
+	if !opts.reclaim && !opts.revokeFeegrants {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cleanupTimeout)
+	defer cancel()
+	if opts.reclaim {
+		if err := manager.Reclaim(ctx); err != nil {
+			log.Error().Err(err).Msg("reclaiming funds")
+		}
+	}
+	if opts.revokeFeegrants {
+		if err := manager.RevokeFeegrants(ctx); err != nil {
+			log.Error().Err(err).Msg("revoking fee grants")
+		}
+	}
+}
+
 type Options struct {
-	seed           int64
-	masterAcc      string
-	pollTime       time.Duration
-	useFeeGrant    bool
-	suppressLogger bool
+	seed            int64
+	masterAcc       string
+	endpoints       []string
+	pollTime        time.Duration
+	useFeeGrant     bool
+	reclaim         bool
+	revokeFeegrants bool
+	suppressLogger  bool
+	metrics         *Metrics
+	recorder        *Recorder
+	txRate          float64
+	byteRate        float64
+	maxInFlight     int
 }
 
 func (o *Options) Fill() {
@@ -123,6 +221,9 @@ func (o *Options) Fill() {
 	if o.pollTime == 0 {
 		o.pollTime = user.DefaultPollTime
 	}
+	if o.maxInFlight == 0 {
+		o.maxInFlight = DefaultMaxInFlight
+	}
 }
 
 func DefaultOptions() *Options {
@@ -141,11 +242,34 @@ func (o *Options) UseFeeGrant() *Options {
 	return o
 }
 
+// ReclaimFunds sends the remaining balances of the sub-accounts back to the
+// master account at the end of the run. Together with a persisted keyring,
+// which lets subsequent runs reuse the sub-accounts, this keeps long running
+// networks from draining the master account.
+func (o *Options) ReclaimFunds() *Options {
+	o.reclaim = true
+	return o
+}
+
+// RevokeFeegrants revokes the fee grants of the sub-accounts at the end of
+// the run.
+func (o *Options) RevokeFeegrants() *Options {
+	o.revokeFeegrants = true
+	return o
+}
+
 func (o *Options) SpecifyMasterAccount(name string) *Options {
 	o.masterAcc = name
 	return o
 }
 
+// WithEndpoints adds gRPC endpoints of further nodes. Accounts are spread
+// across all endpoints and fail over to another endpoint if theirs goes down.
+func (o *Options) WithEndpoints(grpcEndpoints ...string) *Options {
+	o.endpoints = append(o.endpoints, grpcEndpoints...)
+	return o
+}
+
 func (o *Options) WithSeed(seed int64) *Options {
 	o.seed = seed
 	return o
@@ -155,3 +279,44 @@ func (o *Options) WithPollTime(pollTime time.Duration) *Options {
 	o.pollTime = pollTime
 	return o
 }
+
+// WithMetrics records the latency, failures and throughput of all submitted
+// transactions in the provided metrics.
+func (o *Options) WithMetrics(metrics *Metrics) *Options {
+	o.metrics = metrics
+	return o
+}
+
+// WithRecorder records every operation to an operation log that can be
+// replayed with Replay.
+func (o *Options) WithRecorder(recorder *Recorder) *Options {
+	o.recorder = recorder
+	return o
+}
+
+// WithTxRate switches to open-loop mode in which transactions are submitted
+// at the provided rate per second regardless of whether previous transactions
+// have been committed.
+func (o *Options) WithTxRate(txsPerSecond float64) *Options {
+	o.txRate = txsPerSecond
+	return o
+}
+
+// WithByteRate switches to open-loop mode in which blobs are submitted at the
+// provided number of bytes per second regardless of whether previous
+// transactions have been committed. It can be combined with WithTxRate.
+func (o *Options) WithByteRate(bytesPerSecond float64) *Options {
+	o.byteRate = bytesPerSecond
+	return o
+}
+
+// WithMaxInFlight sets the maximum number of transactions that have been
+// broadcast but not yet committed in open-loop mode.
+func (o *Options) WithMaxInFlight(n int) *Options {
+	o.maxInFlight = n
+	return o
+}
+
+func (o *Options) isOpenLoop() bool {
+	return o.txRate > 0 || o.byteRate > 0
+}
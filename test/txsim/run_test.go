@@ -5,23 +5,40 @@
 package txsim_test
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/celestiaorg/celestia-app/app"
 	"github.com/celestiaorg/celestia-app/app/encoding"
+	"github.com/celestiaorg/celestia-app/pkg/appconsts"
 	"github.com/celestiaorg/celestia-app/test/txsim"
 	"github.com/celestiaorg/celestia-app/test/util/testnode"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	"github.com/cosmos/cosmos-sdk/server"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 
 	blob "github.com/celestiaorg/celestia-app/x/blob/types"
+	blobstream "github.com/celestiaorg/celestia-app/x/blobstream/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	vesting "github.com/cosmos/cosmos-sdk/x/auth/vesting/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
 	bank "github.com/cosmos/cosmos-sdk/x/bank/types"
 	distribution "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+	gov "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
+	slashing "github.com/cosmos/cosmos-sdk/x/slashing/types"
 	staking "github.com/cosmos/cosmos-sdk/x/staking/types"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 func TestTxSimulator(t *testing.T) {
@@ -34,6 +51,9 @@ func TestTxSimulator(t *testing.T) {
 		sequences   []txsim.Sequence
 		expMessages map[string]int64
 		useFeegrant bool
+		txRate      float64
+		// check is run against the node after the sequences have run
+		check func(t *testing.T, conn *grpc.ClientConn)
 	}{
 		{
 			name:      "send sequence",
@@ -95,6 +115,69 @@ func TestTxSimulator(t *testing.T) {
 			},
 			useFeegrant: true,
 		},
+		{
+			name: "authz, feegrant and vesting sequences",
+			sequences: []txsim.Sequence{
+				txsim.NewAuthzSequence(100),
+				txsim.NewFeegrantSequence(1e8),
+				txsim.NewVestingSequence(1000, 100),
+			},
+			expMessages: map[string]int64{
+				sdk.MsgTypeURL(&authz.MsgGrant{}):                  1,
+				sdk.MsgTypeURL(&authz.MsgExec{}):                   2,
+				sdk.MsgTypeURL(&feegrant.MsgGrantAllowance{}):      1,
+				sdk.MsgTypeURL(&bank.MsgSend{}):                    2,
+				sdk.MsgTypeURL(&vesting.MsgCreateVestingAccount{}): 2,
+			},
+		},
+		{
+			name:      "gov sequence",
+			sequences: []txsim.Sequence{txsim.NewGovSequence(3, 2)},
+			expMessages: map[string]int64{
+				sdk.MsgTypeURL(&gov.MsgSubmitProposal{}): 2,
+				sdk.MsgTypeURL(&gov.MsgVote{}):           6,
+			},
+			check: checkGovProposals(encCfg.InterfaceRegistry, 2, 3),
+		},
+		{
+			name: "validator sequence",
+			// Blobstream normalizes the voting power of the validator set, so
+			// the validator needs enough stake next to the genesis validator
+			// to not be normalized to zero
+			sequences: []txsim.Sequence{txsim.NewValidatorSequence(100_000_000, 2)},
+			expMessages: map[string]int64{
+				sdk.MsgTypeURL(&staking.MsgCreateValidator{}):       1,
+				sdk.MsgTypeURL(&blobstream.MsgRegisterEVMAddress{}): 1,
+				sdk.MsgTypeURL(&staking.MsgUndelegate{}):            2,
+				sdk.MsgTypeURL(&staking.MsgDelegate{}):              2,
+				sdk.MsgTypeURL(&slashing.MsgUnjail{}):               2,
+			},
+		},
+		{
+			name:      "unbonding sequence",
+			sequences: []txsim.Sequence{txsim.NewUnbondingSequence(1000, 3)},
+			expMessages: map[string]int64{
+				sdk.MsgTypeURL(&staking.MsgDelegate{}):   1,
+				sdk.MsgTypeURL(&staking.MsgUndelegate{}): 3,
+			},
+		},
+		{
+			name:      "adversarial sequence",
+			sequences: []txsim.Sequence{txsim.NewAdversarialSequence()},
+			// only the original of a duplicated tx is committed
+			expMessages: map[string]int64{sdk.MsgTypeURL(&bank.MsgSend{}): 1},
+		},
+		{
+			name: "open loop mixed sequence",
+			sequences: append(
+				txsim.NewSendSequence(2, 1000, 100).Clone(2),
+				txsim.NewBlobSequence(txsim.NewRange(1000, 1000), txsim.NewRange(1, 3)).Clone(2)...),
+			expMessages: map[string]int64{
+				sdk.MsgTypeURL(&bank.MsgSend{}):        10,
+				sdk.MsgTypeURL(&blob.MsgPayForBlobs{}): 20,
+			},
+			txRate: 10,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -109,6 +192,9 @@ func TestTxSimulator(t *testing.T) {
 			if tc.useFeegrant {
 				opts.UseFeeGrant()
 			}
+			if tc.txRate > 0 {
+				opts.WithTxRate(tc.txRate)
+			}
 
 			err := txsim.Run(
 				ctx,
@@ -118,8 +204,11 @@ func TestTxSimulator(t *testing.T) {
 				opts,
 				tc.sequences...,
 			)
-			// Expect all sequences to run for at least 30 seconds without error
-			require.True(t, errors.Is(err, context.DeadlineExceeded), err.Error())
+			// Expect all sequences to either end or run for 30 seconds without
+			// error
+			if err != nil {
+				require.True(t, errors.Is(err, context.DeadlineExceeded), err.Error())
+			}
 
 			blocks, err := testnode.ReadBlockchain(context.Background(), rpcAddr)
 			require.NoError(t, err)
@@ -137,10 +226,163 @@ func TestTxSimulator(t *testing.T) {
 					t.Errorf("missing %d messages of type %s (blocks: %d)", count, msg, len(blocks))
 				}
 			}
+
+			if tc.check != nil {
+				conn, err := grpc.Dial(grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+				require.NoError(t, err)
+				defer conn.Close()
+				tc.check(t, conn)
+			}
 		})
 	}
 }
 
+// checkGovProposals checks that at least the number of proposals sending funds
+// out of the gov module have been accepted and that the voters have voted on
+// each of them.
+func checkGovProposals(registry codectypes.InterfaceRegistry, proposals, voters int) func(t *testing.T, conn *grpc.ClientConn) {
+	return func(t *testing.T, conn *grpc.ClientConn) {
+		client := gov.NewQueryClient(conn)
+		resp, err := client.Proposals(context.Background(), &gov.QueryProposalsRequest{})
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, len(resp.Proposals), proposals)
+
+		authority := authtypes.NewModuleAddress(govtypes.ModuleName).String()
+		for _, proposal := range resp.Proposals[:proposals] {
+			require.Equal(t, gov.StatusVotingPeriod, proposal.Status, proposal.Id)
+			require.NoError(t, proposal.UnpackInterfaces(registry))
+			msgs, err := proposal.GetMsgs()
+			require.NoError(t, err)
+			require.Len(t, msgs, 1)
+			send, ok := msgs[0].(*bank.MsgSend)
+			require.True(t, ok, msgs[0])
+			require.Equal(t, authority, send.FromAddress)
+
+			votes, err := client.Votes(context.Background(), &gov.QueryVotesRequest{ProposalId: proposal.Id})
+			require.NoError(t, err)
+			require.Len(t, votes.Votes, voters, proposal.Id)
+		}
+	}
+}
+
+func TestTxSimulatorLowGasPrice(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping TestTxSimulatorLowGasPrice in short mode.")
+	}
+	encCfg := encoding.MakeConfig(app.ModuleEncodingRegisters...)
+	cfg := testnode.DefaultConfig().WithTimeoutCommit(300 * time.Millisecond).WithFundedAccounts("txsim-master")
+	cfg.AppOptions.Set(server.FlagMinGasPrices, fmt.Sprintf("%v%s", appconsts.DefaultMinGasPrice, app.BondDenom))
+	cctx, _, grpcAddr := testnode.NewNetwork(t, cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	opts := txsim.DefaultOptions().
+		SuppressLogs().
+		WithPollTime(time.Millisecond * 100)
+	err := txsim.Run(ctx, grpcAddr, cctx.Keyring, encCfg, opts, txsim.NewAdversarialSequence(txsim.AttackLowGasPrice))
+	// every tx below the minimum gas price must be rejected
+	require.True(t, errors.Is(err, context.DeadlineExceeded), err.Error())
+}
+
+func TestTxSimulatorReclaim(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping TestTxSimulatorReclaim in short mode.")
+	}
+	encCfg := encoding.MakeConfig(app.ModuleEncodingRegisters...)
+	keyring, _, grpcAddr := Setup(t)
+	conn, err := grpc.Dial(grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	record, err := keyring.Key("txsim-master")
+	require.NoError(t, err)
+	master, err := record.GetAddress()
+	require.NoError(t, err)
+
+	// the second run reuses the accounts of the first run
+	numKeys := 0
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		opts := txsim.DefaultOptions().
+			SuppressLogs().
+			WithPollTime(time.Millisecond * 100).
+			UseFeeGrant().
+			ReclaimFunds().
+			RevokeFeegrants()
+		err := txsim.Run(ctx, grpcAddr, keyring, encCfg, opts, txsim.NewSendSequence(2, 1000, 100))
+		cancel()
+		require.True(t, errors.Is(err, context.DeadlineExceeded), err.Error())
+
+		records, err := keyring.List()
+		require.NoError(t, err)
+		if numKeys != 0 {
+			require.Len(t, records, numKeys)
+		}
+		numKeys = len(records)
+		for _, record := range records {
+			if !strings.HasPrefix(record.Name, "tx-sim-") {
+				continue
+			}
+			address, err := record.GetAddress()
+			require.NoError(t, err)
+			resp, err := bank.NewQueryClient(conn).SpendableBalances(context.Background(), &bank.QuerySpendableBalancesRequest{Address: address.String()})
+			require.NoError(t, err)
+			require.True(t, resp.Balances.IsZero(), resp.Balances.String())
+		}
+
+		grants, err := feegrant.NewQueryClient(conn).AllowancesByGranter(context.Background(), &feegrant.QueryAllowancesByGranterRequest{Granter: master.String()})
+		require.NoError(t, err)
+		require.Empty(t, grants.Allowances)
+	}
+}
+
+func TestTxSimulatorReplayReusedAccounts(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping TestTxSimulatorReplayReusedAccounts in short mode.")
+	}
+	encCfg := encoding.MakeConfig(app.ModuleEncodingRegisters...)
+	keys, _, grpcAddr := Setup(t)
+
+	// the first run creates the accounts that the recorded run reuses
+	var buf bytes.Buffer
+	for _, recorder := range []*txsim.Recorder{nil, txsim.NewRecorder(&buf, encCfg)} {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		opts := txsim.DefaultOptions().
+			SuppressLogs().
+			WithPollTime(time.Millisecond * 100).
+			WithRecorder(recorder)
+		err := txsim.Run(ctx, grpcAddr, keys, encCfg, opts, txsim.NewSendSequence(2, 1000, 100))
+		cancel()
+		require.True(t, errors.Is(err, context.DeadlineExceeded), err.Error())
+	}
+
+	opLog, err := txsim.ReadOperationLog(&buf)
+	require.NoError(t, err)
+	require.NotEmpty(t, opLog.Header.Accounts)
+	require.NotEmpty(t, opLog.Operations)
+	for _, acc := range opLog.Header.Accounts {
+		require.Empty(t, acc.Mnemonic)
+		require.NotEmpty(t, acc.PrivKeyArmor)
+	}
+
+	// replay with a keyring that only holds the master account and an
+	// account of an unrelated earlier run under the first sub-account name
+	fresh := keyring.NewInMemory(encCfg.Codec)
+	armor, err := keys.ExportPrivKeyArmor("txsim-master", "passphrase")
+	require.NoError(t, err)
+	require.NoError(t, fresh.ImportPrivKey("txsim-master", armor, "passphrase"))
+	_, _, err = fresh.NewMnemonic("tx-sim-0", keyring.English, sdk.FullFundraiserPath, keyring.DefaultBIP39Passphrase, hd.Secp256k1)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	opts := txsim.DefaultOptions().
+		SuppressLogs().
+		WithPollTime(time.Millisecond * 100)
+	err = txsim.Replay(ctx, grpcAddr, fresh, encCfg, opts, opLog, txsim.ReplayAsFastAsPossible)
+	require.NoError(t, err)
+}
+
 func Setup(t testing.TB) (keyring.Keyring, string, string) {
 	t.Helper()
 
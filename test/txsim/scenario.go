@@ -0,0 +1,755 @@
+package txsim
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	ns "github.com/celestiaorg/celestia-app/pkg/namespace"
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	"github.com/gogo/protobuf/grpc"
+	"gopkg.in/yaml.v2"
+)
+
+// Sequence types that can be used in a scenario.
+const (
+	BlobSequenceType        = "blob"
+	SendSequenceType        = "send"
+	StakeSequenceType       = "stake"
+	GovSequenceType         = "gov"
+	AuthzSequenceType       = "authz"
+	FeegrantSequenceType    = "feegrant"
+	ValidatorSequenceType   = "validator"
+	VestingSequenceType     = "vesting"
+	UnbondingSequenceType   = "unbonding"
+	AdversarialSequenceType = "adversarial"
+)
+
+// Ramp up curves that determine when the instances of a phase start.
+const (
+	// RampUpLinear starts instances at a constant rate.
+	RampUpLinear = "linear"
+	// RampUpExponential starts instances such that the number of running
+	// instances grows exponentially.
+	RampUpExponential = "exponential"
+)
+
+// Gas price strategies that determine the gas price of the transactions of a
+// phase.
+const (
+	// GasPriceDefault uses the gas price chosen by the sequence.
+	GasPriceDefault = "default"
+	// GasPriceFixed uses the same gas price for all transactions.
+	GasPriceFixed = "fixed"
+	// GasPriceRandom picks a random gas price between min and max for each
+	// transaction.
+	GasPriceRandom = "random"
+	// GasPriceIncreasing increases the gas price linearly from min to max over
+	// the course of the phase.
+	GasPriceIncreasing = "increasing"
+)
+
+// Blob size distributions.
+const (
+	DistributionUniform     = "uniform"
+	DistributionNormal      = "normal"
+	DistributionExponential = "exponential"
+)
+
+// Scenario declaratively describes the load generated by txsim. A scenario
+// consists of phases that are run one after the other. Each phase runs a
+// weighted mix of sequences for a duration or a number of blocks.
+//
+// Scenarios are written in YAML. See the scenarios directory for examples.
+type Scenario struct {
+	Name string `yaml:"name"`
+	// Seed is used if no seed is provided to txsim.
+	Seed   int64   `yaml:"seed"`
+	Phases []Phase `yaml:"phases"`
+}
+
+// Phase is a period of the scenario in which a fixed mix of sequences is run.
+// The length of a phase is either a duration or a number of blocks. Only the
+// last phase may omit its length in which case it runs until its sequences end
+// or txsim is stopped.
+type Phase struct {
+	Name     string        `yaml:"name"`
+	Duration time.Duration `yaml:"duration"`
+	Blocks   int64         `yaml:"blocks"`
+	// Instances is the number of sequences that are run in this phase. They
+	// are split across the sequence mix according to the weights.
+	Instances int           `yaml:"instances"`
+	RampUp    RampUp        `yaml:"ramp_up"`
+	GasPrice  GasPrice      `yaml:"gas_price"`
+	Sequences []SequenceMix `yaml:"sequences"`
+}
+
+// RampUp delays the start of the instances of a phase. The last instance
+// starts after the fraction of the phase has passed. If the fraction is zero,
+// all instances start at the beginning of the phase.
+type RampUp struct {
+	Fraction float64 `yaml:"fraction"`
+	Curve    string  `yaml:"curve"`
+}
+
+// GasPrice configures the gas price strategy of a phase. Price is used by the
+// fixed strategy while Min and Max are used by the random and increasing
+// strategies.
+type GasPrice struct {
+	Strategy string  `yaml:"strategy"`
+	Price    float64 `yaml:"price"`
+	Min      float64 `yaml:"min"`
+	Max      float64 `yaml:"max"`
+}
+
+// SequenceMix is a sequence type with its weight in the mix of a phase. The
+// configuration matching the type must be set.
+type SequenceMix struct {
+	Type      string           `yaml:"type"`
+	Weight    int              `yaml:"weight"`
+	Blob      *BlobConfig      `yaml:"blob"`
+	Send      *SendConfig      `yaml:"send"`
+	Stake     *StakeConfig     `yaml:"stake"`
+	Gov       *GovConfig       `yaml:"gov"`
+	Authz     *AuthzConfig     `yaml:"authz"`
+	Feegrant  *FeegrantConfig  `yaml:"feegrant"`
+	Validator *ValidatorConfig `yaml:"validator"`
+	Vesting   *VestingConfig   `yaml:"vesting"`
+	Unbonding *UnbondingConfig `yaml:"unbonding"`
+	// Adversarial is optional and defaults to all attacks.
+	Adversarial *AdversarialConfig `yaml:"adversarial"`
+}
+
+// BlobConfig configures a blob sequence. Namespaces are hex encoded version
+// zero sub IDs which are assigned to the instances in a round robin fashion.
+// If empty, random namespaces are used.
+type BlobConfig struct {
+	Sizes       SizeDistribution `yaml:"sizes"`
+	BlobsPerPFB Range            `yaml:"blobs_per_pfb"`
+	Namespaces  []string         `yaml:"namespaces"`
+}
+
+// SizeDistribution configures the distribution of blob sizes. Min and max
+// bound the sizes of all distributions.
+type SizeDistribution struct {
+	Type   string  `yaml:"type"`
+	Min    int     `yaml:"min"`
+	Max    int     `yaml:"max"`
+	Mean   float64 `yaml:"mean"`
+	StdDev float64 `yaml:"stddev"`
+}
+
+// SendConfig configures a send sequence.
+type SendConfig struct {
+	Accounts   int `yaml:"accounts"`
+	Amount     int `yaml:"amount"`
+	Iterations int `yaml:"iterations"`
+}
+
+// StakeConfig configures a stake sequence.
+type StakeConfig struct {
+	InitialStake int `yaml:"initial_stake"`
+}
+
+// GovConfig configures a gov sequence.
+type GovConfig struct {
+	Voters    int `yaml:"voters"`
+	Proposals int `yaml:"proposals"`
+}
+
+// AuthzConfig configures an authz sequence. Amount is sent by the grantee on
+// behalf of the granter in each execution.
+type AuthzConfig struct {
+	Amount int `yaml:"amount"`
+}
+
+// FeegrantConfig configures a feegrant sequence.
+type FeegrantConfig struct {
+	SpendLimit int `yaml:"spend_limit"`
+}
+
+// ValidatorConfig configures a validator sequence.
+type ValidatorConfig struct {
+	SelfStake int `yaml:"self_stake"`
+	Cycles    int `yaml:"cycles"`
+}
+
+// VestingConfig configures a vesting sequence. Amount is vested by each of
+// the created accounts.
+type VestingConfig struct {
+	Amount   int `yaml:"amount"`
+	Accounts int `yaml:"accounts"`
+}
+
+// UnbondingConfig configures an unbonding sequence.
+type UnbondingConfig struct {
+	InitialStake int `yaml:"initial_stake"`
+	Unbondings   int `yaml:"unbondings"`
+}
+
+// AdversarialConfig configures an adversarial sequence. If no attacks are
+// listed, all attacks are used.
+type AdversarialConfig struct {
+	Attacks []Attack `yaml:"attacks"`
+}
+
+func (c *AdversarialConfig) attacks() []Attack {
+	if c == nil {
+		return nil
+	}
+	return c.Attacks
+}
+
+// LoadScenario reads and validates the scenario at path.
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	scenario, err := ParseScenario(data)
+	if err != nil {
+		return nil, fmt.Errorf("scenario %s: %w", path, err)
+	}
+	return scenario, nil
+}
+
+// ParseScenario decodes and validates a YAML encoded scenario. Unknown fields
+// are rejected.
+func ParseScenario(data []byte) (*Scenario, error) {
+	var scenario Scenario
+	if err := yaml.UnmarshalStrict(data, &scenario); err != nil {
+		return nil, fmt.Errorf("decoding scenario: %w", err)
+	}
+	if err := scenario.Validate(); err != nil {
+		return nil, err
+	}
+	return &scenario, nil
+}
+
+// Sequences compiles the scenario into sequences that can be passed to Run.
+// Each sequence only generates operations while its phase is active and ends
+// once its phase has passed.
+func (s *Scenario) Sequences() ([]Sequence, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	clock := &scenarioClock{blockBased: s.Phases[0].Blocks > 0}
+	sequences := make([]Sequence, 0)
+	phaseStart := 0.0
+	for _, phase := range s.Phases {
+		phaseEnd := phaseStart + phase.length()
+		mixes := make([][]Sequence, len(phase.Sequences))
+		for i, count := range splitByWeight(phase.Instances, phase.Sequences) {
+			var err error
+			mixes[i], err = phase.Sequences[i].sequences(count)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// interleave the sequences of the mix so that every sequence type is
+		// present from the start of the ramp up
+		instance := 0
+		for round := 0; instance < phase.Instances; round++ {
+			for _, mix := range mixes {
+				if round >= len(mix) {
+					continue
+				}
+				sequences = append(sequences, &phasedSequence{
+					Sequence:   mix[round],
+					clock:      clock,
+					start:      phase.start(phaseStart, instance),
+					phaseStart: phaseStart,
+					phaseEnd:   phaseEnd,
+					gasPrice:   phase.GasPrice,
+				})
+				instance++
+			}
+		}
+		phaseStart = phaseEnd
+	}
+	return sequences, nil
+}
+
+// Validate checks that the scenario can be compiled into sequences.
+func (s *Scenario) Validate() error {
+	if len(s.Phases) == 0 {
+		return errors.New("scenario must have at least one phase")
+	}
+	blockBased := s.Phases[0].Blocks > 0
+	for i, phase := range s.Phases {
+		if err := phase.validate(i == len(s.Phases)-1); err != nil {
+			return fmt.Errorf("phase %d (%s): %w", i, phase.Name, err)
+		}
+		if (phase.Blocks > 0) != blockBased && (phase.Blocks > 0 || phase.Duration > 0) {
+			return fmt.Errorf("phase %d (%s): all phases must either use a duration or a number of blocks", i, phase.Name)
+		}
+	}
+	return nil
+}
+
+func (p Phase) validate(last bool) error {
+	switch {
+	case p.Duration < 0 || p.Blocks < 0:
+		return errors.New("duration and blocks must not be negative")
+	case p.Duration > 0 && p.Blocks > 0:
+		return errors.New("only one of duration and blocks can be set")
+	case p.Duration == 0 && p.Blocks == 0 && !last:
+		return errors.New("either duration or blocks must be set for all but the last phase")
+	case p.Instances < 1:
+		return errors.New("instances must be at least 1")
+	case len(p.Sequences) == 0:
+		return errors.New("at least one sequence must be set")
+	case p.Duration == 0 && p.Blocks == 0 && (p.RampUp.Fraction > 0 || p.GasPrice.Strategy == GasPriceIncreasing):
+		return errors.New("ramp up and increasing gas prices require the duration or blocks to be set")
+	}
+	if err := p.RampUp.validate(); err != nil {
+		return fmt.Errorf("ramp up: %w", err)
+	}
+	if err := p.GasPrice.validate(); err != nil {
+		return fmt.Errorf("gas price: %w", err)
+	}
+	for i, mix := range p.Sequences {
+		if err := mix.validate(); err != nil {
+			return fmt.Errorf("sequence %d (%s): %w", i, mix.Type, err)
+		}
+	}
+	return nil
+}
+
+// length returns the length of the phase in seconds or blocks.
+func (p Phase) length() float64 {
+	switch {
+	case p.Blocks > 0:
+		return float64(p.Blocks)
+	case p.Duration > 0:
+		return p.Duration.Seconds()
+	default:
+		return math.Inf(1)
+	}
+}
+
+// start returns the time at which the instance of the phase starts if the
+// phase starts at phaseStart. Instances of a phase without a length start with
+// the phase as they can't be ramped up.
+func (p Phase) start(phaseStart float64, instance int) float64 {
+	offset := p.RampUp.offset(instance, p.Instances)
+	if offset == 0 || math.IsInf(p.length(), 1) {
+		return phaseStart
+	}
+	return phaseStart + offset*p.length()
+}
+
+// splitByWeight splits n instances across the mixes in proportion to their
+// weights. Instances that can't be split evenly go to the mixes with the
+// largest remainders.
+func splitByWeight(n int, mixes []SequenceMix) []int {
+	total := 0
+	for _, mix := range mixes {
+		total += mix.Weight
+	}
+	counts := make([]int, len(mixes))
+	order := make([]int, len(mixes))
+	assigned := 0
+	for i, mix := range mixes {
+		counts[i] = n * mix.Weight / total
+		assigned += counts[i]
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return n*mixes[order[i]].Weight%total > n*mixes[order[j]].Weight%total
+	})
+	for i := 0; i < n-assigned; i++ {
+		counts[order[i]]++
+	}
+	return counts
+}
+
+func (m SequenceMix) sequences(n int) ([]Sequence, error) {
+	switch m.Type {
+	case BlobSequenceType:
+		sizes, err := m.Blob.Sizes.distribution()
+		if err != nil {
+			return nil, err
+		}
+		namespaces, err := m.Blob.namespaces()
+		if err != nil {
+			return nil, err
+		}
+		sequences := make([]Sequence, n)
+		for i := range sequences {
+			sequence := NewBlobSequence(NewRange(m.Blob.Sizes.Min, m.Blob.Sizes.Max), m.Blob.BlobsPerPFB).WithSizeDistribution(sizes)
+			if len(namespaces) > 0 {
+				sequence.WithNamespace(namespaces[i%len(namespaces)])
+			}
+			sequences[i] = sequence
+		}
+		return sequences, nil
+	case SendSequenceType:
+		return NewSendSequence(m.Send.Accounts, m.Send.Amount, m.Send.Iterations).Clone(n), nil
+	case StakeSequenceType:
+		return NewStakeSequence(m.Stake.InitialStake).Clone(n), nil
+	case GovSequenceType:
+		return NewGovSequence(m.Gov.Voters, m.Gov.Proposals).Clone(n), nil
+	case AuthzSequenceType:
+		return NewAuthzSequence(m.Authz.Amount).Clone(n), nil
+	case FeegrantSequenceType:
+		return NewFeegrantSequence(m.Feegrant.SpendLimit).Clone(n), nil
+	case ValidatorSequenceType:
+		return NewValidatorSequence(m.Validator.SelfStake, m.Validator.Cycles).Clone(n), nil
+	case VestingSequenceType:
+		return NewVestingSequence(m.Vesting.Amount, m.Vesting.Accounts).Clone(n), nil
+	case UnbondingSequenceType:
+		return NewUnbondingSequence(m.Unbonding.InitialStake, m.Unbonding.Unbondings).Clone(n), nil
+	case AdversarialSequenceType:
+		return NewAdversarialSequence(m.Adversarial.attacks()...).Clone(n), nil
+	default:
+		return nil, fmt.Errorf("unknown sequence type %q", m.Type)
+	}
+}
+
+// offset returns the fraction of the phase after which the i-th of n
+// instances starts.
+func (r RampUp) offset(i, n int) float64 {
+	if r.Fraction == 0 || n < 2 {
+		return 0
+	}
+	switch r.Curve {
+	case RampUpExponential:
+		// the number of running instances after a fraction x of the ramp up
+		// is n^x
+		return r.Fraction * math.Log(float64(i+1)) / math.Log(float64(n))
+	default:
+		return r.Fraction * float64(i) / float64(n-1)
+	}
+}
+
+func (r RampUp) validate() error {
+	if r.Fraction < 0 || r.Fraction > 1 {
+		return fmt.Errorf("fraction must be between 0 and 1, got %v", r.Fraction)
+	}
+	switch r.Curve {
+	case "", RampUpLinear, RampUpExponential:
+		return nil
+	default:
+		return fmt.Errorf("unknown curve %q", r.Curve)
+	}
+}
+
+func (g GasPrice) validate() error {
+	switch g.Strategy {
+	case "", GasPriceDefault:
+		return nil
+	case GasPriceFixed:
+		if g.Price <= 0 {
+			return errors.New("price must be positive")
+		}
+		return nil
+	case GasPriceRandom, GasPriceIncreasing:
+		if g.Min <= 0 || g.Max < g.Min {
+			return fmt.Errorf("min must be positive and not greater than max, got min %v and max %v", g.Min, g.Max)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown strategy %q", g.Strategy)
+	}
+}
+
+// apply sets the gas price of the operation. progress is the fraction of the
+// phase that has passed.
+func (g GasPrice) apply(op *Operation, progress float64, rand *rand.Rand) {
+	var price float64
+	switch g.Strategy {
+	case GasPriceFixed:
+		price = g.Price
+	case GasPriceRandom:
+		price = g.Min + rand.Float64()*(g.Max-g.Min)
+	case GasPriceIncreasing:
+		price = g.Min + progress*(g.Max-g.Min)
+	default:
+		return
+	}
+	// the gas price is only used if the gas limit is set
+	if op.GasLimit == 0 {
+		op.GasLimit = DefaultGasLimit
+	}
+	op.GasPrice = price
+}
+
+func (m SequenceMix) validate() error {
+	if m.Weight < 1 {
+		return errors.New("weight must be at least 1")
+	}
+	switch m.Type {
+	case BlobSequenceType:
+		if m.Blob == nil {
+			return errors.New("blob config must be set")
+		}
+		return m.Blob.validate()
+	case SendSequenceType:
+		if m.Send == nil {
+			return errors.New("send config must be set")
+		}
+		if m.Send.Accounts < 2 || m.Send.Amount < 1 || m.Send.Iterations < 1 {
+			return errors.New("send requires at least 2 accounts, a positive amount and at least 1 iteration")
+		}
+		return nil
+	case StakeSequenceType:
+		if m.Stake == nil {
+			return errors.New("stake config must be set")
+		}
+		if m.Stake.InitialStake < 1 {
+			return errors.New("initial stake must be positive")
+		}
+		return nil
+	case GovSequenceType:
+		if m.Gov == nil {
+			return errors.New("gov config must be set")
+		}
+		if m.Gov.Voters < 1 || m.Gov.Proposals < 1 {
+			return errors.New("gov requires at least 1 voter and 1 proposal")
+		}
+		return nil
+	case AuthzSequenceType:
+		if m.Authz == nil {
+			return errors.New("authz config must be set")
+		}
+		if m.Authz.Amount < 1 {
+			return errors.New("authz amount must be positive")
+		}
+		return nil
+	case FeegrantSequenceType:
+		if m.Feegrant == nil {
+			return errors.New("feegrant config must be set")
+		}
+		if m.Feegrant.SpendLimit < 1 {
+			return errors.New("feegrant spend limit must be positive")
+		}
+		return nil
+	case ValidatorSequenceType:
+		if m.Validator == nil {
+			return errors.New("validator config must be set")
+		}
+		if m.Validator.SelfStake < 1 || m.Validator.Cycles < 1 {
+			return errors.New("validator requires a positive self stake and at least 1 cycle")
+		}
+		return nil
+	case VestingSequenceType:
+		if m.Vesting == nil {
+			return errors.New("vesting config must be set")
+		}
+		if m.Vesting.Amount < 1 || m.Vesting.Accounts < 1 {
+			return errors.New("vesting requires a positive amount and at least 1 account")
+		}
+		return nil
+	case UnbondingSequenceType:
+		if m.Unbonding == nil {
+			return errors.New("unbonding config must be set")
+		}
+		if m.Unbonding.InitialStake < 1 || m.Unbonding.Unbondings < 1 {
+			return errors.New("unbonding requires a positive initial stake and at least 1 unbonding")
+		}
+		return nil
+	case AdversarialSequenceType:
+		for _, attack := range m.Adversarial.attacks() {
+			if err := attack.Validate(); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown sequence type %q", m.Type)
+	}
+}
+
+func (c BlobConfig) validate() error {
+	if _, err := c.Sizes.distribution(); err != nil {
+		return err
+	}
+	if c.BlobsPerPFB.Min < 1 || c.BlobsPerPFB.Max < c.BlobsPerPFB.Min {
+		return fmt.Errorf("blobs per PFB must be at least 1 and min must not be greater than max, got %v", c.BlobsPerPFB)
+	}
+	_, err := c.namespaces()
+	return err
+}
+
+func (c BlobConfig) namespaces() ([]ns.Namespace, error) {
+	namespaces := make([]ns.Namespace, len(c.Namespaces))
+	for i, encoded := range c.Namespaces {
+		subID, err := hex.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("decoding namespace %s: %w", encoded, err)
+		}
+		namespaces[i], err = ns.NewV0(subID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespace %s: %w", encoded, err)
+		}
+	}
+	return namespaces, nil
+}
+
+func (d SizeDistribution) distribution() (Distribution, error) {
+	if d.Min < 1 || d.Max < d.Min {
+		return nil, fmt.Errorf("sizes must be at least 1 and min must not be greater than max, got min %d and max %d", d.Min, d.Max)
+	}
+	bounds := NewRange(d.Min, d.Max)
+	switch d.Type {
+	case "", DistributionUniform:
+		return bounds, nil
+	case DistributionNormal:
+		if d.StdDev <= 0 {
+			return nil, errors.New("normal distribution requires a positive stddev")
+		}
+		return NormalDistribution{Bounds: bounds, Mean: d.Mean, StdDev: d.StdDev}, nil
+	case DistributionExponential:
+		if d.Mean <= 0 {
+			return nil, errors.New("exponential distribution requires a positive mean")
+		}
+		return ExponentialDistribution{Bounds: bounds, Mean: d.Mean}, nil
+	default:
+		return nil, fmt.Errorf("unknown size distribution %q", d.Type)
+	}
+}
+
+// scenarioPollTime is how often the latest height is queried when phases are
+// measured in blocks.
+const scenarioPollTime = time.Second
+
+// phasedSequence wraps a sequence such that it only runs while its phase is
+// active.
+type phasedSequence struct {
+	Sequence
+
+	clock *scenarioClock
+	// start is the time at which this instance starts including the ramp up.
+	start      float64
+	phaseStart float64
+	phaseEnd   float64
+	gasPrice   GasPrice
+}
+
+var _ Sequence = &phasedSequence{}
+
+func (s *phasedSequence) Clone(n int) []Sequence {
+	clones := s.Sequence.Clone(n)
+	for i, clone := range clones {
+		phased := *s
+		phased.Sequence = clone
+		clones[i] = &phased
+	}
+	return clones
+}
+
+// Unwrap returns the wrapped sequence.
+func (s *phasedSequence) Unwrap() Sequence {
+	return s.Sequence
+}
+
+// Next waits until the instance starts and returns ErrEndOfSequence once the
+// phase has passed. Otherwise the operation of the wrapped sequence is
+// returned with the gas price of the phase.
+func (s *phasedSequence) Next(ctx context.Context, querier grpc.ClientConn, rand *rand.Rand) (Operation, error) {
+	var now float64
+	for {
+		var err error
+		now, err = s.clock.Now(ctx, querier)
+		if err != nil {
+			return Operation{}, err
+		}
+		if now >= s.start {
+			break
+		}
+		if err := s.clock.wait(ctx, s.start-now); err != nil {
+			return Operation{}, err
+		}
+	}
+	if now >= s.phaseEnd {
+		return Operation{}, ErrEndOfSequence
+	}
+
+	op, err := s.Sequence.Next(ctx, querier, rand)
+	if err != nil {
+		return Operation{}, err
+	}
+	progress := 0.0
+	if !math.IsInf(s.phaseEnd, 1) {
+		progress = (now - s.phaseStart) / (s.phaseEnd - s.phaseStart)
+	}
+	s.gasPrice.apply(&op, progress, rand)
+	return op, nil
+}
+
+// scenarioClock measures the progress of a scenario in seconds or blocks since
+// the first operation of any of its sequences was requested.
+type scenarioClock struct {
+	blockBased bool
+
+	once        sync.Once
+	startTime   time.Time
+	startHeight int64
+	startErr    error
+
+	mtx         sync.Mutex
+	height      int64
+	lastUpdated time.Time
+}
+
+// Now returns the progress of the scenario.
+func (c *scenarioClock) Now(ctx context.Context, querier grpc.ClientConn) (float64, error) {
+	c.once.Do(func() {
+		c.startTime = time.Now()
+		if c.blockBased {
+			c.startHeight, c.startErr = c.latestHeight(ctx, querier)
+		}
+	})
+	if c.startErr != nil {
+		return 0, c.startErr
+	}
+	if !c.blockBased {
+		return time.Since(c.startTime).Seconds(), nil
+	}
+	height, err := c.latestHeight(ctx, querier)
+	if err != nil {
+		return 0, err
+	}
+	return float64(height - c.startHeight), nil
+}
+
+// wait sleeps until the provided progress has likely been made.
+func (c *scenarioClock) wait(ctx context.Context, progress float64) error {
+	wait := scenarioPollTime
+	if !c.blockBased {
+		wait = time.Duration(progress * float64(time.Second))
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(wait):
+		return nil
+	}
+}
+
+// latestHeight returns the latest height of the chain. The height is queried
+// at most once every scenarioPollTime.
+func (c *scenarioClock) latestHeight(ctx context.Context, querier grpc.ClientConn) (int64, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if time.Since(c.lastUpdated) < scenarioPollTime {
+		return c.height, nil
+	}
+	resp, err := tmservice.NewServiceClient(querier).GetLatestBlock(ctx, &tmservice.GetLatestBlockRequest{})
+	if err != nil {
+		return 0, fmt.Errorf("querying latest height: %w", err)
+	}
+	c.height = resp.SdkBlock.Header.Height
+	c.lastUpdated = time.Now()
+	return c.height, nil
+}
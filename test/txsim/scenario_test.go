@@ -0,0 +1,282 @@
+package txsim
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/grpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExampleScenarios(t *testing.T) {
+	paths, err := filepath.Glob("scenarios/*.yaml")
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+
+	for _, path := range paths {
+		t.Run(path, func(t *testing.T) {
+			scenario, err := LoadScenario(path)
+			require.NoError(t, err)
+
+			sequences, err := scenario.Sequences()
+			require.NoError(t, err)
+			instances := 0
+			for _, phase := range scenario.Phases {
+				instances += phase.Instances
+			}
+			assert.Len(t, sequences, instances)
+		})
+	}
+}
+
+func TestParseScenarioValidation(t *testing.T) {
+	testCases := []struct {
+		name     string
+		scenario string
+		errMsg   string
+	}{
+		{
+			name:     "no phases",
+			scenario: "name: empty",
+			errMsg:   "at least one phase",
+		},
+		{
+			name: "unknown field",
+			scenario: `
+phases:
+  - name: one
+    instances: 1
+    unknown: true`,
+			errMsg: "unknown",
+		},
+		{
+			name: "unbounded phase before the last phase",
+			scenario: `
+phases:
+  - name: one
+    instances: 1
+    sequences: [{type: stake, weight: 1, stake: {initial_stake: 1}}]
+  - name: two
+    instances: 1
+    sequences: [{type: stake, weight: 1, stake: {initial_stake: 1}}]`,
+			errMsg: "either duration or blocks",
+		},
+		{
+			name: "mixed units",
+			scenario: `
+phases:
+  - name: one
+    blocks: 10
+    instances: 1
+    sequences: [{type: stake, weight: 1, stake: {initial_stake: 1}}]
+  - name: two
+    duration: 1m
+    instances: 1
+    sequences: [{type: stake, weight: 1, stake: {initial_stake: 1}}]`,
+			errMsg: "all phases must either use a duration or a number of blocks",
+		},
+		{
+			name: "missing config",
+			scenario: `
+phases:
+  - name: one
+    instances: 1
+    sequences: [{type: blob, weight: 1}]`,
+			errMsg: "blob config must be set",
+		},
+		{
+			name: "unknown sequence type",
+			scenario: `
+phases:
+  - name: one
+    instances: 1
+    sequences: [{type: vote, weight: 1}]`,
+			errMsg: "unknown sequence type",
+		},
+		{
+			name: "missing gov config",
+			scenario: `
+phases:
+  - name: one
+    instances: 1
+    sequences: [{type: gov, weight: 1}]`,
+			errMsg: "gov config must be set",
+		},
+		{
+			name: "validator without cycles",
+			scenario: `
+phases:
+  - name: one
+    instances: 1
+    sequences: [{type: validator, weight: 1, validator: {self_stake: 1000000}}]`,
+			errMsg: "at least 1 cycle",
+		},
+		{
+			name: "unknown attack",
+			scenario: `
+phases:
+  - name: one
+    instances: 1
+    sequences: [{type: adversarial, weight: 1, adversarial: {attacks: [replay]}}]`,
+			errMsg: "unknown attack",
+		},
+		{
+			name: "invalid namespace",
+			scenario: `
+phases:
+  - name: one
+    instances: 1
+    sequences:
+      - type: blob
+        weight: 1
+        blob: {sizes: {min: 1, max: 10}, blobs_per_pfb: {min: 1, max: 1}, namespaces: ["zz"]}`,
+			errMsg: "decoding namespace",
+		},
+		{
+			name: "ramp up of unbounded phase",
+			scenario: `
+phases:
+  - name: one
+    instances: 2
+    ramp_up: {fraction: 0.5}
+    sequences: [{type: stake, weight: 1, stake: {initial_stake: 1}}]`,
+			errMsg: "require the duration or blocks",
+		},
+		{
+			name: "invalid gas price",
+			scenario: `
+phases:
+  - name: one
+    duration: 10s
+    instances: 1
+    gas_price: {strategy: random, min: 0.2, max: 0.1}
+    sequences: [{type: stake, weight: 1, stake: {initial_stake: 1}}]`,
+			errMsg: "gas price",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseScenario([]byte(tc.scenario))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errMsg)
+		})
+	}
+}
+
+func TestSplitByWeight(t *testing.T) {
+	mixes := []SequenceMix{{Weight: 3}, {Weight: 1}, {Weight: 1}}
+	assert.Equal(t, []int{6, 2, 2}, splitByWeight(10, mixes))
+	assert.Equal(t, []int{2, 1, 1}, splitByWeight(4, mixes))
+	assert.Equal(t, []int{1, 0, 0}, splitByWeight(1, mixes))
+}
+
+func TestRampUpOffset(t *testing.T) {
+	linear := RampUp{Fraction: 0.5, Curve: RampUpLinear}
+	assert.Equal(t, 0.0, linear.offset(0, 5))
+	assert.Equal(t, 0.25, linear.offset(2, 5))
+	assert.Equal(t, 0.5, linear.offset(4, 5))
+
+	exponential := RampUp{Fraction: 1, Curve: RampUpExponential}
+	assert.Equal(t, 0.0, exponential.offset(0, 4))
+	assert.InDelta(t, 0.5, exponential.offset(1, 4), 1e-9)
+	assert.InDelta(t, 1.0, exponential.offset(3, 4), 1e-9)
+
+	assert.Equal(t, 0.0, RampUp{}.offset(3, 4))
+}
+
+func TestPhasedSequence(t *testing.T) {
+	clock := &scenarioClock{}
+	clock.once.Do(func() { clock.startTime = time.Now().Add(-10 * time.Second) })
+	r := rand.New(rand.NewSource(1))
+
+	// the phase has passed
+	seq := &phasedSequence{Sequence: &stubSequence{}, clock: clock, phaseStart: 0, phaseEnd: 5}
+	_, err := seq.Next(context.Background(), nil, r)
+	require.ErrorIs(t, err, ErrEndOfSequence)
+
+	// the phase is active and the gas price is set by the phase
+	seq = &phasedSequence{
+		Sequence:   &stubSequence{},
+		clock:      clock,
+		phaseStart: 5,
+		phaseEnd:   15,
+		gasPrice:   GasPrice{Strategy: GasPriceIncreasing, Min: 0.1, Max: 0.2},
+	}
+	op, err := seq.Next(context.Background(), nil, r)
+	require.NoError(t, err)
+	assert.EqualValues(t, DefaultGasLimit, op.GasLimit)
+	assert.InDelta(t, 0.15, op.GasPrice, 0.01)
+
+	// the instance waits for its ramp up
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	seq = &phasedSequence{Sequence: &stubSequence{}, clock: clock, start: 20, phaseStart: 15, phaseEnd: 25}
+	_, err = seq.Next(ctx, nil, r)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+// TestScenarioSequences checks that the instances of a ramped up phase start
+// one after the other and that the instances of an unbounded last phase start
+// with the phase and run until txsim is stopped.
+func TestScenarioSequences(t *testing.T) {
+	send := []SequenceMix{{Type: SendSequenceType, Weight: 1, Send: &SendConfig{Accounts: 2, Amount: 1, Iterations: 1}}}
+	scenario := &Scenario{Phases: []Phase{
+		{Name: "ramp", Duration: 10 * time.Second, Instances: 3, RampUp: RampUp{Fraction: 0.5}, Sequences: send},
+		{Name: "steady", Instances: 2, Sequences: send},
+	}}
+	sequences, err := scenario.Sequences()
+	require.NoError(t, err)
+	require.Len(t, sequences, 5)
+
+	starts := make([]float64, len(sequences))
+	for i, sequence := range sequences {
+		starts[i] = sequence.(*phasedSequence).start
+	}
+	assert.Equal(t, []float64{0, 2.5, 5, 10, 10}, starts)
+
+	steady := sequences[4].(*phasedSequence)
+	assert.True(t, math.IsInf(steady.phaseEnd, 1))
+	steady.clock.once.Do(func() { steady.clock.startTime = time.Now().Add(-time.Hour) })
+	steady.Sequence = &stubSequence{}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = steady.Next(ctx, nil, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
+}
+
+func TestDistributions(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	bounds := NewRange(10, 20)
+	normal := NormalDistribution{Bounds: bounds, Mean: 15, StdDev: 100}
+	exponential := ExponentialDistribution{Bounds: bounds, Mean: 100}
+	for i := 0; i < 100; i++ {
+		for _, d := range []Distribution{normal, exponential} {
+			n := d.Rand(r)
+			assert.GreaterOrEqual(t, n, 10)
+			assert.LessOrEqual(t, n, 20)
+		}
+	}
+}
+
+// stubSequence endlessly returns an empty operation.
+type stubSequence struct{}
+
+func (s *stubSequence) Clone(n int) []Sequence {
+	sequences := make([]Sequence, n)
+	for i := range sequences {
+		sequences[i] = &stubSequence{}
+	}
+	return sequences
+}
+
+func (s *stubSequence) Init(context.Context, grpc.ClientConn, AccountAllocator, *rand.Rand, bool) {}
+
+func (s *stubSequence) Next(context.Context, grpc.ClientConn, *rand.Rand) (Operation, error) {
+	return Operation{}, nil
+}
@@ -5,7 +5,9 @@ import (
 	"errors"
 	"math/rand"
 
+	errorsmod "cosmossdk.io/errors"
 	"github.com/celestiaorg/celestia-app/pkg/blob"
+	"github.com/celestiaorg/celestia-app/pkg/user"
 	"github.com/cosmos/cosmos-sdk/types"
 	"github.com/gogo/protobuf/grpc"
 )
@@ -33,14 +35,26 @@ type Sequence interface {
 // Operation represents a series of messages and blobs that are to be bundled
 // in a single transaction. A delay (in heights) may also be set before the transaction is sent.
 // The gas limit and price can also be set. If left at 0, the DefaultGasLimit will be used.
+// If a fee granter is set, it pays for the fees of the transaction.
+//
+// Adversarial operations may provide a Craft function that replaces the
+// default way of building and signing the transaction. If ExpectedErrors is
+// set, the node must reject the transaction with one of the errors.
 type Operation struct {
-	Msgs     []types.Msg
-	Blobs    []*blob.Blob
-	Delay    uint64
-	GasLimit uint64
-	GasPrice float64
+	Msgs           []types.Msg
+	Blobs          []*blob.Blob
+	Delay          uint64
+	GasLimit       uint64
+	GasPrice       float64
+	FeeGranter     types.AccAddress
+	Craft          CraftFunc
+	ExpectedErrors []*errorsmod.Error
 }
 
+// CraftFunc builds and signs the raw bytes of a transaction with the signer
+// of the operation and the options derived from the operation.
+type CraftFunc func(signer *user.Signer, opts ...user.TxOption) ([]byte, error)
+
 const (
 	// Set the default gas limit to cover the costs of most transactions.
 	// At 0.1 utia per gas, this equates to 20_000utia per transaction.
@@ -111,3 +111,105 @@ func getRandomValidator(ctx context.Context, conn grpc.ClientConn, rand *rand.Ra
 	}
 	return resp.Validators[rand.Intn(len(resp.Validators))], nil
 }
+
+var _ Sequence = &UnbondingSequence{}
+
+// UnbondingSequence sets up a sequence whereby an account delegates to a
+// validator, undelegates parts of its stake in a series of unbondings and
+// finally redelegates the remainder to another validator. Each undelegation
+// creates an unbonding entry so the number of unbondings should not exceed the
+// maximum number of unbonding entries.
+type UnbondingSequence struct {
+	initialStake  int
+	numUnbondings int
+
+	account     types.AccAddress
+	delegatedTo string
+	unbondings  int
+	redelegated bool
+}
+
+func NewUnbondingSequence(initialStake, numUnbondings int) *UnbondingSequence {
+	return &UnbondingSequence{
+		initialStake:  initialStake,
+		numUnbondings: numUnbondings,
+	}
+}
+
+func (s *UnbondingSequence) Clone(n int) []Sequence {
+	sequenceGroup := make([]Sequence, n)
+	for i := 0; i < n; i++ {
+		sequenceGroup[i] = NewUnbondingSequence(s.initialStake, s.numUnbondings)
+	}
+	return sequenceGroup
+}
+
+func (s *UnbondingSequence) Init(_ context.Context, _ grpc.ClientConn, allocateAccounts AccountAllocator, _ *rand.Rand, useFeegrant bool) {
+	s.account = allocateAccounts(1, s.initialStake+gasFunds(useFeegrant))[0]
+}
+
+func (s *UnbondingSequence) Next(ctx context.Context, querier grpc.ClientConn, rand *rand.Rand) (Operation, error) {
+	if s.delegatedTo == "" {
+		val, err := getRandomValidator(ctx, querier, rand)
+		if err != nil {
+			return Operation{}, err
+		}
+		s.delegatedTo = val.OperatorAddress
+		return Operation{
+			Msgs: []types.Msg{
+				&staking.MsgDelegate{
+					DelegatorAddress: s.account.String(),
+					ValidatorAddress: s.delegatedTo,
+					Amount:           types.NewInt64Coin(appconsts.BondDenom, int64(s.initialStake)),
+				},
+			},
+		}, nil
+	}
+
+	// half of the initial stake is undelegated over the course of the
+	// unbondings
+	if s.unbondings < s.numUnbondings {
+		s.unbondings++
+		return Operation{
+			Msgs: []types.Msg{
+				&staking.MsgUndelegate{
+					DelegatorAddress: s.account.String(),
+					ValidatorAddress: s.delegatedTo,
+					Amount:           types.NewInt64Coin(appconsts.BondDenom, int64(s.unbondingAmount())),
+				},
+			},
+			Delay: uint64(rand.Int63n(5)),
+		}, nil
+	}
+
+	if s.redelegated {
+		return Operation{}, ErrEndOfSequence
+	}
+	s.redelegated = true
+	val, err := getRandomValidator(ctx, querier, rand)
+	if err != nil {
+		return Operation{}, err
+	}
+	// there may not be another validator to redelegate to
+	if val.OperatorAddress == s.delegatedTo {
+		return Operation{}, ErrEndOfSequence
+	}
+	remainder := s.initialStake - s.numUnbondings*s.unbondingAmount()
+	return Operation{
+		Msgs: []types.Msg{
+			&staking.MsgBeginRedelegate{
+				DelegatorAddress:    s.account.String(),
+				ValidatorSrcAddress: s.delegatedTo,
+				ValidatorDstAddress: val.OperatorAddress,
+				Amount:              types.NewInt64Coin(appconsts.BondDenom, int64(remainder)),
+			},
+		},
+	}, nil
+}
+
+func (s *UnbondingSequence) unbondingAmount() int {
+	if s.numUnbondings == 0 {
+		return 0
+	}
+	return s.initialStake / (2 * s.numUnbondings)
+}
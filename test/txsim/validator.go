@@ -0,0 +1,128 @@
+package txsim
+
+import (
+	"context"
+	"math/rand"
+
+	"github.com/celestiaorg/celestia-app/pkg/appconsts"
+	bstypes "github.com/celestiaorg/celestia-app/x/blobstream/types"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"github.com/cosmos/cosmos-sdk/types"
+	slashing "github.com/cosmos/cosmos-sdk/x/slashing/types"
+	staking "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gogo/protobuf/grpc"
+)
+
+var _ Sequence = &ValidatorSequence{}
+
+// validatorGasLimit covers the cost of creating a validator and of the
+// undelegation that jails it, which both exceed the DefaultGasLimit.
+const validatorGasLimit = 400_000
+
+// ValidatorSequence sets up a sequence whereby an account creates a validator
+// and registers an EVM address for it. It then repeatedly undelegates enough
+// of its self delegation to fall below the minimum self delegation, which
+// jails the validator, delegates the same amount again and unjails the
+// validator. The self stake must be large enough for the validator to have
+// voting power, also once Blobstream normalizes the voting power of the
+// validator set, which halts the chain otherwise. As every cycle creates an
+// unbonding entry, the number of cycles should not exceed the maximum number
+// of unbonding entries.
+type ValidatorSequence struct {
+	selfStake int
+	numCycles int
+
+	account types.AccAddress
+	pubKey  cryptotypes.PubKey
+	// step is the number of operations that have been returned.
+	step int
+}
+
+func NewValidatorSequence(selfStake, numCycles int) *ValidatorSequence {
+	return &ValidatorSequence{
+		selfStake: selfStake,
+		numCycles: numCycles,
+	}
+}
+
+func (s *ValidatorSequence) Clone(n int) []Sequence {
+	sequenceGroup := make([]Sequence, n)
+	for i := 0; i < n; i++ {
+		sequenceGroup[i] = NewValidatorSequence(s.selfStake, s.numCycles)
+	}
+	return sequenceGroup
+}
+
+// Init funds the account with the self stake and the amount that is delegated
+// again in each cycle, as undelegated funds remain locked until the unbonding
+// period has passed.
+func (s *ValidatorSequence) Init(_ context.Context, _ grpc.ClientConn, allocateAccounts AccountAllocator, rand *rand.Rand, useFeegrant bool) {
+	funds := s.selfStake + s.numCycles*s.unbondAmount() + gasFunds(useFeegrant)
+	s.account = allocateAccounts(1, funds)[0]
+	secret := make([]byte, 32)
+	_, _ = rand.Read(secret)
+	s.pubKey = ed25519.GenPrivKeyFromSecret(secret).PubKey()
+}
+
+func (s *ValidatorSequence) Next(_ context.Context, _ grpc.ClientConn, rand *rand.Rand) (Operation, error) {
+	valAddr := types.ValAddress(s.account)
+	defer func() { s.step++ }()
+
+	switch {
+	case s.step == 0:
+		// the commission must be at least the minimum commission of 5%
+		rate := types.NewDecWithPrec(int64(5+rand.Intn(96)), 2)
+		msg, err := staking.NewMsgCreateValidator(
+			valAddr,
+			s.pubKey,
+			types.NewInt64Coin(appconsts.BondDenom, int64(s.selfStake)),
+			staking.NewDescription("txsim", "", "", "", ""),
+			staking.NewCommissionRates(rate, types.OneDec(), types.OneDec()),
+			types.NewInt(int64(s.selfStake/2)),
+		)
+		if err != nil {
+			return Operation{}, err
+		}
+		return Operation{
+			Msgs:     []types.Msg{msg},
+			GasLimit: validatorGasLimit,
+		}, nil
+
+	case s.step == 1:
+		var evmAddress common.Address
+		_, _ = rand.Read(evmAddress[:])
+		return Operation{
+			Msgs:  []types.Msg{bstypes.NewMsgRegisterEVMAddress(valAddr, evmAddress)},
+			Delay: 1,
+		}, nil
+
+	case s.step >= 2+3*s.numCycles:
+		return Operation{}, ErrEndOfSequence
+	}
+
+	amount := types.NewInt64Coin(appconsts.BondDenom, int64(s.unbondAmount()))
+	switch (s.step - 2) % 3 {
+	case 0:
+		return Operation{
+			Msgs:     []types.Msg{staking.NewMsgUndelegate(s.account, valAddr, amount)},
+			GasLimit: validatorGasLimit,
+			Delay:    uint64(rand.Int63n(5)),
+		}, nil
+	case 1:
+		return Operation{
+			Msgs: []types.Msg{staking.NewMsgDelegate(s.account, valAddr, amount)},
+		}, nil
+	default:
+		return Operation{
+			Msgs: []types.Msg{slashing.NewMsgUnjail(valAddr)},
+		}, nil
+	}
+}
+
+// unbondAmount is the smallest amount that brings the self delegation below
+// the minimum self delegation.
+func (s *ValidatorSequence) unbondAmount() int {
+	return s.selfStake/2 + 1
+}
@@ -0,0 +1,48 @@
+package txsim
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/types"
+	slashing "github.com/cosmos/cosmos-sdk/x/slashing/types"
+	staking "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	bstypes "github.com/celestiaorg/celestia-app/x/blobstream/types"
+)
+
+func TestValidatorSequence(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	account := types.AccAddress("validator")
+	var funds int
+	seq := NewValidatorSequence(1_000_000, 2)
+	seq.Init(context.Background(), nil, func(n, balance int) []types.AccAddress {
+		funds = balance
+		return []types.AccAddress{account}
+	}, r, true)
+	assert.Equal(t, 1_000_000+2*500_001+1, funds)
+
+	expected := []types.Msg{
+		&staking.MsgCreateValidator{},
+		&bstypes.MsgRegisterEVMAddress{},
+		&staking.MsgUndelegate{},
+		&staking.MsgDelegate{},
+		&slashing.MsgUnjail{},
+		&staking.MsgUndelegate{},
+		&staking.MsgDelegate{},
+		&slashing.MsgUnjail{},
+	}
+	for _, msg := range expected {
+		op, err := seq.Next(context.Background(), nil, r)
+		require.NoError(t, err)
+		require.Len(t, op.Msgs, 1)
+		assert.Equal(t, types.MsgTypeURL(msg), types.MsgTypeURL(op.Msgs[0]))
+		require.NoError(t, op.Msgs[0].ValidateBasic())
+		assert.Equal(t, account, op.Msgs[0].GetSigners()[0])
+	}
+	_, err := seq.Next(context.Background(), nil, r)
+	require.ErrorIs(t, err, ErrEndOfSequence)
+}
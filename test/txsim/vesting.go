@@ -0,0 +1,70 @@
+package txsim
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/celestiaorg/celestia-app/pkg/appconsts"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	"github.com/cosmos/cosmos-sdk/types"
+	vesting "github.com/cosmos/cosmos-sdk/x/auth/vesting/types"
+	"github.com/gogo/protobuf/grpc"
+)
+
+var _ Sequence = &VestingSequence{}
+
+// VestingSequence sets up a sequence whereby an account creates vesting
+// accounts for new random addresses. Each vesting account is at random either
+// a continuous or a delayed vesting account which vests within an hour. The
+// sequence ends after the provided number of accounts have been created.
+type VestingSequence struct {
+	amount      int
+	numAccounts int
+
+	account types.AccAddress
+	created int
+}
+
+func NewVestingSequence(amount, numAccounts int) *VestingSequence {
+	return &VestingSequence{
+		amount:      amount,
+		numAccounts: numAccounts,
+	}
+}
+
+func (s *VestingSequence) Clone(n int) []Sequence {
+	sequenceGroup := make([]Sequence, n)
+	for i := 0; i < n; i++ {
+		sequenceGroup[i] = NewVestingSequence(s.amount, s.numAccounts)
+	}
+	return sequenceGroup
+}
+
+func (s *VestingSequence) Init(_ context.Context, _ grpc.ClientConn, allocateAccounts AccountAllocator, _ *rand.Rand, useFeegrant bool) {
+	s.account = allocateAccounts(1, s.amount*s.numAccounts+gasFunds(useFeegrant))[0]
+}
+
+func (s *VestingSequence) Next(_ context.Context, _ grpc.ClientConn, rand *rand.Rand) (Operation, error) {
+	if s.created >= s.numAccounts {
+		return Operation{}, ErrEndOfSequence
+	}
+
+	secret := make([]byte, 32)
+	_, _ = rand.Read(secret)
+	to := types.AccAddress(secp256k1.GenPrivKeyFromSecret(secret).PubKey().Address())
+	endTime := time.Now().Add(time.Duration(1+rand.Intn(60)) * time.Minute).Unix()
+	msg := vesting.NewMsgCreateVestingAccount(
+		s.account,
+		to,
+		types.NewCoins(types.NewInt64Coin(appconsts.BondDenom, int64(s.amount))),
+		time.Now().Unix(),
+		endTime,
+		rand.Intn(2) == 0,
+	)
+	s.created++
+	return Operation{
+		Msgs:  []types.Msg{msg},
+		Delay: uint64(rand.Int63n(5)),
+	}, nil
+}
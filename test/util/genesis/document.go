@@ -65,6 +65,9 @@ func Document(
 	for _, modifer := range mods {
 		state = modifer(state)
 	}
+	if err := app.ModuleBasics.ValidateGenesis(ecfg.Codec, ecfg.TxConfig, state); err != nil {
+		return nil, err
+	}
 
 	stateBz, err := json.MarshalIndent(state, "", "  ")
 	if err != nil {
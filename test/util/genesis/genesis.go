@@ -69,11 +69,21 @@ func (g *Genesis) WithConsensusParams(params *tmproto.ConsensusParams) *Genesis
 	return g
 }
 
+// WithAppVersion sets the app version that the network starts with.
+func (g *Genesis) WithAppVersion(version uint64) *Genesis {
+	params := *g.ConsensusParams
+	params.Version.AppVersion = version
+	g.ConsensusParams = &params
+	return g
+}
+
 func (g *Genesis) WithChainID(chainID string) *Genesis {
 	g.ChainID = chainID
 	return g
 }
 
+// WithGenesisTime sets the genesis time. The mint module derives the time of
+// genesis, and therefore its inflation schedule, from it.
 func (g *Genesis) WithGenesisTime(genesisTime time.Time) *Genesis {
 	g.GenesisTime = genesisTime
 	return g
@@ -142,6 +152,13 @@ func (g *Genesis) Accounts() []Account {
 }
 
 func (g *Genesis) Export() (*coretypes.GenesisDoc, error) {
+	if err := coretypes.ValidateConsensusParams(*g.ConsensusParams); err != nil {
+		return nil, fmt.Errorf("invalid consensus params: %w", err)
+	}
+	if version := g.ConsensusParams.Version.AppVersion; version > appconsts.LatestVersion {
+		return nil, fmt.Errorf("app version %d is not supported: the latest version is %d", version, appconsts.LatestVersion)
+	}
+
 	addrs := make([]string, 0, len(g.accounts))
 	pubKeys := make([]cryptotypes.PubKey, 0, len(g.accounts))
 	gentxs := make([]json.RawMessage, 0, len(g.genTxs))
@@ -176,7 +193,7 @@ func (g *Genesis) Export() (*coretypes.GenesisDoc, error) {
 		gentxs = append(gentxs, json.RawMessage(bz))
 	}
 
-	return Document(
+	doc, err := Document(
 		g.ecfg,
 		g.ConsensusParams,
 		g.ChainID,
@@ -185,6 +202,11 @@ func (g *Genesis) Export() (*coretypes.GenesisDoc, error) {
 		pubKeys,
 		g.genOps...,
 	)
+	if err != nil {
+		return nil, err
+	}
+	doc.GenesisTime = g.GenesisTime
+	return doc, nil
 }
 
 func (g *Genesis) Keyring() keyring.Keyring {
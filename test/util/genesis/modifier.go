@@ -2,22 +2,42 @@ package genesis
 
 import (
 	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
 	"time"
 
 	"github.com/celestiaorg/celestia-app/app"
+	"github.com/celestiaorg/celestia-app/app/encoding"
 	blobtypes "github.com/celestiaorg/celestia-app/x/blob/types"
 	bstypes "github.com/celestiaorg/celestia-app/x/blobstream/types"
 	"github.com/cosmos/cosmos-sdk/codec"
+	cdctypes "github.com/cosmos/cosmos-sdk/codec/types"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	vestingtypes "github.com/cosmos/cosmos-sdk/x/auth/vesting/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
 	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
 	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
 	v1 "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
+	slashingtypes "github.com/cosmos/cosmos-sdk/x/slashing/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	ibctransfertypes "github.com/cosmos/ibc-go/v6/modules/apps/transfer/types"
+	ibcclienttypes "github.com/cosmos/ibc-go/v6/modules/core/02-client/types"
+	ibcconnectiontypes "github.com/cosmos/ibc-go/v6/modules/core/03-connection/types"
+	ibchost "github.com/cosmos/ibc-go/v6/modules/core/24-host"
+	ibctypes "github.com/cosmos/ibc-go/v6/modules/core/types"
+	"github.com/gogo/protobuf/proto"
 )
 
 // Modifier allows for arbitrary changes to be made on the genesis state
 // after initial accounts have been added. It accepts the genesis state as input
 // and is expected to return the modified genesis as output.
+//
+// The modifiers of this package validate the genesis state of the modules they
+// modify and panic if it is invalid.
 type Modifier func(state map[string]json.RawMessage) map[string]json.RawMessage
 
 // SetBlobParams will set the provided blob params as genesis state.
@@ -25,7 +45,7 @@ func SetBlobParams(codec codec.Codec, params blobtypes.Params) Modifier {
 	return func(state map[string]json.RawMessage) map[string]json.RawMessage {
 		blobGenState := blobtypes.DefaultGenesis()
 		blobGenState.Params = params
-		state[blobtypes.ModuleName] = codec.MustMarshalJSON(blobGenState)
+		setModuleState(codec, state, blobtypes.ModuleName, blobGenState)
 		return state
 	}
 }
@@ -40,7 +60,7 @@ func ImmediateProposals(codec codec.Codec) Modifier {
 		gs.TallyParams.Threshold = "0.000001"
 		vp := time.Second * 5
 		gs.VotingParams.VotingPeriod = &vp
-		state[govtypes.ModuleName] = codec.MustMarshalJSON(gs)
+		setModuleState(codec, state, govtypes.ModuleName, gs)
 		return state
 	}
 }
@@ -51,7 +71,7 @@ func SetDataCommitmentWindow(codec codec.Codec, window uint64) Modifier {
 	return func(state map[string]json.RawMessage) map[string]json.RawMessage {
 		qgbGenState := bstypes.DefaultGenesis()
 		qgbGenState.Params.DataCommitmentWindow = window
-		state[bstypes.ModuleName] = codec.MustMarshalJSON(qgbGenState)
+		setModuleState(codec, state, bstypes.ModuleName, qgbGenState)
 		return state
 	}
 }
@@ -61,31 +81,314 @@ func SetDataCommitmentWindow(codec codec.Codec, window uint64) Modifier {
 // use the one generated by the testnet infra.
 func FundAccounts(codec codec.Codec, addresses []sdk.AccAddress, balance sdk.Coin) Modifier {
 	return func(state map[string]json.RawMessage) map[string]json.RawMessage {
-		// set the accounts in the genesis state
-		var authGenState authtypes.GenesisState
-		codec.MustUnmarshalJSON(state[authtypes.ModuleName], &authGenState)
-
 		genAccounts := make([]authtypes.GenesisAccount, len(addresses))
 		genBalances := make([]banktypes.Balance, len(addresses))
 		for idx, addr := range addresses {
-			genAccounts[idx] = authtypes.NewBaseAccount(addr, nil, uint64(idx+len(authGenState.Accounts)), 0)
+			genAccounts[idx] = authtypes.NewBaseAccount(addr, nil, 0, 0)
 			genBalances[idx] = banktypes.Balance{Address: addr.String(), Coins: sdk.NewCoins(balance)}
 		}
+		addAccounts(codec, state, genAccounts, genBalances)
+		return state
+	}
+}
+
+// VestingAccount is a genesis account whose balance is partially locked until
+// it vests.
+type VestingAccount struct {
+	Address sdk.AccAddress
+	// Balance is the initial balance of the account, including the coins that
+	// vest.
+	Balance sdk.Coins
+	// Vesting are the coins of the balance that are locked until they vest.
+	Vesting sdk.Coins
+	// Start is the time at which the coins start to vest linearly until End.
+	// If Start is zero, all coins vest at once at End.
+	Start time.Time
+	End   time.Time
+}
 
-		accounts, err := authtypes.PackAccounts(genAccounts)
-		if err != nil {
+// AddVestingAccounts adds continuous vesting accounts, or delayed vesting
+// accounts if their start time is zero, to the genesis and funds them.
+func AddVestingAccounts(codec codec.Codec, accounts ...VestingAccount) Modifier {
+	return func(state map[string]json.RawMessage) map[string]json.RawMessage {
+		genAccounts := make([]authtypes.GenesisAccount, len(accounts))
+		genBalances := make([]banktypes.Balance, len(accounts))
+		for idx, acc := range accounts {
+			if !acc.Balance.IsAllGTE(acc.Vesting) {
+				panic(fmt.Sprintf("vesting coins %s of %s exceed its balance %s", acc.Vesting, acc.Address, acc.Balance))
+			}
+			baseAccount := authtypes.NewBaseAccount(acc.Address, nil, 0, 0)
+			if acc.Start.IsZero() {
+				genAccounts[idx] = vestingtypes.NewDelayedVestingAccount(baseAccount, acc.Vesting, acc.End.Unix())
+			} else {
+				genAccounts[idx] = vestingtypes.NewContinuousVestingAccount(baseAccount, acc.Vesting, acc.Start.Unix(), acc.End.Unix())
+			}
+			genBalances[idx] = banktypes.Balance{Address: acc.Address.String(), Coins: acc.Balance}
+		}
+		addAccounts(codec, state, genAccounts, genBalances)
+		return state
+	}
+}
+
+// addAccounts adds the accounts and their balances to the auth and bank
+// genesis states. The accounts are numbered after the existing accounts.
+func addAccounts(codec codec.Codec, state map[string]json.RawMessage, accounts []authtypes.GenesisAccount, balances []banktypes.Balance) {
+	var authGenState authtypes.GenesisState
+	codec.MustUnmarshalJSON(state[authtypes.ModuleName], &authGenState)
+
+	for idx, acc := range accounts {
+		if err := acc.SetAccountNumber(uint64(idx + len(authGenState.Accounts))); err != nil {
 			panic(err)
 		}
+	}
+	packedAccounts, err := authtypes.PackAccounts(accounts)
+	if err != nil {
+		panic(err)
+	}
+	authGenState.Accounts = append(authGenState.Accounts, packedAccounts...)
+	setModuleState(codec, state, authtypes.ModuleName, &authGenState)
+
+	var bankGenState banktypes.GenesisState
+	codec.MustUnmarshalJSON(state[banktypes.ModuleName], &bankGenState)
+	bankGenState.Balances = append(bankGenState.Balances, balances...)
+	setModuleState(codec, state, banktypes.ModuleName, &bankGenState)
+}
+
+// SetStakingParams sets the params of the staking module.
+func SetStakingParams(codec codec.Codec, params stakingtypes.Params) Modifier {
+	return func(state map[string]json.RawMessage) map[string]json.RawMessage {
+		var stakingGenState stakingtypes.GenesisState
+		codec.MustUnmarshalJSON(state[stakingtypes.ModuleName], &stakingGenState)
+		stakingGenState.Params = params
+		setModuleState(codec, state, stakingtypes.ModuleName, &stakingGenState)
+		return state
+	}
+}
+
+// JailedValidator is a validator that is added to the staking genesis state
+// directly instead of through a genesis transaction.
+type JailedValidator struct {
+	// Operator is the account that operates the validator. It self-delegates
+	// the stake of the validator.
+	Operator     sdk.AccAddress
+	ConsensusKey cryptotypes.PubKey
+	Stake        sdk.Int
+	Moniker      string
+}
 
-		authGenState.Accounts = append(authGenState.Accounts, accounts...)
-		state[authtypes.ModuleName] = codec.MustMarshalJSON(&authGenState)
+// AddJailedValidators adds validators that are not part of the initial
+// validator set. The validators are jailed and unbonded because the validator
+// set of the genesis is determined by the genesis transactions. Their operators
+// can unjail them, after funding the operator accounts with FundAccounts, to
+// test changes of the validator set. The stake of the validators is held by the
+// not bonded pool.
+func AddJailedValidators(codec codec.Codec, validators ...JailedValidator) Modifier {
+	return func(state map[string]json.RawMessage) map[string]json.RawMessage {
+		var stakingGenState stakingtypes.GenesisState
+		codec.MustUnmarshalJSON(state[stakingtypes.ModuleName], &stakingGenState)
+		var slashingGenState slashingtypes.GenesisState
+		codec.MustUnmarshalJSON(state[slashingtypes.ModuleName], &slashingGenState)
+
+		stake := sdk.ZeroInt()
+		for _, v := range validators {
+			if !v.Stake.IsPositive() {
+				panic(fmt.Sprintf("stake of validator %s must be positive", v.Moniker))
+			}
+			valAddr := sdk.ValAddress(v.Operator)
+			val, err := stakingtypes.NewValidator(valAddr, v.ConsensusKey, stakingtypes.Description{Moniker: v.Moniker})
+			if err != nil {
+				panic(err)
+			}
+			val.Jailed = true
+			val.Tokens = v.Stake
+			val.DelegatorShares = sdk.NewDecFromInt(v.Stake)
+			stakingGenState.Validators = append(stakingGenState.Validators, val)
+			stakingGenState.Delegations = append(stakingGenState.Delegations, stakingtypes.NewDelegation(v.Operator, valAddr, val.DelegatorShares))
 
-		// set the balances in the genesis state
+			// validators can only be unjailed if they have signing info
+			consAddr := sdk.ConsAddress(v.ConsensusKey.Address())
+			slashingGenState.SigningInfos = append(slashingGenState.SigningInfos, slashingtypes.SigningInfo{
+				Address:              consAddr.String(),
+				ValidatorSigningInfo: slashingtypes.NewValidatorSigningInfo(consAddr, 0, 0, time.Unix(0, 0).UTC(), false, 0),
+			})
+			stake = stake.Add(v.Stake)
+		}
+		setModuleState(codec, state, stakingtypes.ModuleName, &stakingGenState)
+		setModuleState(codec, state, slashingtypes.ModuleName, &slashingGenState)
+
+		// the balance of the not bonded pool must match the stake of the
+		// unbonded validators
 		var bankGenState banktypes.GenesisState
 		codec.MustUnmarshalJSON(state[banktypes.ModuleName], &bankGenState)
+		notBondedPool := authtypes.NewModuleAddress(stakingtypes.NotBondedPoolName).String()
+		coins := sdk.NewCoins(sdk.NewCoin(stakingGenState.Params.BondDenom, stake))
+		found := false
+		for i, balance := range bankGenState.Balances {
+			if balance.Address == notBondedPool {
+				bankGenState.Balances[i].Coins = balance.Coins.Add(coins...)
+				found = true
+			}
+		}
+		if !found {
+			bankGenState.Balances = append(bankGenState.Balances, banktypes.Balance{Address: notBondedPool, Coins: coins})
+		}
+		setModuleState(codec, state, banktypes.ModuleName, &bankGenState)
+		return state
+	}
+}
+
+// SetSlashingParams sets the params of the slashing module.
+func SetSlashingParams(codec codec.Codec, params slashingtypes.Params) Modifier {
+	return func(state map[string]json.RawMessage) map[string]json.RawMessage {
+		var slashingGenState slashingtypes.GenesisState
+		codec.MustUnmarshalJSON(state[slashingtypes.ModuleName], &slashingGenState)
+		slashingGenState.Params = params
+		setModuleState(codec, state, slashingtypes.ModuleName, &slashingGenState)
+		return state
+	}
+}
+
+// FeeGrant is a fee allowance that the granter grants to the grantee.
+type FeeGrant struct {
+	Granter   sdk.AccAddress
+	Grantee   sdk.AccAddress
+	Allowance feegrant.FeeAllowanceI
+}
 
-		bankGenState.Balances = append(bankGenState.Balances, genBalances...)
-		state[banktypes.ModuleName] = codec.MustMarshalJSON(&bankGenState)
+// AddFeeGrants adds fee allowances to the genesis.
+func AddFeeGrants(codec codec.Codec, grants ...FeeGrant) Modifier {
+	return func(state map[string]json.RawMessage) map[string]json.RawMessage {
+		var feegrantGenState feegrant.GenesisState
+		codec.MustUnmarshalJSON(state[feegrant.ModuleName], &feegrantGenState)
+		for _, g := range grants {
+			grant, err := feegrant.NewGrant(g.Granter, g.Grantee, g.Allowance)
+			if err != nil {
+				panic(err)
+			}
+			feegrantGenState.Allowances = append(feegrantGenState.Allowances, grant)
+		}
+		setModuleState(codec, state, feegrant.ModuleName, &feegrantGenState)
 		return state
 	}
 }
+
+// AuthzGrant is an authorization that the granter grants to the grantee. The
+// grant doesn't expire if Expiration is nil.
+type AuthzGrant struct {
+	Granter       sdk.AccAddress
+	Grantee       sdk.AccAddress
+	Authorization authz.Authorization
+	Expiration    *time.Time
+}
+
+// AddAuthzGrants adds authorizations to the genesis.
+func AddAuthzGrants(codec codec.Codec, grants ...AuthzGrant) Modifier {
+	return func(state map[string]json.RawMessage) map[string]json.RawMessage {
+		var authzGenState authz.GenesisState
+		codec.MustUnmarshalJSON(state[authz.ModuleName], &authzGenState)
+		for _, g := range grants {
+			if err := g.Authorization.ValidateBasic(); err != nil {
+				panic(err)
+			}
+			authorization, err := cdctypes.NewAnyWithValue(g.Authorization)
+			if err != nil {
+				panic(err)
+			}
+			authzGenState.Authorization = append(authzGenState.Authorization, authz.GrantAuthorization{
+				Granter:       g.Granter.String(),
+				Grantee:       g.Grantee.String(),
+				Authorization: authorization,
+				Expiration:    g.Expiration,
+			})
+		}
+		setModuleState(codec, state, authz.ModuleName, &authzGenState)
+		return state
+	}
+}
+
+// SetIBCParams sets the params of the IBC client and connection submodules.
+func SetIBCParams(codec codec.Codec, clientParams ibcclienttypes.Params, connectionParams ibcconnectiontypes.Params) Modifier {
+	return func(state map[string]json.RawMessage) map[string]json.RawMessage {
+		var ibcGenState ibctypes.GenesisState
+		codec.MustUnmarshalJSON(state[ibchost.ModuleName], &ibcGenState)
+		ibcGenState.ClientGenesis.Params = clientParams
+		ibcGenState.ConnectionGenesis.Params = connectionParams
+		setModuleState(codec, state, ibchost.ModuleName, &ibcGenState)
+		return state
+	}
+}
+
+// SetTransferParams sets the params of the IBC transfer module.
+func SetTransferParams(codec codec.Codec, params ibctransfertypes.Params) Modifier {
+	return func(state map[string]json.RawMessage) map[string]json.RawMessage {
+		var transferGenState ibctransfertypes.GenesisState
+		codec.MustUnmarshalJSON(state[ibctransfertypes.ModuleName], &transferGenState)
+		transferGenState.Params = params
+		setModuleState(codec, state, ibctransfertypes.ModuleName, &transferGenState)
+		return state
+	}
+}
+
+// SetBlobstreamParams sets the params of the blobstream module.
+func SetBlobstreamParams(codec codec.Codec, params bstypes.Params) Modifier {
+	return func(state map[string]json.RawMessage) map[string]json.RawMessage {
+		var bsGenState bstypes.GenesisState
+		codec.MustUnmarshalJSON(state[bstypes.ModuleName], &bsGenState)
+		bsGenState.Params = &params
+		setModuleState(codec, state, bstypes.ModuleName, &bsGenState)
+		return state
+	}
+}
+
+// ImportModuleStates replaces the genesis state of every module in states.
+// It is used to reproduce state exported with `celestia-appd export --modules`
+// on a fresh network. Modules not present in states are left untouched.
+func ImportModuleStates(states map[string]json.RawMessage) Modifier {
+	return func(state map[string]json.RawMessage) map[string]json.RawMessage {
+		for moduleName, moduleState := range states {
+			state[moduleName] = moduleState
+		}
+		return state
+	}
+}
+
+// ReadModuleStates reads a file written by `celestia-appd export --modules`.
+func ReadModuleStates(path string) (map[string]json.RawMessage, error) {
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var states map[string]json.RawMessage
+	if err := json.Unmarshal(bz, &states); err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+// encodingConfig is used to validate the genesis state of modules.
+var encodingConfig = sync.OnceValue(func() encoding.Config {
+	return encoding.MakeConfig(app.ModuleBasics)
+})
+
+// setModuleState sets the genesis state of the module and panics if it is
+// invalid.
+func setModuleState(codec codec.Codec, state map[string]json.RawMessage, moduleName string, moduleState proto.Message) {
+	bz := codec.MustMarshalJSON(moduleState)
+	if err := validateModuleState(moduleName, bz); err != nil {
+		panic(err)
+	}
+	state[moduleName] = bz
+}
+
+// validateModuleState validates the genesis state of the module.
+func validateModuleState(moduleName string, bz json.RawMessage) error {
+	module, ok := app.ModuleBasics[moduleName]
+	if !ok {
+		return fmt.Errorf("unknown module %s", moduleName)
+	}
+	ecfg := encodingConfig()
+	if err := module.ValidateGenesis(ecfg.Codec, ecfg.TxConfig, bz); err != nil {
+		return fmt.Errorf("invalid %s genesis state: %w", moduleName, err)
+	}
+	return nil
+}
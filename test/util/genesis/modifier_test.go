@@ -0,0 +1,212 @@
+package genesis_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/celestiaorg/celestia-app/app"
+	"github.com/celestiaorg/celestia-app/app/encoding"
+	"github.com/celestiaorg/celestia-app/test/util/genesis"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModifiers(t *testing.T) {
+	ecfg := encoding.MakeConfig(app.ModuleBasics)
+	cdc := ecfg.Codec
+	granter := sdk.AccAddress(secp256k1.GenPrivKey().PubKey().Address())
+	grantee := sdk.AccAddress(secp256k1.GenPrivKey().PubKey().Address())
+	vesting := sdk.AccAddress(secp256k1.GenPrivKey().PubKey().Address())
+	operator := sdk.AccAddress(secp256k1.GenPrivKey().PubKey().Address())
+
+	stakingParams := stakingtypes.DefaultParams()
+	stakingParams.BondDenom = app.BondDenom
+	stakingParams.MaxValidators = 7
+	start := time.Now()
+
+	g := genesis.NewDefaultGenesis().
+		WithValidators(genesis.NewDefaultValidator("validator")).
+		WithAppVersion(1).
+		WithModifiers(
+			genesis.SetStakingParams(cdc, stakingParams),
+			genesis.AddVestingAccounts(cdc, genesis.VestingAccount{
+				Address: vesting,
+				Balance: sdk.NewCoins(sdk.NewInt64Coin(app.BondDenom, 100)),
+				Vesting: sdk.NewCoins(sdk.NewInt64Coin(app.BondDenom, 60)),
+				Start:   start,
+				End:     start.Add(time.Hour),
+			}),
+			genesis.AddFeeGrants(cdc, genesis.FeeGrant{
+				Granter:   granter,
+				Grantee:   grantee,
+				Allowance: &feegrant.BasicAllowance{},
+			}),
+			genesis.AddJailedValidators(cdc, genesis.JailedValidator{
+				Operator:     operator,
+				ConsensusKey: ed25519.GenPrivKey().PubKey(),
+				Stake:        sdk.NewInt(1_000_000),
+				Moniker:      "jailed",
+			}),
+			genesis.PatchModuleState(stakingtypes.ModuleName, json.RawMessage(`{"params": {"max_entries": 3}}`)),
+		)
+
+	doc, err := g.Export()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), doc.ConsensusParams.Version.AppVersion)
+	assert.Equal(t, g.GenesisTime, doc.GenesisTime)
+
+	var state map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(doc.AppState, &state))
+
+	var stakingGenState stakingtypes.GenesisState
+	cdc.MustUnmarshalJSON(state[stakingtypes.ModuleName], &stakingGenState)
+	assert.EqualValues(t, 7, stakingGenState.Params.MaxValidators)
+	assert.EqualValues(t, 3, stakingGenState.Params.MaxEntries)
+	require.Len(t, stakingGenState.Validators, 1)
+	assert.True(t, stakingGenState.Validators[0].Jailed)
+	assert.Equal(t, stakingtypes.Unbonded, stakingGenState.Validators[0].Status)
+	require.Len(t, stakingGenState.Delegations, 1)
+
+	var bankGenState banktypes.GenesisState
+	cdc.MustUnmarshalJSON(state[banktypes.ModuleName], &bankGenState)
+	balances := make(map[string]sdk.Coins)
+	for _, balance := range bankGenState.Balances {
+		balances[balance.Address] = balance.Coins
+	}
+	assert.Equal(t, sdk.NewInt(100), balances[vesting.String()].AmountOf(app.BondDenom))
+	notBondedPool := authtypes.NewModuleAddress(stakingtypes.NotBondedPoolName)
+	assert.Equal(t, sdk.NewInt(1_000_000), balances[notBondedPool.String()].AmountOf(app.BondDenom))
+
+	var feegrantGenState feegrant.GenesisState
+	cdc.MustUnmarshalJSON(state[feegrant.ModuleName], &feegrantGenState)
+	require.Len(t, feegrantGenState.Allowances, 1)
+	assert.Equal(t, grantee.String(), feegrantGenState.Allowances[0].Grantee)
+}
+
+func TestModifiersValidate(t *testing.T) {
+	cdc := encoding.MakeConfig(app.ModuleBasics).Codec
+	invalidParams := stakingtypes.DefaultParams()
+	invalidParams.BondDenom = ""
+	address := sdk.AccAddress(secp256k1.GenPrivKey().PubKey().Address())
+
+	tests := []struct {
+		name     string
+		modifier genesis.Modifier
+	}{
+		{
+			name:     "invalid staking params",
+			modifier: genesis.SetStakingParams(cdc, invalidParams),
+		},
+		{
+			name: "vesting more than the balance",
+			modifier: genesis.AddVestingAccounts(cdc, genesis.VestingAccount{
+				Address: address,
+				Balance: sdk.NewCoins(sdk.NewInt64Coin(app.BondDenom, 1)),
+				Vesting: sdk.NewCoins(sdk.NewInt64Coin(app.BondDenom, 2)),
+				End:     time.Now(),
+			}),
+		},
+		{
+			name:     "invalid patch",
+			modifier: genesis.PatchModuleState(stakingtypes.ModuleName, json.RawMessage(`{"params": {"bond_denom": ""}}`)),
+		},
+		{
+			name:     "unknown module",
+			modifier: genesis.PatchModuleState("unknown", json.RawMessage(`{}`)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := genesis.NewDefaultGenesis().
+				WithValidators(genesis.NewDefaultValidator("validator")).
+				WithModifiers(tt.modifier)
+			assert.Panics(t, func() {
+				_, _ = g.Export()
+			})
+		})
+	}
+}
+
+func TestExportRejectsUnsupportedAppVersion(t *testing.T) {
+	g := genesis.NewDefaultGenesis().
+		WithValidators(genesis.NewDefaultValidator("validator")).
+		WithAppVersion(1_000)
+	_, err := g.Export()
+	assert.Error(t, err)
+}
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		patch    string
+		expected string
+	}{
+		{
+			name:     "replace field",
+			target:   `{"a": "b"}`,
+			patch:    `{"a": "c"}`,
+			expected: `{"a": "c"}`,
+		},
+		{
+			name:     "add field",
+			target:   `{"a": "b"}`,
+			patch:    `{"b": "c"}`,
+			expected: `{"a": "b", "b": "c"}`,
+		},
+		{
+			name:     "remove field",
+			target:   `{"a": "b", "b": "c"}`,
+			patch:    `{"a": null}`,
+			expected: `{"b": "c"}`,
+		},
+		{
+			name:     "merge nested objects",
+			target:   `{"a": {"b": "c", "d": "e"}}`,
+			patch:    `{"a": {"d": "f"}}`,
+			expected: `{"a": {"b": "c", "d": "f"}}`,
+		},
+		{
+			name:     "replace arrays",
+			target:   `{"a": [1, 2]}`,
+			patch:    `{"a": [3]}`,
+			expected: `{"a": [3]}`,
+		},
+		{
+			name:     "replace non object target",
+			target:   `["a"]`,
+			patch:    `{"a": "b"}`,
+			expected: `{"a": "b"}`,
+		},
+		{
+			name:     "empty target",
+			target:   ``,
+			patch:    `{"a": {"b": null}}`,
+			expected: `{"a": {}}`,
+		},
+		{
+			name:     "keep large numbers",
+			target:   `{"a": 1}`,
+			patch:    `{"a": 18446744073709551615}`,
+			expected: `{"a": 18446744073709551615}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := genesis.MergePatch([]byte(tt.target), []byte(tt.patch))
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(result))
+		})
+	}
+
+	_, err := genesis.MergePatch([]byte(`{}`), []byte(`{"a":`))
+	assert.Error(t, err)
+}
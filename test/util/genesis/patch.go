@@ -0,0 +1,80 @@
+package genesis
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// PatchModuleState applies a JSON merge patch (RFC 7396) to the genesis state
+// of the module. It is meant for changes that no other modifier covers. Fields
+// of the patch replace the fields of the state, objects are merged recursively
+// and null removes a field. For example, the patch
+//
+//	{"params": {"max_validators": 5}}
+//
+// only changes the maximum number of validators of the staking module.
+func PatchModuleState(moduleName string, patch json.RawMessage) Modifier {
+	return func(state map[string]json.RawMessage) map[string]json.RawMessage {
+		bz, err := MergePatch(state[moduleName], patch)
+		if err != nil {
+			panic(fmt.Sprintf("patching %s genesis state: %v", moduleName, err))
+		}
+		if err := validateModuleState(moduleName, bz); err != nil {
+			panic(err)
+		}
+		state[moduleName] = bz
+		return state
+	}
+}
+
+// MergePatch applies the JSON merge patch (RFC 7396) to the target document
+// and returns the result. An empty target is treated as null.
+func MergePatch(target, patch []byte) ([]byte, error) {
+	patchValue, err := decodeJSON(patch)
+	if err != nil {
+		return nil, fmt.Errorf("decoding patch: %w", err)
+	}
+	var targetValue interface{}
+	if len(bytes.TrimSpace(target)) > 0 {
+		targetValue, err = decodeJSON(target)
+		if err != nil {
+			return nil, fmt.Errorf("decoding target: %w", err)
+		}
+	}
+	return json.Marshal(mergePatch(targetValue, patchValue))
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = make(map[string]interface{}, len(patchObject))
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergePatch(targetObject[key], value)
+	}
+	return targetObject
+}
+
+// decodeJSON decodes a JSON document while keeping numbers as they are
+// written, so that large integers don't lose precision.
+func decodeJSON(bz []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(bz))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after the JSON document")
+	}
+	return value, nil
+}
@@ -1,6 +1,7 @@
 package malicious
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/celestiaorg/celestia-app/app"
@@ -12,39 +13,33 @@ import (
 	dbm "github.com/tendermint/tm-db"
 )
 
-const (
-	// BehaviorConfigKey is the key used to set the malicious config.
-	BehaviorConfigKey = "behavior_config"
-
-	// OutOfOrderHandlerKey is the key used to set the out of order prepare
-	// proposal handler.
-	OutOfOrderHandlerKey = "out_of_order"
-)
+// BehaviorConfigKey is the key used to set the malicious config. Its value is
+// either a single BehaviorConfig or a []BehaviorConfig.
+const BehaviorConfigKey = "behavior_config"
 
 // BehaviorConfig defines the malicious behavior for the application. It
-// dictates the height at which the malicious behavior will start along with
-// what type of malicious behavior will be performed.
+// dictates the heights at which the malicious behavior will be performed along
+// with what type of malicious behavior will be performed. Behaviors whose
+// height ranges overlap are combined.
 type BehaviorConfig struct {
 	// HandlerName is the name of the malicious handler to use. All known
-	// handlers are defined in the PrepareProposalHandlerMap.
+	// handlers are defined in Behaviors.
 	HandlerName string `json:"handler_name"`
 	// StartHeight is the height at which the malicious behavior will start.
 	StartHeight int64 `json:"start_height"`
+	// EndHeight is the last height at which the malicious behavior is
+	// performed. If zero, the behavior doesn't end.
+	EndHeight int64 `json:"end_height"`
 }
 
-type PrepareProposalHandler func(req abci.RequestPrepareProposal) abci.ResponsePrepareProposal
-
-// PrepareProposalHandlerMap is a map of all the known prepare proposal handlers.
-func (a *App) PrepareProposalHandlerMap() map[string]PrepareProposalHandler {
-	return map[string]PrepareProposalHandler{
-		OutOfOrderHandlerKey: a.OutOfOrderPrepareProposal,
-	}
+// isActive returns whether the behavior is performed at the height.
+func (c BehaviorConfig) isActive(height int64) bool {
+	return height >= c.StartHeight && (c.EndHeight == 0 || height <= c.EndHeight)
 }
 
 type App struct {
 	*app.App
-	maliciousStartHeight      int64
-	malPrepareProposalHandler PrepareProposalHandler
+	behaviors []BehaviorConfig
 }
 
 func New(
@@ -60,30 +55,48 @@ func New(
 	goodApp := app.New(logger, db, traceStore, loadLatest, invCheckPeriod, encodingConfig, nil, appOpts, baseAppOptions...)
 	badApp := &App{App: goodApp}
 
-	// set the malicious prepare proposal handler if it is set in the app options
-	if malHanderName := appOpts.Get(BehaviorConfigKey); malHanderName != nil {
-		badApp.SetMaliciousBehavior(malHanderName.(BehaviorConfig))
+	// set the malicious behaviors if they are set in the app options
+	switch behavior := appOpts.Get(BehaviorConfigKey).(type) {
+	case BehaviorConfig:
+		badApp.SetMaliciousBehaviors(behavior)
+	case []BehaviorConfig:
+		badApp.SetMaliciousBehaviors(behavior...)
 	}
 
 	return badApp
 }
 
+// SetMaliciousBehavior replaces the malicious behaviors of the app with the
+// behavior.
 func (a *App) SetMaliciousBehavior(mcfg BehaviorConfig) {
-	// check if the handler is known
-	if _, ok := a.PrepareProposalHandlerMap()[mcfg.HandlerName]; !ok {
-		panic("unknown malicious prepare proposal handler")
+	a.SetMaliciousBehaviors(mcfg)
+}
+
+// SetMaliciousBehaviors replaces the malicious behaviors of the app. It panics
+// if a behavior is unknown.
+func (a *App) SetMaliciousBehaviors(mcfgs ...BehaviorConfig) {
+	for _, mcfg := range mcfgs {
+		if _, ok := Behaviors[mcfg.HandlerName]; !ok {
+			panic(fmt.Sprintf("unknown malicious prepare proposal handler %q", mcfg.HandlerName))
+		}
 	}
-	a.malPrepareProposalHandler = a.PrepareProposalHandlerMap()[mcfg.HandlerName]
-	a.maliciousStartHeight = mcfg.StartHeight
+	a.behaviors = mcfgs
 }
 
-// PrepareProposal overwrites the default app's method to use the configured
-// malicious behavior after a given height.
+// PrepareProposal overwrites the default app's method to combine the
+// malicious behaviors configured for the height of the proposal.
 func (a *App) PrepareProposal(req abci.RequestPrepareProposal) abci.ResponsePrepareProposal {
-	if a.LastBlockHeight()+1 >= a.maliciousStartHeight {
-		return a.malPrepareProposalHandler(req)
+	height := a.LastBlockHeight() + 1
+	behaviors := make([]Behavior, 0, len(a.behaviors))
+	for _, mcfg := range a.behaviors {
+		if mcfg.isActive(height) {
+			behaviors = append(behaviors, Behaviors[mcfg.HandlerName])
+		}
+	}
+	if len(behaviors) == 0 {
+		return a.App.PrepareProposal(req)
 	}
-	return a.App.PrepareProposal(req)
+	return a.MaliciousPrepareProposal(req, behaviors...)
 }
 
 // ProcessProposal overwrites the default app's method to auto accept any
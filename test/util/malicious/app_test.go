@@ -96,7 +96,7 @@ func TestMaliciousTestNode(t *testing.T) {
 	require.NoError(t, err)
 
 	rawSquare := shares.ToBytes(s)
-	eds, err := ExtendShares(rawSquare)
+	eds, err := ExtendShares(rawSquare, appconsts.LatestVersion)
 	require.NoError(t, err)
 
 	dah, err := da.NewDataAvailabilityHeader(eds)
@@ -106,7 +106,7 @@ func TestMaliciousTestNode(t *testing.T) {
 	correctSquare, err := square.Construct(block.Block.Txs.ToSliceOfBytes(), appconsts.LatestVersion, appconsts.DefaultSquareSizeUpperBound)
 	require.NoError(t, err)
 
-	goodEds, err := da.ExtendShares(shares.ToBytes(correctSquare))
+	goodEds, err := da.ExtendShares(shares.ToBytes(correctSquare), appconsts.LatestVersion)
 	require.NoError(t, err)
 
 	goodDah, err := da.NewDataAvailabilityHeader(goodEds)
@@ -0,0 +1,135 @@
+package malicious
+
+import (
+	"github.com/celestiaorg/celestia-app/pkg/blob"
+	"github.com/celestiaorg/celestia-app/x/upgrade"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+const (
+	// OutOfOrderHandlerKey is the key used to set the out of order prepare
+	// proposal handler.
+	OutOfOrderHandlerKey = "out_of_order"
+	// WrongSquareSizeHandlerKey proposes the honest square with a square size
+	// twice its actual size.
+	WrongSquareSizeHandlerKey = "wrong_square_size"
+	// TamperedShareIndexesHandlerKey proposes a square in which the share
+	// indexes of the PFBs don't point to their blobs.
+	TamperedShareIndexesHandlerKey = "tampered_share_indexes"
+	// PFBWithoutBlobsHandlerKey proposes the PFB of the first blob tx as a
+	// normal tx without its blobs.
+	PFBWithoutBlobsHandlerKey = "pfb_without_blobs"
+	// MisplacedUpgradeHandlerKey proposes an upgrade message that isn't the
+	// first transaction of the block.
+	MisplacedUpgradeHandlerKey = "misplaced_upgrade"
+	// DuplicateBlobTxHandlerKey proposes the first blob tx twice.
+	DuplicateBlobTxHandlerKey = "duplicate_blob_tx"
+	// InvalidPaddingHandlerKey proposes a square with additional padding in
+	// front of every blob.
+	InvalidPaddingHandlerKey = "invalid_padding"
+	// WrongDataHashHandlerKey proposes the honest square with a data hash that
+	// doesn't match it.
+	WrongDataHashHandlerKey = "wrong_data_hash"
+)
+
+// Behavior defines how a malicious proposer deviates from preparing an honest
+// proposal. Steps of a behavior that are not set are performed honestly.
+// Behaviors are combined by applying each of their steps in turn.
+type Behavior struct {
+	// ModifyTxs modifies the transactions of the proposal after they have been
+	// filtered and ordered the way an honest proposer would include them.
+	ModifyTxs func(a *App, txs [][]byte) ([][]byte, error)
+	// Export defines how the layout of the square deviates from the honest
+	// layout.
+	Export ExportConfig
+	// ModifyResponse modifies the proposal after its square and data root have
+	// been computed.
+	ModifyResponse func(resp *abci.ResponsePrepareProposal)
+}
+
+// Behaviors are all known malicious behaviors by their handler name.
+var Behaviors = map[string]Behavior{
+	OutOfOrderHandlerKey: {
+		Export: ExportConfig{SwapBlobs: true},
+	},
+	WrongSquareSizeHandlerKey: {
+		ModifyResponse: func(resp *abci.ResponsePrepareProposal) {
+			resp.BlockData.SquareSize *= 2
+		},
+	},
+	TamperedShareIndexesHandlerKey: {
+		Export: ExportConfig{ShareIndexOffset: 1},
+	},
+	PFBWithoutBlobsHandlerKey: {
+		ModifyTxs: pfbWithoutBlobs,
+	},
+	MisplacedUpgradeHandlerKey: {
+		ModifyTxs: misplacedUpgrade,
+	},
+	DuplicateBlobTxHandlerKey: {
+		ModifyTxs: duplicateBlobTx,
+	},
+	InvalidPaddingHandlerKey: {
+		Export: ExportConfig{ExtraPadding: 1},
+	},
+	WrongDataHashHandlerKey: {
+		ModifyResponse: func(resp *abci.ResponsePrepareProposal) {
+			hash := append([]byte{}, resp.BlockData.Hash...)
+			hash[0] ^= 0xFF
+			resp.BlockData.Hash = hash
+		},
+	},
+}
+
+// pfbWithoutBlobs replaces the first blob tx with the PFB it wraps. The PFB is
+// placed behind the normal txs.
+func pfbWithoutBlobs(_ *App, txs [][]byte) ([][]byte, error) {
+	normalTxs, blobTxs := splitTxs(txs)
+	if len(blobTxs) == 0 {
+		return txs, nil
+	}
+	blobTx, _ := blob.UnmarshalBlobTx(blobTxs[0])
+	normalTxs = append(normalTxs, blobTx.Tx)
+	return append(normalTxs, blobTxs[1:]...), nil
+}
+
+// misplacedUpgrade places an upgrade message to the next app version behind
+// the normal txs. If there are no normal txs, a second upgrade message is
+// placed in front of it so that it is never the first transaction.
+func misplacedUpgrade(a *App, txs [][]byte) ([][]byte, error) {
+	upgradeTx, err := upgrade.NewMsgVersionChange(a.GetTxConfig(), a.GetBaseApp().AppVersion()+1)
+	if err != nil {
+		return nil, err
+	}
+	normalTxs, blobTxs := splitTxs(txs)
+	if len(normalTxs) == 0 {
+		normalTxs = append(normalTxs, upgradeTx)
+	}
+	normalTxs = append(normalTxs, upgradeTx)
+	return append(normalTxs, blobTxs...), nil
+}
+
+// duplicateBlobTx includes the first blob tx twice.
+func duplicateBlobTx(_ *App, txs [][]byte) ([][]byte, error) {
+	normalTxs, blobTxs := splitTxs(txs)
+	if len(blobTxs) == 0 {
+		return txs, nil
+	}
+	blobTxs = append([][]byte{blobTxs[0]}, blobTxs...)
+	return append(normalTxs, blobTxs...), nil
+}
+
+// splitTxs splits the ordered txs of a proposal into its normal txs and blob
+// txs.
+func splitTxs(txs [][]byte) (normalTxs, blobTxs [][]byte) {
+	normalTxs = make([][]byte, 0, len(txs))
+	blobTxs = make([][]byte, 0, len(txs))
+	for _, tx := range txs {
+		if _, isBlobTx := blob.UnmarshalBlobTx(tx); isBlobTx {
+			blobTxs = append(blobTxs, tx)
+		} else {
+			normalTxs = append(normalTxs, tx)
+		}
+	}
+	return normalTxs, blobTxs
+}
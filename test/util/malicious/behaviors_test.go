@@ -0,0 +1,128 @@
+package malicious
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/celestiaorg/celestia-app/app"
+	"github.com/celestiaorg/celestia-app/app/encoding"
+	appns "github.com/celestiaorg/celestia-app/pkg/namespace"
+	testutil "github.com/celestiaorg/celestia-app/test/util"
+	"github.com/celestiaorg/celestia-app/test/util/blobfactory"
+	"github.com/celestiaorg/celestia-app/test/util/testfactory"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/log"
+	tmrand "github.com/tendermint/tendermint/libs/rand"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	coretypes "github.com/tendermint/tendermint/types"
+)
+
+// TestBehaviorsAreRejected tests that the honest ProcessProposal rejects the
+// proposals of every malicious behavior for the reason the behavior targets.
+func TestBehaviorsAreRejected(t *testing.T) {
+	badApp, txs, logs := setupBehaviorTest(t)
+
+	const dataRootReason = "proposed data root differs from calculated data root"
+	testCases := []struct {
+		behaviors []string
+		reason    string
+	}{
+		{[]string{OutOfOrderHandlerKey}, dataRootReason},
+		{[]string{WrongSquareSizeHandlerKey}, "proposed square size differs from calculated square size"},
+		{[]string{TamperedShareIndexesHandlerKey}, dataRootReason},
+		{[]string{PFBWithoutBlobsHandlerKey}, "has PFB but is not a blob tx"},
+		{[]string{MisplacedUpgradeHandlerKey}, "is not the first transaction"},
+		{[]string{DuplicateBlobTxHandlerKey}, "invalid PFB signature"},
+		{[]string{InvalidPaddingHandlerKey}, dataRootReason},
+		{[]string{WrongDataHashHandlerKey}, dataRootReason},
+		// transactions are checked before the square
+		{[]string{WrongSquareSizeHandlerKey, MisplacedUpgradeHandlerKey}, "is not the first transaction"},
+		{[]string{TamperedShareIndexesHandlerKey, InvalidPaddingHandlerKey}, dataRootReason},
+	}
+
+	for _, tc := range testCases {
+		t.Run(strings.Join(tc.behaviors, "+"), func(t *testing.T) {
+			mcfgs := make([]BehaviorConfig, len(tc.behaviors))
+			for i, name := range tc.behaviors {
+				mcfgs[i] = BehaviorConfig{HandlerName: name}
+			}
+			badApp.SetMaliciousBehaviors(mcfgs...)
+			logs.Reset()
+
+			require.Equal(t, abci.ResponseProcessProposal_REJECT, proposeAndProcess(badApp, txs).Result)
+			require.Contains(t, logs.String(), tc.reason)
+		})
+	}
+}
+
+// TestBehaviorHeightRange tests that behaviors are only performed within their
+// height range.
+func TestBehaviorHeightRange(t *testing.T) {
+	badApp, txs, _ := setupBehaviorTest(t)
+	height := badApp.LastBlockHeight() + 1
+
+	testCases := []struct {
+		start, end int64
+		result     abci.ResponseProcessProposal_Result
+	}{
+		{0, 0, abci.ResponseProcessProposal_REJECT},
+		{height, height, abci.ResponseProcessProposal_REJECT},
+		{height + 1, 0, abci.ResponseProcessProposal_ACCEPT},
+		{0, height - 1, abci.ResponseProcessProposal_ACCEPT},
+	}
+	for _, tc := range testCases {
+		badApp.SetMaliciousBehaviors(BehaviorConfig{HandlerName: WrongDataHashHandlerKey, StartHeight: tc.start, EndHeight: tc.end})
+		require.Equal(t, tc.result, proposeAndProcess(badApp, txs).Result, "start %d end %d", tc.start, tc.end)
+	}
+}
+
+func TestUnknownBehavior(t *testing.T) {
+	badApp, _, _ := setupBehaviorTest(t)
+	require.Panics(t, func() {
+		badApp.SetMaliciousBehaviors(BehaviorConfig{HandlerName: "unknown"})
+	})
+}
+
+// setupBehaviorTest returns a malicious app that logs to the returned buffer
+// along with transactions that send funds and pay for blobs in different
+// namespaces.
+func setupBehaviorTest(t *testing.T) (*App, [][]byte, *bytes.Buffer) {
+	enc := encoding.MakeConfig(app.ModuleEncodingRegisters...).TxConfig
+	accounts := testfactory.GenerateAccounts(5)
+	logs := &bytes.Buffer{}
+	testApp, kr := testutil.SetupTestAppWithGenesisValSetAndLogger(log.NewTMLogger(log.NewSyncWriter(logs)), app.DefaultConsensusParams(), accounts...)
+
+	infos := make([]blobfactory.AccountInfo, 2)
+	for i, acc := range accounts[1:3] {
+		accI := testutil.DirectQueryAccount(testApp, testfactory.GetAddress(kr, acc))
+		infos[i] = blobfactory.AccountInfo{AccountNum: accI.GetAccountNumber(), Sequence: accI.GetSequence()}
+	}
+	blobTxs := blobfactory.ManyMultiBlobTx(
+		t, enc, kr, testutil.ChainID, accounts[1:3], infos,
+		blobfactory.NestedBlobs(t, appns.RandomBlobNamespaces(tmrand.NewRand(), 2), [][]int{{1000}, {300}}),
+	)
+	sendTxs := testutil.SendTxsWithAccounts(t, testApp, enc, kr, 1000, accounts[0], accounts[3:], testutil.ChainID)
+
+	return &App{App: testApp}, append(coretypes.Txs(sendTxs).ToSliceOfBytes(), blobTxs...), logs
+}
+
+// proposeAndProcess prepares a proposal with the malicious app and processes
+// it with the honest app.
+func proposeAndProcess(badApp *App, txs [][]byte) abci.ResponseProcessProposal {
+	height := badApp.LastBlockHeight() + 1
+	resp := badApp.PrepareProposal(abci.RequestPrepareProposal{
+		BlockData: &tmproto.Data{Txs: txs},
+		ChainId:   testutil.ChainID,
+		Height:    height,
+	})
+	return badApp.App.ProcessProposal(abci.RequestProcessProposal{
+		BlockData: resp.BlockData,
+		Header: tmproto.Header{
+			Height:   height,
+			DataHash: resp.BlockData.Hash,
+			ChainID:  testutil.ChainID,
+		},
+	})
+}
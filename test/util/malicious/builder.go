@@ -55,11 +55,57 @@ func Construct(txs [][]byte, appVersion uint64, maxSquareSize int, efn ExportFn)
 	return efn(builder)
 }
 
-var _ ExportFn = OutOfOrderExport
+var (
+	_ ExportFn = OutOfOrderExport
+	_ ExportFn = TamperedShareIndexesExport
+	_ ExportFn = InvalidPaddingExport
+)
 
 // OutOfOrderExport constructs the square in a malicious and deterministic way
 // by swapping the first two blobs with different namespaces.
 func OutOfOrderExport(b *square.Builder) (square.Square, error) {
+	return ExportConfig{SwapBlobs: true}.Export(b)
+}
+
+// TamperedShareIndexesExport constructs the square with the honest layout but
+// records share indexes in the PFBs that are off by one from where their blobs
+// start.
+func TamperedShareIndexesExport(b *square.Builder) (square.Square, error) {
+	return ExportConfig{ShareIndexOffset: 1}.Export(b)
+}
+
+// InvalidPaddingExport constructs the square with an additional padding share
+// in front of every blob so that the blobs don't start where the share
+// commitment rules dictate.
+func InvalidPaddingExport(b *square.Builder) (square.Square, error) {
+	return ExportConfig{ExtraPadding: 1}.Export(b)
+}
+
+// ExportConfig defines how a square deviates from the honest layout. The zero
+// value constructs the honest square.
+type ExportConfig struct {
+	// SwapBlobs swaps the first two blobs with different namespaces.
+	SwapBlobs bool
+	// ExtraPadding is the number of padding shares added in front of every
+	// blob.
+	ExtraPadding int
+	// ShareIndexOffset is added to the share index of every blob recorded in
+	// the PFB that paid for it.
+	ShareIndexOffset uint32
+}
+
+// Merge combines the deviations of both configs.
+func (c ExportConfig) Merge(other ExportConfig) ExportConfig {
+	return ExportConfig{
+		SwapBlobs:        c.SwapBlobs || other.SwapBlobs,
+		ExtraPadding:     c.ExtraPadding + other.ExtraPadding,
+		ShareIndexOffset: c.ShareIndexOffset + other.ShareIndexOffset,
+	}
+}
+
+// Export constructs the square following the layout of square.Builder.Export
+// with the deviations of the config applied.
+func (c ExportConfig) Export(b *square.Builder) (square.Square, error) {
 	// if there are no transactions, return an empty square
 	if b.IsEmpty() {
 		return square.EmptySquare(), nil
@@ -68,7 +114,7 @@ func OutOfOrderExport(b *square.Builder) (square.Square, error) {
 	// calculate the square size.
 	// NOTE: A future optimization could be to recalculate the currentSize based on the actual
 	// interblob padding used when the blobs are correctly ordered instead of using worst case padding.
-	ss := inclusion.BlobMinSquareSize(b.CurrentSize())
+	ss := inclusion.BlobMinSquareSize(b.CurrentSize() + len(b.Blobs)*c.ExtraPadding)
 
 	// sort the blobs in order of namespace. We use slice stable here to respect the
 	// order of multiple blobs within a namespace as per the priority of the PFB
@@ -76,7 +122,7 @@ func OutOfOrderExport(b *square.Builder) (square.Square, error) {
 		return bytes.Compare(b.Blobs[i].Blob.Namespace().Bytes(), b.Blobs[j].Blob.Namespace().Bytes()) < 0
 	})
 
-	if len(b.Blobs) > 1 {
+	if c.SwapBlobs && len(b.Blobs) > 1 {
 		// iterate through each blob and find the first two that have different
 		// namespaces and swap them.
 		for i := 0; i < len(b.Blobs)-1; i++ {
@@ -103,19 +149,19 @@ func OutOfOrderExport(b *square.Builder) (square.Square, error) {
 	for i, element := range b.Blobs {
 		// NextShareIndex returned where the next blob should start so as to comply with the share commitment rules
 		// We fill out the remaining
-		cursor = inclusion.NextShareIndex(cursor, element.NumShares, b.SubtreeRootThreshold())
+		cursor = inclusion.NextShareIndex(cursor, element.NumShares, b.SubtreeRootThreshold()) + c.ExtraPadding
 		if i == 0 {
 			nonReservedStart = cursor
 		}
 
 		// defensively check that the actual padding never exceeds the max padding initially allocated for it
 		padding := cursor - endOfLastBlob
-		if padding > element.MaxPadding {
+		if padding > element.MaxPadding+c.ExtraPadding {
 			return nil, fmt.Errorf("blob has %d padding shares, but %d was the max possible", padding, element.MaxPadding)
 		}
 
 		// record the starting share index of the blob in the PFB that paid for it
-		b.Pfbs[element.PfbIndex].ShareIndexes[element.BlobIndex] = uint32(cursor)
+		b.Pfbs[element.PfbIndex].ShareIndexes[element.BlobIndex] = uint32(cursor) + c.ShareIndexOffset
 		// If this is not the first blob, we add padding by writing padded shares to the previous blob
 		// (which could be of a different namespace)
 		if i > 0 {
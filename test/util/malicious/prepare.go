@@ -5,6 +5,7 @@ import (
 	"github.com/celestiaorg/celestia-app/app/ante"
 	"github.com/celestiaorg/celestia-app/pkg/da"
 	"github.com/celestiaorg/celestia-app/pkg/shares"
+	"github.com/celestiaorg/celestia-app/pkg/square"
 	abci "github.com/tendermint/tendermint/abci/types"
 	core "github.com/tendermint/tendermint/proto/tendermint/types"
 )
@@ -15,6 +16,12 @@ import (
 // for. It will swap the order of two blobs in the square and then use the
 // modified nmt to create a commitment over the modified square.
 func (a *App) OutOfOrderPrepareProposal(req abci.RequestPrepareProposal) abci.ResponsePrepareProposal {
+	return a.MaliciousPrepareProposal(req, Behaviors[OutOfOrderHandlerKey])
+}
+
+// MaliciousPrepareProposal prepares the proposal block data like the honest
+// PrepareProposal but deviates from it as defined by the behaviors.
+func (a *App) MaliciousPrepareProposal(req abci.RequestPrepareProposal, behaviors ...Behavior) abci.ResponsePrepareProposal {
 	// create a context using a branch of the state and loaded using the
 	// proposal height and chain-id
 	sdkCtx := a.NewProposalContext(core.Header{ChainID: a.GetChainID(), Height: a.LastBlockHeight() + 1})
@@ -34,9 +41,29 @@ func (a *App) OutOfOrderPrepareProposal(req abci.RequestPrepareProposal) abci.Re
 
 	txs := app.FilterTxs(a.Logger(), sdkCtx, handler, a.GetTxConfig(), req.BlockData.Txs)
 
-	// build the square from the set of valid and prioritised transactions.
-	// The txs returned are the ones used in the square and block
-	dataSquare, txs, err := Build(txs, a.GetBaseApp().AppVersion(), a.GovSquareSizeUpperBound(sdkCtx), OutOfOrderExport)
+	// order the transactions and drop those that don't fit like the honest
+	// square builder would
+	appVersion := a.GetBaseApp().AppVersion()
+	maxSquareSize := a.GovSquareSizeUpperBound(sdkCtx)
+	_, txs, err := square.Build(txs, appVersion, maxSquareSize)
+	if err != nil {
+		panic(err)
+	}
+
+	var export ExportConfig
+	for _, behavior := range behaviors {
+		if behavior.ModifyTxs != nil {
+			txs, err = behavior.ModifyTxs(a, txs)
+			if err != nil {
+				panic(err)
+			}
+		}
+		export = export.Merge(behavior.Export)
+	}
+
+	// construct the square from the exact set of transactions using the
+	// malicious layout
+	dataSquare, err := Construct(txs, appVersion, maxSquareSize, export.Export)
 	if err != nil {
 		panic(err)
 	}
@@ -44,7 +71,7 @@ func (a *App) OutOfOrderPrepareProposal(req abci.RequestPrepareProposal) abci.Re
 	// erasure the data square which we use to create the data root. Note: this
 	// is using a modified version of nmt where the order of the namepspaces is
 	// not enforced.
-	eds, err := ExtendShares(shares.ToBytes(dataSquare))
+	eds, err := ExtendShares(shares.ToBytes(dataSquare), appVersion)
 	if err != nil {
 		a.Logger().Error(
 			"failure to erasure the data square while creating a proposal block",
@@ -63,11 +90,17 @@ func (a *App) OutOfOrderPrepareProposal(req abci.RequestPrepareProposal) abci.Re
 
 	// tendermint doesn't need to use any of the erasure data, as only the
 	// protobuf encoded version of the block data is gossiped.
-	return abci.ResponsePrepareProposal{
+	resp := abci.ResponsePrepareProposal{
 		BlockData: &core.Data{
 			Txs:        txs,
 			SquareSize: uint64(dataSquare.Size()),
 			Hash:       dah.Hash(),
 		},
 	}
+	for _, behavior := range behaviors {
+		if behavior.ModifyResponse != nil {
+			behavior.ModifyResponse(&resp)
+		}
+	}
+	return resp
 }
@@ -33,12 +33,12 @@ func OutOfOrderNamespaceConfig(startHeight int64) *testnode.Config {
 }
 
 // TestNodeConfig returns a testnode config with the malicous application and
-// provided behavior set in the app options.
-func TestNodeConfig(behavior BehaviorConfig) *testnode.Config {
+// provided behaviors set in the app options.
+func TestNodeConfig(behaviors ...BehaviorConfig) *testnode.Config {
 	cfg := testnode.DefaultConfig().
 		WithAppCreator(NewAppServer)
 
-	cfg.AppOptions.Set(BehaviorConfigKey, behavior)
+	cfg.AppOptions.Set(BehaviorConfigKey, behaviors)
 	return cfg
 }
 
@@ -58,7 +58,9 @@ func (c constructor) NewTree(_ rsmt2d.Axis, axisIndex uint) rsmt2d.Tree {
 	return &newTree
 }
 
-func ExtendShares(s [][]byte) (*rsmt2d.ExtendedDataSquare, error) {
+// ExtendShares erasure codes the shares with the codec of the app version and
+// computes the roots with the malicious tree.
+func ExtendShares(s [][]byte, appVersion uint64) (*rsmt2d.ExtendedDataSquare, error) {
 	// Check that the length of the square is a power of 2.
 	if !shares.IsPowerOfTwo(len(s)) {
 		return nil, fmt.Errorf("number of shares is not a power of 2: got %d", len(s))
@@ -67,5 +69,5 @@ func ExtendShares(s [][]byte) (*rsmt2d.ExtendedDataSquare, error) {
 
 	// here we construct a tree
 	// Note: uses the nmt wrapper to construct the tree.
-	return rsmt2d.ComputeExtendedDataSquare(s, appconsts.DefaultCodec(), NewConstructor(uint64(squareSize)))
+	return rsmt2d.ComputeExtendedDataSquare(s, appconsts.Codec(appVersion), NewConstructor(uint64(squareSize)))
 }
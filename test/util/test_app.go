@@ -50,6 +50,12 @@ func (ao EmptyAppOptions) Get(_ string) interface{} {
 // is bonded with a delegation of one consensus engine unit in the default token
 // of the app from first genesis account. A no-op logger is set in app.
 func SetupTestAppWithGenesisValSet(cparams *tmproto.ConsensusParams, genAccounts ...string) (*app.App, keyring.Keyring) {
+	return SetupTestAppWithGenesisValSetAndLogger(log.NewNopLogger(), cparams, genAccounts...)
+}
+
+// SetupTestAppWithGenesisValSetAndLogger is like SetupTestAppWithGenesisValSet
+// but sets the logger in the app, allowing tests to inspect what the app logs.
+func SetupTestAppWithGenesisValSetAndLogger(logger log.Logger, cparams *tmproto.ConsensusParams, genAccounts ...string) (*app.App, keyring.Keyring) {
 	// var cache sdk.MultiStorePersistentCache
 	// EmptyAppOptions is a stub implementing AppOptions
 	emptyOpts := EmptyAppOptions{}
@@ -59,7 +65,7 @@ func SetupTestAppWithGenesisValSet(cparams *tmproto.ConsensusParams, genAccounts
 	encCfg := encoding.MakeConfig(app.ModuleEncodingRegisters...)
 
 	testApp := app.New(
-		log.NewNopLogger(), db, nil, true,
+		logger, db, nil, true,
 		cast.ToUint(emptyOpts.Get(server.FlagInvCheckPeriod)),
 		encCfg,
 		nil,
@@ -0,0 +1,179 @@
+package testnode
+
+import "time"
+
+// killTimeout is how long Kill waits for a node to crash before it stops the
+// node regardless.
+const killTimeout = 30 * time.Second
+
+// LinkFaults are the faults of the messages that one node of a Network sends
+// to another.
+type LinkFaults struct {
+	// Delay is the latency added to every message.
+	Delay time.Duration
+	// DropRate is the probability with which a chunk of data forces the nodes
+	// to reconnect. Individual messages can't be dropped as connections
+	// between nodes are authenticated, so the connection is closed instead
+	// and comet gossips its state again once the nodes have reconnected.
+	DropRate float64
+}
+
+// SetLinkFaults sets the faults of the messages that the node from sends to
+// the node to. The zero value removes all faults.
+func (n *Network) SetLinkFaults(from, to int, faults LinkFaults) {
+	n.faults.mtx.Lock()
+	defer n.faults.mtx.Unlock()
+	n.faults.links[[2]int{from, to}] = faults
+}
+
+// SetAllLinkFaults sets the faults of the messages between all nodes.
+func (n *Network) SetAllLinkFaults(faults LinkFaults) {
+	for from := range n.nodes {
+		for to := range n.nodes {
+			if from != to {
+				n.SetLinkFaults(from, to, faults)
+			}
+		}
+	}
+}
+
+// Partition splits the network into groups of nodes, given by their indexes.
+// Nodes of different groups can't communicate until the network is healed.
+// Nodes that are in no group are isolated from all other nodes.
+func (n *Network) Partition(groups ...[]int) {
+	group := make(map[int]int)
+	for g, nodes := range groups {
+		for _, i := range nodes {
+			group[i] = g
+		}
+	}
+
+	n.faults.mtx.Lock()
+	for a := range n.nodes {
+		for b := a + 1; b < len(n.nodes); b++ {
+			groupA, okA := group[a]
+			groupB, okB := group[b]
+			if !okA || !okB || groupA != groupB {
+				n.faults.partitioned[pair(a, b)] = true
+			}
+		}
+	}
+	n.faults.mtx.Unlock()
+
+	for _, l := range n.links {
+		if n.faults.isPartitioned(l.from, l.to) {
+			l.cut()
+		}
+	}
+}
+
+// Isolate disconnects the node from all other nodes until the network is
+// healed.
+func (n *Network) Isolate(i int) {
+	n.faults.mtx.Lock()
+	for j := range n.nodes {
+		if j != i {
+			n.faults.partitioned[pair(i, j)] = true
+		}
+	}
+	n.faults.mtx.Unlock()
+
+	for _, l := range n.links {
+		if l.from == i || l.to == i {
+			l.cut()
+		}
+	}
+}
+
+// Heal removes all partitions. Nodes reconnect with the backoff of comet,
+// which may take a few seconds.
+func (n *Network) Heal() {
+	n.faults.mtx.Lock()
+	defer n.faults.mtx.Unlock()
+	n.faults.partitioned = make(map[[2]int]bool)
+}
+
+// Pause freezes the communication of the node: messages to and from the node
+// are held back, as if the node was suspended, until it is resumed. The node
+// itself keeps running.
+func (n *Network) Pause(i int) {
+	n.faults.mtx.Lock()
+	defer n.faults.mtx.Unlock()
+	if _, ok := n.faults.paused[i]; !ok {
+		n.faults.paused[i] = make(chan struct{})
+	}
+}
+
+// Resume delivers the messages that were held back while the node was paused
+// and resumes its communication.
+func (n *Network) Resume(i int) {
+	n.faults.mtx.Lock()
+	defer n.faults.mtx.Unlock()
+	if resumed, ok := n.faults.paused[i]; ok {
+		close(resumed)
+		delete(n.faults.paused, i)
+	}
+}
+
+// SetClockOffset sets the clock of the node ahead by the offset when it signs
+// votes. Block times are the weighted median of the vote timestamps of the
+// validators, so a skewed clock affects block times if the validator holds
+// enough voting power. Clocks that are behind can't be simulated as comet
+// doesn't let vote timestamps go back before the voted block, so set the
+// clocks of the other nodes ahead instead.
+func (n *Node) SetClockOffset(offset time.Duration) {
+	if offset < 0 {
+		panic("clock offsets must not be negative")
+	}
+	n.faults.clockOffset.Store(int64(offset))
+}
+
+// SetDiskFull makes all writes to the database of the application fail with
+// ENOSPC while full is true. The node halts when it fails to commit a block
+// and must be restarted once the disk has been freed. It must only be set on
+// nodes that are in consensus, as comet doesn't recover from the failure while
+// the node syncs blocks.
+func (n *Node) SetDiskFull(full bool) {
+	n.faults.diskFull.Store(full)
+}
+
+// CrashOnPrepareProposal makes the node crash once it prepares a proposal at
+// or above the height. A crashed node halts consensus and must be restarted.
+func (n *Node) CrashOnPrepareProposal(height int64) {
+	n.faults.crashAt(prepareProposal, height)
+}
+
+// CrashOnProcessProposal makes the node crash once it processes a proposal at
+// or above the height. A crashed node halts consensus and must be restarted.
+func (n *Node) CrashOnProcessProposal(height int64) {
+	n.faults.crashAt(processProposal, height)
+}
+
+// Crashed returns a channel that is closed when the node crashes.
+func (n *Node) Crashed() <-chan struct{} {
+	return n.faults.crashedChan()
+}
+
+// Kill crashes the node the next time it processes a proposal, halting it in
+// the middle of a height, and stops it. The node is stopped regardless if it
+// doesn't crash within the killTimeout, for example because the network has
+// halted.
+func (n *Node) Kill() error {
+	n.CrashOnProcessProposal(0)
+	select {
+	case <-n.Crashed():
+	case <-time.After(killTimeout):
+	}
+	n.faults.mtx.Lock()
+	delete(n.faults.crashes, processProposal)
+	n.faults.mtx.Unlock()
+	return n.Stop()
+}
+
+// Restart stops and starts the node.
+func (n *Node) Restart() error {
+	if err := n.Stop(); err != nil {
+		return err
+	}
+	return n.Start()
+}
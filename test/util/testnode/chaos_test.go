@@ -0,0 +1,333 @@
+package testnode
+
+import (
+	"testing"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	"github.com/celestiaorg/celestia-app/app"
+	"github.com/celestiaorg/celestia-app/app/encoding"
+	"github.com/celestiaorg/celestia-app/test/util/genesis"
+	"github.com/celestiaorg/celestia-app/x/blobstream"
+	bstypes "github.com/celestiaorg/celestia-app/x/blobstream/types"
+	minttypes "github.com/celestiaorg/celestia-app/x/mint/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+// newChaosNetwork starts a network of four validators with equal voting
+// power, so that any three of them can make progress.
+func newChaosNetwork(t *testing.T, modifiers ...genesis.Modifier) *Network {
+	if testing.Short() {
+		t.Skip("skipping chaos test in short mode.")
+	}
+	cfg := DefaultConfig().WithTimeoutCommit(100 * time.Millisecond).WithModifiers(modifiers...)
+	network := NewMultiNodeNetwork(t, cfg, 4)
+	cctx := network.Node(0).Context()
+	_, err := cctx.WaitForHeight(3)
+	require.NoError(t, err)
+	return network
+}
+
+func TestIsolatedMinority(t *testing.T) {
+	network := newChaosNetwork(t)
+	network.Isolate(3)
+	isolated := network.Node(3).Context()
+	isolatedHeight, err := isolated.LatestHeight()
+	require.NoError(t, err)
+
+	// liveness: the remaining validators hold more than 2/3 of the power
+	height := requireProgress(t, network.Node(0), 5)
+	// the isolated node can't commit blocks on its own
+	stalledHeight, err := isolated.LatestHeight()
+	require.NoError(t, err)
+	assert.LessOrEqual(t, stalledHeight, isolatedHeight+1)
+
+	network.Heal()
+	_, err = isolated.WaitForHeight(height + 2)
+	require.NoError(t, err)
+	requireSameChain(t, network.Nodes(), height)
+}
+
+func TestEvenSplitHalts(t *testing.T) {
+	network := newChaosNetwork(t)
+	network.Partition([]int{0, 1}, []int{2, 3})
+	before := latestHeights(t, network.Nodes())
+
+	// neither half holds more than 2/3 of the power, so no blocks beyond the
+	// ones in flight are committed
+	time.Sleep(5 * time.Second)
+	after := latestHeights(t, network.Nodes())
+	for i := range before {
+		assert.LessOrEqual(t, after[i], before[i]+1, "node %d", i)
+	}
+
+	network.Heal()
+	height := requireProgress(t, network.Node(0), 3)
+	requireSameChain(t, network.Nodes(), height)
+}
+
+func TestLossyLinks(t *testing.T) {
+	network := newChaosNetwork(t)
+	network.SetAllLinkFaults(LinkFaults{Delay: 50 * time.Millisecond})
+	requireProgress(t, network.Node(0), 3)
+
+	// connections of the first node keep breaking
+	for to := 1; to < 4; to++ {
+		network.SetLinkFaults(0, to, LinkFaults{Delay: 50 * time.Millisecond, DropRate: 0.01})
+	}
+	height := requireProgress(t, network.Node(1), 3)
+
+	network.SetAllLinkFaults(LinkFaults{})
+	requireHeight(t, network.Node(0), height+2)
+	requireSameChain(t, network.Nodes(), height)
+}
+
+func TestPausedNode(t *testing.T) {
+	network := newChaosNetwork(t)
+	network.Pause(0)
+	height := requireProgress(t, network.Node(1), 5)
+
+	network.Resume(0)
+	requireHeight(t, network.Node(0), height+2)
+	requireSameChain(t, network.Nodes(), height)
+}
+
+func TestCrashDuringProposal(t *testing.T) {
+	type test struct {
+		name  string
+		crash func(n *Node, height int64)
+	}
+	tests := []test{
+		{
+			name:  "prepare proposal",
+			crash: (*Node).CrashOnPrepareProposal,
+		},
+		{
+			name:  "process proposal",
+			crash: (*Node).CrashOnProcessProposal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			network := newChaosNetwork(t)
+			crashing := network.Node(0)
+			height := latestHeights(t, []*Node{crashing})[0]
+
+			tt.crash(crashing, height+1)
+			select {
+			case <-crashing.Crashed():
+			case <-time.After(DefaultTimeout):
+				t.Fatal("node didn't crash")
+			}
+			height = requireProgress(t, network.Node(1), 3)
+
+			// the node recovers from its write ahead log and catches up
+			require.NoError(t, crashing.Restart())
+			requireHeight(t, crashing, height+2)
+			requireSameChain(t, network.Nodes(), height)
+		})
+	}
+}
+
+func TestKillAndRestart(t *testing.T) {
+	network := newChaosNetwork(t)
+	killed := network.Node(2)
+	require.NoError(t, killed.Kill())
+	require.False(t, killed.IsRunning())
+	height := requireProgress(t, network.Node(0), 3)
+
+	require.NoError(t, killed.Start())
+	requireHeight(t, killed, height+2)
+	requireSameChain(t, network.Nodes(), height)
+}
+
+func TestClockSkew(t *testing.T) {
+	network := newChaosNetwork(t)
+	const offset = time.Hour
+
+	// a single skewed validator doesn't move the median of the vote
+	// timestamps
+	network.Node(3).SetClockOffset(offset)
+	requireProgress(t, network.Node(0), 3)
+	cctx := network.Node(0).Context()
+	blockTime, err := cctx.LatestTimestamp()
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), blockTime, offset/2)
+
+	// a majority of skewed validators does
+	for i := 0; i < 2; i++ {
+		network.Node(i).SetClockOffset(offset)
+	}
+	height := requireProgress(t, network.Node(0), 3)
+	blockTime, err = cctx.LatestTimestamp()
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(offset), blockTime, offset/2)
+	requireIncreasingBlockTimes(t, network.Node(0), height)
+	requireSameChain(t, network.Nodes(), height)
+
+	// the block provisions follow the block times, so the blocks that jumped
+	// ahead minted the provisions of the skew
+	minted, annualProvisions := requireBlockProvisions(t, network.Node(0), height)
+	skewProvision := annualProvisions.MulInt64(int64(offset / 2)).QuoInt64(minttypes.NanosecondsPerYear).TruncateInt()
+	assert.True(t, minted.GT(skewProvision), "minted %s, expected more than %s", minted, skewProvision)
+}
+
+func TestClockSkewPrunesAttestations(t *testing.T) {
+	cdc := encoding.MakeConfig(app.ModuleEncodingRegisters...).Codec
+	network := newChaosNetwork(t, genesis.SetDataCommitmentWindow(cdc, bstypes.MinimumDataCommitmentWindow))
+	cctx := network.Node(0).Context()
+	query := bstypes.NewQueryClient(cctx.GRPCClient)
+
+	// the valset of the genesis validators is followed by the first data
+	// commitment
+	_, err := cctx.WaitForHeightWithTimeout(bstypes.MinimumDataCommitmentWindow+1, 2*time.Minute)
+	require.NoError(t, err)
+	latest, err := query.LatestAttestationNonce(cctx.GoContext(), &bstypes.QueryLatestAttestationNonceRequest{})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, latest.Nonce, uint64(2))
+
+	// block times of a majority of skewed validators jump past the expiry of
+	// all attestations but the latest one, which is never pruned
+	for i := 0; i < 3; i++ {
+		network.Node(i).SetClockOffset(blobstream.AttestationExpiryTime + 24*time.Hour)
+	}
+	height := requireProgress(t, network.Node(0), 3)
+	earliest, err := query.EarliestAttestationNonce(cctx.GoContext(), &bstypes.QueryEarliestAttestationNonceRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, latest.Nonce, earliest.Nonce)
+	requireIncreasingBlockTimes(t, network.Node(0), height)
+	requireSameChain(t, network.Nodes(), height)
+}
+
+func TestDiskFull(t *testing.T) {
+	network := newChaosNetwork(t)
+	full := network.Node(3)
+	full.SetDiskFull(true)
+
+	// the node halts when it fails to commit a block while the others make
+	// progress
+	height := requireProgress(t, network.Node(0), 5)
+	stalledHeight := latestHeights(t, []*Node{full})[0]
+	assert.Less(t, stalledHeight, height)
+
+	full.SetDiskFull(false)
+	require.NoError(t, full.Restart())
+	requireHeight(t, full, height+2)
+	requireSameChain(t, network.Nodes(), height)
+}
+
+// requireProgress waits until the node has committed blocks more blocks and
+// returns its height.
+func requireProgress(t *testing.T, n *Node, blocks int64) int64 {
+	t.Helper()
+	cctx := n.Context()
+	height, err := cctx.LatestHeight()
+	require.NoError(t, err)
+	height, err = cctx.WaitForHeight(height + blocks)
+	require.NoError(t, err)
+	return height
+}
+
+// requireHeight waits until the node has committed the block at the height.
+func requireHeight(t *testing.T, n *Node, height int64) {
+	t.Helper()
+	cctx := n.Context()
+	_, err := cctx.WaitForHeight(height)
+	require.NoError(t, err)
+}
+
+func latestHeights(t *testing.T, nodes []*Node) []int64 {
+	t.Helper()
+	heights := make([]int64, len(nodes))
+	for i, n := range nodes {
+		cctx := n.Context()
+		height, err := cctx.LatestHeight()
+		require.NoError(t, err)
+		heights[i] = height
+	}
+	return heights
+}
+
+// requireSameChain checks that all nodes committed the same blocks up to the
+// height. Nodes that lag behind are given time to catch up.
+func requireSameChain(t *testing.T, nodes []*Node, height int64) {
+	t.Helper()
+	for _, n := range nodes {
+		requireHeight(t, n, height)
+	}
+	for h := int64(1); h <= height; h++ {
+		var expected []byte
+		for i, n := range nodes {
+			cctx := n.Context()
+			block, err := cctx.Client.Block(cctx.GoContext(), &h)
+			require.NoError(t, err)
+			if i == 0 {
+				expected = block.BlockID.Hash
+				continue
+			}
+			require.Equal(t, expected, []byte(block.BlockID.Hash), "node %d committed a different block at height %d", i, h)
+		}
+	}
+}
+
+// requireBlockProvisions checks that every block up to the height minted the
+// provision of the time since the previous block. It returns the total amount
+// minted and the annual provisions.
+func requireBlockProvisions(t *testing.T, n *Node, height int64) (sdkmath.Int, sdk.Dec) {
+	t.Helper()
+	cctx := n.Context()
+	total := sdkmath.ZeroInt()
+	var annualProvisions sdk.Dec
+	// the first block has no previous block time and mints nothing
+	for h := int64(2); h <= height; h++ {
+		previousHeight := h - 1
+		previous, err := cctx.Client.Block(cctx.GoContext(), &previousHeight)
+		require.NoError(t, err)
+		current, err := cctx.Client.Block(cctx.GoContext(), &h)
+		require.NoError(t, err)
+		results, err := cctx.Client.BlockResults(cctx.GoContext(), &h)
+		require.NoError(t, err)
+
+		attributes := mintAttributes(results.BeginBlockEvents)
+		require.NotEmpty(t, attributes, "no mint event at height %d", h)
+		annualProvisions, err = sdk.NewDecFromStr(attributes[minttypes.AttributeKeyAnnualProvisions])
+		require.NoError(t, err)
+		minter := minttypes.Minter{AnnualProvisions: annualProvisions, BondDenom: app.BondDenom}
+		expected, err := minter.CalculateBlockProvision(current.Block.Time, previous.Block.Time)
+		require.NoError(t, err)
+		require.Equal(t, expected.Amount.String(), attributes[sdk.AttributeKeyAmount], "block provision at height %d", h)
+		total = total.Add(expected.Amount)
+	}
+	return total, annualProvisions
+}
+
+// mintAttributes returns the attributes of the mint event.
+func mintAttributes(events []abci.Event) map[string]string {
+	attributes := make(map[string]string)
+	for _, event := range events {
+		if event.Type != minttypes.EventTypeMint {
+			continue
+		}
+		for _, attr := range event.Attributes {
+			attributes[string(attr.Key)] = string(attr.Value)
+		}
+	}
+	return attributes
+}
+
+// requireIncreasingBlockTimes checks that the block times of the node increase
+// up to the height.
+func requireIncreasingBlockTimes(t *testing.T, n *Node, height int64) {
+	t.Helper()
+	cctx := n.Context()
+	var previous time.Time
+	for h := int64(1); h <= height; h++ {
+		block, err := cctx.Client.Block(cctx.GoContext(), &h)
+		require.NoError(t, err)
+		require.True(t, block.Block.Time.After(previous), "block time at height %d doesn't increase", h)
+		previous = block.Block.Time
+	}
+}
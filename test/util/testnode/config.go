@@ -142,6 +142,15 @@ func (ao *KVAppOptions) Set(o string, v interface{}) {
 	ao.options[o] = v
 }
 
+// clone returns a copy of the options that can be modified independently.
+func (ao *KVAppOptions) clone() *KVAppOptions {
+	opts := &KVAppOptions{options: make(map[string]interface{}, len(ao.options))}
+	for o, v := range ao.options {
+		opts.options[o] = v
+	}
+	return opts
+}
+
 // DefaultAppOptions returns the default application options.
 func DefaultAppOptions() *KVAppOptions {
 	opts := &KVAppOptions{options: make(map[string]interface{})}
@@ -0,0 +1,186 @@
+package testnode
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	srvtypes "github.com/cosmos/cosmos-sdk/server/types"
+	abci "github.com/tendermint/tendermint/abci/types"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	"github.com/tendermint/tendermint/types"
+	tmtime "github.com/tendermint/tendermint/types/time"
+	dbm "github.com/tendermint/tm-db"
+)
+
+// errDiskFull is returned by the writes to the database of the application of
+// a node whose disk is full.
+var errDiskFull = fmt.Errorf("simulated disk full: %w", syscall.ENOSPC)
+
+// nodeFaults are the faults injected into a single node of a Network. They
+// outlive restarts of the node.
+type nodeFaults struct {
+	clockOffset atomic.Int64
+	diskFull    atomic.Bool
+
+	mtx sync.Mutex
+	// crashes maps the ABCI methods that crash the node to the height from
+	// which they crash it.
+	crashes map[string]int64
+	// crashed is closed when the node crashes and replaced when it is
+	// restarted.
+	crashed chan struct{}
+	// didCrash is true if crashed has been closed.
+	didCrash bool
+}
+
+func newNodeFaults() *nodeFaults {
+	return &nodeFaults{
+		crashes: make(map[string]int64),
+		crashed: make(chan struct{}),
+	}
+}
+
+const (
+	prepareProposal = "PrepareProposal"
+	processProposal = "ProcessProposal"
+)
+
+// crashAt makes the node crash the next time method is called at or above the
+// height.
+func (f *nodeFaults) crashAt(method string, height int64) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.crashes[method] = height
+}
+
+// maybeCrash panics if the method is set to crash at the height. Comet recovers
+// from panics in the consensus routine by halting consensus, which is how a
+// crash of the node looks to the rest of the network.
+func (f *nodeFaults) maybeCrash(method string, height int64) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	crashHeight, ok := f.crashes[method]
+	if !ok || height < crashHeight {
+		return
+	}
+	delete(f.crashes, method)
+	if !f.didCrash {
+		close(f.crashed)
+		f.didCrash = true
+	}
+	panic(fmt.Sprintf("simulated crash in %s at height %d", method, height))
+}
+
+// reset prepares the faults for a restart of the node.
+func (f *nodeFaults) reset() {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if f.didCrash {
+		f.crashed = make(chan struct{})
+		f.didCrash = false
+	}
+}
+
+func (f *nodeFaults) crashedChan() <-chan struct{} {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return f.crashed
+}
+
+// faultyApp crashes the node in the ABCI methods configured in its faults.
+// Only methods that comet calls from its consensus routine may crash, as a
+// panic anywhere else would crash the test.
+type faultyApp struct {
+	srvtypes.Application
+	faults *nodeFaults
+}
+
+func (a *faultyApp) PrepareProposal(req abci.RequestPrepareProposal) abci.ResponsePrepareProposal {
+	a.faults.maybeCrash(prepareProposal, req.Height)
+	return a.Application.PrepareProposal(req)
+}
+
+func (a *faultyApp) ProcessProposal(req abci.RequestProcessProposal) abci.ResponseProcessProposal {
+	a.faults.maybeCrash(processProposal, req.Header.Height)
+	return a.Application.ProcessProposal(req)
+}
+
+// skewedPrivValidator signs votes with timestamps taken from a clock that is
+// ahead by the clock offset of the node. The time of a block is the weighted
+// median of the timestamps of the votes that commit the previous block, so
+// skewed clocks of validators shift the block time. Proposals are signed
+// unchanged as comet doesn't take their timestamp from the signed proposal.
+type skewedPrivValidator struct {
+	types.PrivValidator
+	faults *nodeFaults
+}
+
+func (pv *skewedPrivValidator) SignVote(chainID string, vote *tmproto.Vote) error {
+	// comet sets the timestamp to the later of the current time and the time
+	// of the voted block, so the skewed clock only matters if it is later
+	skewed := tmtime.Now().Add(time.Duration(pv.faults.clockOffset.Load()))
+	if skewed.After(vote.Timestamp) {
+		vote.Timestamp = skewed
+	}
+	return pv.PrivValidator.SignVote(chainID, vote)
+}
+
+// faultyDB fails all writes while the disk of the node is full.
+type faultyDB struct {
+	dbm.DB
+	faults *nodeFaults
+}
+
+func (db *faultyDB) Set(key, value []byte) error {
+	if db.faults.diskFull.Load() {
+		return errDiskFull
+	}
+	return db.DB.Set(key, value)
+}
+
+func (db *faultyDB) SetSync(key, value []byte) error {
+	if db.faults.diskFull.Load() {
+		return errDiskFull
+	}
+	return db.DB.SetSync(key, value)
+}
+
+func (db *faultyDB) Delete(key []byte) error {
+	if db.faults.diskFull.Load() {
+		return errDiskFull
+	}
+	return db.DB.Delete(key)
+}
+
+func (db *faultyDB) DeleteSync(key []byte) error {
+	if db.faults.diskFull.Load() {
+		return errDiskFull
+	}
+	return db.DB.DeleteSync(key)
+}
+
+func (db *faultyDB) NewBatch() dbm.Batch {
+	return &faultyBatch{Batch: db.DB.NewBatch(), faults: db.faults}
+}
+
+type faultyBatch struct {
+	dbm.Batch
+	faults *nodeFaults
+}
+
+func (b *faultyBatch) Write() error {
+	if b.faults.diskFull.Load() {
+		return errDiskFull
+	}
+	return b.Batch.Write()
+}
+
+func (b *faultyBatch) WriteSync() error {
+	if b.faults.diskFull.Load() {
+		return errDiskFull
+	}
+	return b.Batch.WriteSync()
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/tendermint/tendermint/p2p"
 	"github.com/tendermint/tendermint/privval"
 	"github.com/tendermint/tendermint/proxy"
+	"github.com/tendermint/tendermint/types"
 	dbm "github.com/tendermint/tm-db"
 )
 
@@ -24,6 +25,18 @@ import (
 // validator celestia-app network. It expects that all configuration files are
 // already initialized and saved to the baseDir.
 func NewCometNode(t testing.TB, baseDir string, cfg *Config) (*node.Node, srvtypes.Application, error) {
+	t.Helper()
+	dbPath := filepath.Join(cfg.TmConfig.RootDir, "data")
+	db, err := dbm.NewGoLevelDB("application", dbPath)
+	require.NoError(t, err)
+	return newCometNode(baseDir, cfg, db, node.DefaultDBProvider, nil)
+}
+
+// newCometNode creates a comet node that runs the application on appDB and
+// whose own databases are opened by the dbProvider. The application stores its
+// snapshots in appHome. If faults is not nil, they are injected into the
+// application and the signer of the node.
+func newCometNode(appHome string, cfg *Config, appDB dbm.DB, dbProvider node.DBProvider, faults *nodeFaults) (*node.Node, srvtypes.Application, error) {
 	var logger log.Logger
 	if cfg.SupressLogs {
 		logger = log.NewNopLogger()
@@ -32,13 +45,14 @@ func NewCometNode(t testing.TB, baseDir string, cfg *Config) (*node.Node, srvtyp
 		logger = log.NewFilter(logger, log.AllowError())
 	}
 
-	dbPath := filepath.Join(cfg.TmConfig.RootDir, "data")
-	db, err := dbm.NewGoLevelDB("application", dbPath)
-	require.NoError(t, err)
+	cfg.AppOptions.Set(flags.FlagHome, appHome)
 
-	cfg.AppOptions.Set(flags.FlagHome, baseDir)
-
-	app := cfg.AppCreator(logger, db, nil, cfg.AppOptions)
+	app := cfg.AppCreator(logger, appDB, nil, cfg.AppOptions)
+	var pv types.PrivValidator = privval.LoadOrGenFilePV(cfg.TmConfig.PrivValidatorKeyFile(), cfg.TmConfig.PrivValidatorStateFile())
+	if faults != nil {
+		app = &faultyApp{Application: app, faults: faults}
+		pv = &skewedPrivValidator{PrivValidator: pv, faults: faults}
+	}
 
 	nodeKey, err := p2p.LoadOrGenNodeKey(cfg.TmConfig.NodeKeyFile())
 	if err != nil {
@@ -47,11 +61,11 @@ func NewCometNode(t testing.TB, baseDir string, cfg *Config) (*node.Node, srvtyp
 
 	tmNode, err := node.NewNode(
 		cfg.TmConfig,
-		privval.LoadOrGenFilePV(cfg.TmConfig.PrivValidatorKeyFile(), cfg.TmConfig.PrivValidatorStateFile()),
+		pv,
 		nodeKey,
 		proxy.NewLocalClientCreator(app),
 		node.DefaultGenesisDocProviderFunc(cfg.TmConfig),
-		node.DefaultDBProvider,
+		dbProvider,
 		node.DefaultMetricsProvider(cfg.TmConfig.Instrumentation),
 		logger,
 	)
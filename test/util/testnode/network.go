@@ -0,0 +1,334 @@
+package testnode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/celestiaorg/celestia-app/test/util/genesis"
+	cmtdb "github.com/cometbft/cometbft-db"
+	"github.com/stretchr/testify/require"
+	"github.com/syndtr/goleveldb/leveldb"
+	tmconfig "github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/node"
+	"github.com/tendermint/tendermint/p2p"
+	dbm "github.com/tendermint/tm-db"
+)
+
+// Network is an in-process network of validators that are connected to each
+// other over loopback. Unlike NewNetwork, which starts a single validator, it
+// allows testing consensus-level behaviour such as proposal rejection,
+// upgrades and validator set changes without containers. Nodes are connected
+// through links that can inject network faults.
+type Network struct {
+	nodes  []*Node
+	links  []*link
+	faults *networkFaults
+}
+
+// Node is a validator of a Network. Nodes can be stopped and restarted
+// individually. A restarted node keeps its state and addresses.
+type Node struct {
+	// Name is the name of the account of the validator in the keyring.
+	Name string
+
+	cfg     *Config
+	baseDir string
+	faults  *nodeFaults
+	// starts counts how often the node has been started.
+	starts int
+
+	mtx  sync.Mutex
+	cctx Context
+	// stop is nil if the node is not running.
+	stop func() error
+}
+
+// NewMultiNodeNetwork starts an in-process network of numValidators
+// validators. Default validators are added to the genesis of the config until
+// it contains numValidators validators, and every validator of the genesis is
+// run as a node. Each node listens on its own open ports and has all other
+// nodes as persistent peers, which it reaches through a link that can inject
+// faults. The nodes are stopped when the test finishes.
+func NewMultiNodeNetwork(t testing.TB, cfg *Config, numValidators int) *Network {
+	t.Helper()
+
+	for i := len(cfg.Genesis.Validators()); i < numValidators; i++ {
+		val := genesis.NewDefaultValidator(fmt.Sprintf("%s-%d", DefaultValidatorAccountName, i))
+		require.NoError(t, cfg.Genesis.AddValidator(val))
+	}
+	validators := cfg.Genesis.Validators()
+
+	network := &Network{nodes: make([]*Node, len(validators)), faults: newNetworkFaults()}
+	t.Cleanup(func() {
+		t.Log("tearing down multi node network")
+		for _, n := range network.nodes {
+			if n != nil {
+				require.NoError(t, n.Stop())
+			}
+		}
+		for _, l := range network.links {
+			require.NoError(t, l.Close())
+		}
+	})
+	// The links listen before the ports of the nodes are picked so that they
+	// can't take a port that is reserved for a node.
+	for i := range validators {
+		for j := range validators {
+			if i == j {
+				continue
+			}
+			l, err := newLink(i, j, network.faults)
+			require.NoError(t, err)
+			network.links = append(network.links, l)
+		}
+	}
+
+	nodeIDs := make([]p2p.ID, len(validators))
+	for i, val := range validators {
+		tmCfg := copyTendermintConfig(cfg.TmConfig)
+		tmCfg.RPC.ListenAddress = fmt.Sprintf("tcp://127.0.0.1:%d", GetFreePort())
+		tmCfg.P2P.ListenAddress = fmt.Sprintf("tcp://127.0.0.1:%d", GetFreePort())
+		tmCfg.RPC.GRPCListenAddress = fmt.Sprintf("tcp://127.0.0.1:%d", GetFreePort())
+		// all nodes share the loopback address
+		tmCfg.P2P.AllowDuplicateIP = true
+		tmCfg.P2P.AddrBookStrict = false
+		// nodes must only connect through the links and not learn the
+		// addresses of their peers
+		tmCfg.P2P.PexReactor = false
+
+		appCfg := *cfg.AppConfig
+		appCfg.GRPC.Address = fmt.Sprintf("127.0.0.1:%d", GetFreePort())
+		appCfg.API.Address = fmt.Sprintf("tcp://127.0.0.1:%d", GetFreePort())
+
+		baseDir, err := genesis.InitFiles(t.TempDir(), tmCfg, cfg.Genesis, i)
+		require.NoError(t, err)
+
+		nodeCfg := *cfg
+		nodeCfg.TmConfig = tmCfg
+		nodeCfg.AppConfig = &appCfg
+		nodeCfg.AppOptions = cfg.AppOptions.clone()
+		network.nodes[i] = &Node{Name: val.Name, cfg: &nodeCfg, baseDir: baseDir, faults: newNodeFaults()}
+		nodeIDs[i] = p2p.PubKeyToID(val.NetworkKey.PubKey())
+	}
+
+	peers := make([][]string, len(network.nodes))
+	for _, l := range network.links {
+		l.start(strings.TrimPrefix(network.nodes[l.to].cfg.TmConfig.P2P.ListenAddress, "tcp://"))
+		peers[l.from] = append(peers[l.from], p2p.IDAddressString(nodeIDs[l.to], l.Addr()))
+	}
+	for i, n := range network.nodes {
+		n.cfg.TmConfig.P2P.PersistentPeers = strings.Join(peers[i], ",")
+	}
+	for _, n := range network.nodes {
+		require.NoError(t, n.Start())
+	}
+	return network
+}
+
+// Nodes returns all nodes of the network in the order of the validators in
+// the genesis.
+func (n *Network) Nodes() []*Node {
+	return n.nodes
+}
+
+// Node returns the node of the ith validator of the genesis.
+func (n *Network) Node(i int) *Node {
+	return n.nodes[i]
+}
+
+// GRPCEndpoints returns the gRPC addresses of all nodes.
+func (n *Network) GRPCEndpoints() []string {
+	endpoints := make([]string, len(n.nodes))
+	for i := range n.nodes {
+		endpoints[i] = n.nodes[i].GRPCAddress()
+	}
+	return endpoints
+}
+
+// Start starts the node. Starting a running node is a no-op.
+func (n *Node) Start() error {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	if n.stop != nil {
+		return nil
+	}
+
+	n.faults.reset()
+	dbs := &nodeDBs{faults: n.faults}
+	appDB, err := dbs.openApp(n.cfg.TmConfig)
+	if err != nil {
+		return err
+	}
+	// The application never closes its snapshot database, so every start
+	// uses its own snapshot directory.
+	n.starts++
+	appHome := filepath.Join(n.baseDir, "app", strconv.Itoa(n.starts))
+	tmNode, app, err := newCometNode(appHome, n.cfg, appDB, dbs.open, n.faults)
+	if err != nil {
+		return errors.Join(err, dbs.close())
+	}
+
+	cctx := NewContext(context.TODO(), n.cfg.Genesis.Keyring(), n.cfg.TmConfig, n.cfg.Genesis.ChainID)
+	cctx, stopNode, err := startNode(tmNode, cctx)
+	if err != nil {
+		return errors.Join(err, dbs.close())
+	}
+	cctx.Context = cctx.WithClient(newNodeClient(tmNode))
+
+	cctx, stopGRPC, err := StartGRPCServer(app, n.cfg.AppConfig, cctx)
+	if err != nil {
+		return errors.Join(err, stopNode(), dbs.close())
+	}
+
+	n.cctx = cctx
+	n.stop = func() error {
+		return errors.Join(stopGRPC(), cctx.GRPCClient.Close(), stopNode(), dbs.close())
+	}
+	return nil
+}
+
+// Stop stops the node while keeping its files. Stopping a stopped node is a
+// no-op.
+func (n *Node) Stop() error {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	if n.stop == nil {
+		return nil
+	}
+	err := n.stop()
+	n.stop = nil
+	return err
+}
+
+// IsRunning returns whether the node has been started and not stopped.
+func (n *Node) IsRunning() bool {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	return n.stop != nil
+}
+
+// Context returns the context of the node. The context is replaced when the
+// node is restarted.
+func (n *Node) Context() Context {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	return n.cctx
+}
+
+// RPCAddress returns the address of the comet RPC of the node.
+func (n *Node) RPCAddress() string {
+	return n.cfg.TmConfig.RPC.ListenAddress
+}
+
+// GRPCAddress returns the address of the gRPC server of the node.
+func (n *Node) GRPCAddress() string {
+	return n.cfg.AppConfig.GRPC.Address
+}
+
+// nodeDBs opens the databases of a node and closes them when the node is
+// stopped. Comet doesn't close all of its databases, nor does the
+// application, which would keep a restarted node from opening them again.
+// Faults are injected into the database of the application.
+type nodeDBs struct {
+	faults *nodeFaults
+
+	mtx sync.Mutex
+	dbs []io.Closer
+}
+
+// openApp opens the database of the application.
+func (d *nodeDBs) openApp(cfg *tmconfig.Config) (dbm.DB, error) {
+	db, err := dbm.NewGoLevelDB("application", filepath.Join(cfg.RootDir, "data"))
+	if err != nil {
+		return nil, err
+	}
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	d.dbs = append(d.dbs, db)
+	return &faultyDB{DB: db, faults: d.faults}, nil
+}
+
+// open opens the databases of comet. It is a node.DBProvider.
+func (d *nodeDBs) open(ctx *node.DBContext) (cmtdb.DB, error) {
+	db, err := node.DefaultDBProvider(ctx)
+	if err != nil {
+		return nil, err
+	}
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	cdb := &cometDB{DB: db}
+	d.dbs = append(d.dbs, cdb)
+	return cdb, nil
+}
+
+func (d *nodeDBs) close() error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	var errs []error
+	for _, db := range d.dbs {
+		// databases that comet closed itself return leveldb.ErrClosed
+		if err := db.Close(); err != nil && !errors.Is(err, leveldb.ErrClosed) {
+			errs = append(errs, err)
+		}
+	}
+	d.dbs = nil
+	return errors.Join(errs...)
+}
+
+// cometDB is a database of comet. Comet closes its block store when it stops
+// while the routines of its consensus reactor may still read from it, which
+// panics. Reads from a closed cometDB find nothing instead.
+type cometDB struct {
+	cmtdb.DB
+
+	mtx    sync.RWMutex
+	closed bool
+}
+
+func (db *cometDB) Get(key []byte) ([]byte, error) {
+	db.mtx.RLock()
+	defer db.mtx.RUnlock()
+	if db.closed {
+		return nil, nil
+	}
+	return db.DB.Get(key)
+}
+
+func (db *cometDB) Has(key []byte) (bool, error) {
+	db.mtx.RLock()
+	defer db.mtx.RUnlock()
+	if db.closed {
+		return false, nil
+	}
+	return db.DB.Has(key)
+}
+
+func (db *cometDB) Close() error {
+	db.mtx.Lock()
+	defer db.mtx.Unlock()
+	if db.closed {
+		return nil
+	}
+	db.closed = true
+	return db.DB.Close()
+}
+
+// copyTendermintConfig returns a copy of the config that shares none of its
+// sections with the original.
+func copyTendermintConfig(cfg *tmconfig.Config) *tmconfig.Config {
+	c := *cfg
+	rpc, p2pCfg, mempool := *cfg.RPC, *cfg.P2P, *cfg.Mempool
+	stateSync, fastSync, consensus := *cfg.StateSync, *cfg.FastSync, *cfg.Consensus
+	storage, txIndex, instrumentation := *cfg.Storage, *cfg.TxIndex, *cfg.Instrumentation
+	c.RPC, c.P2P, c.Mempool = &rpc, &p2pCfg, &mempool
+	c.StateSync, c.FastSync, c.Consensus = &stateSync, &fastSync, &consensus
+	c.Storage, c.TxIndex, c.Instrumentation = &storage, &txIndex, &instrumentation
+	return &c
+}
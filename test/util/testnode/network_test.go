@@ -0,0 +1,42 @@
+package testnode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiNodeNetwork(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping multi node network test in short mode.")
+	}
+
+	cfg := DefaultConfig().WithTimeoutCommit(100 * time.Millisecond)
+	network := NewMultiNodeNetwork(t, cfg, 4)
+	require.Len(t, network.Nodes(), 4)
+
+	cctx := network.Node(0).Context()
+	_, err := cctx.WaitForHeight(3)
+	require.NoError(t, err)
+
+	vals, err := cctx.Client.Validators(cctx.GoContext(), nil, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, vals.Validators, 4)
+
+	// three out of four validators hold more than 2/3 of the voting power so
+	// the network keeps producing blocks
+	stopped := network.Node(3)
+	require.NoError(t, stopped.Stop())
+	require.False(t, stopped.IsRunning())
+	height, err := cctx.LatestHeight()
+	require.NoError(t, err)
+	_, err = cctx.WaitForHeight(height + 3)
+	require.NoError(t, err)
+
+	// the restarted node catches up with the network
+	require.NoError(t, stopped.Start())
+	restarted := stopped.Context()
+	_, err = restarted.WaitForHeight(height + 5)
+	require.NoError(t, err)
+}
@@ -0,0 +1,222 @@
+package testnode
+
+import (
+	"context"
+	"sync"
+
+	"github.com/tendermint/tendermint/libs/bytes"
+	"github.com/tendermint/tendermint/node"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	"github.com/tendermint/tendermint/rpc/client/local"
+	rpccore "github.com/tendermint/tendermint/rpc/core"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+// rpcEnvMtx serializes the calls of all nodeClients as they swap the global
+// RPC environment of comet.
+var rpcEnvMtx sync.Mutex
+
+// nodeClient is a local RPC client of one node of a Network. Comet serves RPC
+// calls from a single global environment that is replaced by every node that
+// is started in the process, so a plain local client would query whichever
+// node was started last. nodeClient restores the environment of its node for
+// the duration of each call. The RPC servers of the nodes still share the
+// environment of the node that was started last.
+type nodeClient struct {
+	*local.Local
+	env *rpccore.Environment
+}
+
+var _ rpcclient.Client = &nodeClient{}
+
+// newNodeClient creates a local client of the started node.
+func newNodeClient(tmNode *node.Node) *nodeClient {
+	rpcEnvMtx.Lock()
+	defer rpcEnvMtx.Unlock()
+	// creating the local client configures the environment of the node
+	client := local.New(tmNode)
+	return &nodeClient{Local: client, env: rpccore.GetEnvironment()}
+}
+
+// use sets the environment of the node until the returned function is called.
+func (c *nodeClient) use() func() {
+	rpcEnvMtx.Lock()
+	rpccore.SetEnvironment(c.env)
+	return rpcEnvMtx.Unlock
+}
+
+func (c *nodeClient) Status(ctx context.Context) (*ctypes.ResultStatus, error) {
+	defer c.use()()
+	return c.Local.Status(ctx)
+}
+
+func (c *nodeClient) ABCIInfo(ctx context.Context) (*ctypes.ResultABCIInfo, error) {
+	defer c.use()()
+	return c.Local.ABCIInfo(ctx)
+}
+
+func (c *nodeClient) ABCIQuery(ctx context.Context, path string, data bytes.HexBytes) (*ctypes.ResultABCIQuery, error) {
+	defer c.use()()
+	return c.Local.ABCIQuery(ctx, path, data)
+}
+
+func (c *nodeClient) ABCIQueryWithOptions(ctx context.Context, path string, data bytes.HexBytes, opts rpcclient.ABCIQueryOptions) (*ctypes.ResultABCIQuery, error) {
+	defer c.use()()
+	return c.Local.ABCIQueryWithOptions(ctx, path, data, opts)
+}
+
+func (c *nodeClient) BroadcastTxCommit(ctx context.Context, tx types.Tx) (*ctypes.ResultBroadcastTxCommit, error) {
+	defer c.use()()
+	return c.Local.BroadcastTxCommit(ctx, tx)
+}
+
+func (c *nodeClient) BroadcastTxAsync(ctx context.Context, tx types.Tx) (*ctypes.ResultBroadcastTx, error) {
+	defer c.use()()
+	return c.Local.BroadcastTxAsync(ctx, tx)
+}
+
+func (c *nodeClient) BroadcastTxSync(ctx context.Context, tx types.Tx) (*ctypes.ResultBroadcastTx, error) {
+	defer c.use()()
+	return c.Local.BroadcastTxSync(ctx, tx)
+}
+
+func (c *nodeClient) UnconfirmedTxs(ctx context.Context, limit *int) (*ctypes.ResultUnconfirmedTxs, error) {
+	defer c.use()()
+	return c.Local.UnconfirmedTxs(ctx, limit)
+}
+
+func (c *nodeClient) NumUnconfirmedTxs(ctx context.Context) (*ctypes.ResultUnconfirmedTxs, error) {
+	defer c.use()()
+	return c.Local.NumUnconfirmedTxs(ctx)
+}
+
+func (c *nodeClient) CheckTx(ctx context.Context, tx types.Tx) (*ctypes.ResultCheckTx, error) {
+	defer c.use()()
+	return c.Local.CheckTx(ctx, tx)
+}
+
+func (c *nodeClient) NetInfo(ctx context.Context) (*ctypes.ResultNetInfo, error) {
+	defer c.use()()
+	return c.Local.NetInfo(ctx)
+}
+
+func (c *nodeClient) DumpConsensusState(ctx context.Context) (*ctypes.ResultDumpConsensusState, error) {
+	defer c.use()()
+	return c.Local.DumpConsensusState(ctx)
+}
+
+func (c *nodeClient) ConsensusState(ctx context.Context) (*ctypes.ResultConsensusState, error) {
+	defer c.use()()
+	return c.Local.ConsensusState(ctx)
+}
+
+func (c *nodeClient) ConsensusParams(ctx context.Context, height *int64) (*ctypes.ResultConsensusParams, error) {
+	defer c.use()()
+	return c.Local.ConsensusParams(ctx, height)
+}
+
+func (c *nodeClient) Health(ctx context.Context) (*ctypes.ResultHealth, error) {
+	defer c.use()()
+	return c.Local.Health(ctx)
+}
+
+func (c *nodeClient) DialSeeds(ctx context.Context, seeds []string) (*ctypes.ResultDialSeeds, error) {
+	defer c.use()()
+	return c.Local.DialSeeds(ctx, seeds)
+}
+
+func (c *nodeClient) DialPeers(ctx context.Context, peers []string, persistent, unconditional, private bool) (*ctypes.ResultDialPeers, error) {
+	defer c.use()()
+	return c.Local.DialPeers(ctx, peers, persistent, unconditional, private)
+}
+
+func (c *nodeClient) BlockchainInfo(ctx context.Context, minHeight, maxHeight int64) (*ctypes.ResultBlockchainInfo, error) {
+	defer c.use()()
+	return c.Local.BlockchainInfo(ctx, minHeight, maxHeight)
+}
+
+func (c *nodeClient) Genesis(ctx context.Context) (*ctypes.ResultGenesis, error) {
+	defer c.use()()
+	return c.Local.Genesis(ctx)
+}
+
+func (c *nodeClient) GenesisChunked(ctx context.Context, id uint) (*ctypes.ResultGenesisChunk, error) {
+	defer c.use()()
+	return c.Local.GenesisChunked(ctx, id)
+}
+
+func (c *nodeClient) Block(ctx context.Context, height *int64) (*ctypes.ResultBlock, error) {
+	defer c.use()()
+	return c.Local.Block(ctx, height)
+}
+
+func (c *nodeClient) SignedBlock(ctx context.Context, height *int64) (*ctypes.ResultSignedBlock, error) {
+	defer c.use()()
+	return c.Local.SignedBlock(ctx, height)
+}
+
+func (c *nodeClient) BlockByHash(ctx context.Context, hash []byte) (*ctypes.ResultBlock, error) {
+	defer c.use()()
+	return c.Local.BlockByHash(ctx, hash)
+}
+
+func (c *nodeClient) BlockResults(ctx context.Context, height *int64) (*ctypes.ResultBlockResults, error) {
+	defer c.use()()
+	return c.Local.BlockResults(ctx, height)
+}
+
+func (c *nodeClient) Header(ctx context.Context, height *int64) (*ctypes.ResultHeader, error) {
+	defer c.use()()
+	return c.Local.Header(ctx, height)
+}
+
+func (c *nodeClient) HeaderByHash(ctx context.Context, hash bytes.HexBytes) (*ctypes.ResultHeader, error) {
+	defer c.use()()
+	return c.Local.HeaderByHash(ctx, hash)
+}
+
+func (c *nodeClient) Commit(ctx context.Context, height *int64) (*ctypes.ResultCommit, error) {
+	defer c.use()()
+	return c.Local.Commit(ctx, height)
+}
+
+func (c *nodeClient) DataCommitment(ctx context.Context, start, end uint64) (*ctypes.ResultDataCommitment, error) {
+	defer c.use()()
+	return c.Local.DataCommitment(ctx, start, end)
+}
+
+func (c *nodeClient) DataRootInclusionProof(ctx context.Context, height, start, end uint64) (*ctypes.ResultDataRootInclusionProof, error) {
+	defer c.use()()
+	return c.Local.DataRootInclusionProof(ctx, height, start, end)
+}
+
+func (c *nodeClient) Validators(ctx context.Context, height *int64, page, perPage *int) (*ctypes.ResultValidators, error) {
+	defer c.use()()
+	return c.Local.Validators(ctx, height, page, perPage)
+}
+
+func (c *nodeClient) Tx(ctx context.Context, hash []byte, prove bool) (*ctypes.ResultTx, error) {
+	defer c.use()()
+	return c.Local.Tx(ctx, hash, prove)
+}
+
+func (c *nodeClient) ProveShares(ctx context.Context, height, startShare, endShare uint64) (types.ShareProof, error) {
+	defer c.use()()
+	return c.Local.ProveShares(ctx, height, startShare, endShare)
+}
+
+func (c *nodeClient) TxSearch(ctx context.Context, query string, prove bool, page, perPage *int, orderBy string) (*ctypes.ResultTxSearch, error) {
+	defer c.use()()
+	return c.Local.TxSearch(ctx, query, prove, page, perPage, orderBy)
+}
+
+func (c *nodeClient) BlockSearch(ctx context.Context, query string, page, perPage *int, orderBy string) (*ctypes.ResultBlockSearch, error) {
+	defer c.use()()
+	return c.Local.BlockSearch(ctx, query, page, perPage, orderBy)
+}
+
+func (c *nodeClient) BroadcastEvidence(ctx context.Context, ev types.Evidence) (*ctypes.ResultBroadcastEvidence, error) {
+	defer c.use()()
+	return c.Local.BroadcastEvidence(ctx, ev)
+}
@@ -0,0 +1,240 @@
+package testnode
+
+import (
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// chunkSize is the maximum number of bytes that a link forwards at once.
+	chunkSize = 32 * 1024
+	// maxQueuedChunks is the number of chunks that a link buffers per
+	// direction before it stops reading from the sender.
+	maxQueuedChunks = 1024
+)
+
+// link forwards the p2p connections that a node of a Network dials to one of
+// its peers and injects the network faults of the Network into them.
+type link struct {
+	from, to int
+	target   string
+	listener net.Listener
+	faults   *networkFaults
+
+	mtx    sync.Mutex
+	conns  map[net.Conn]struct{}
+	closed bool
+}
+
+// newLink creates a link from the node from to the node to that listens on an
+// open port. It doesn't accept connections until it is started.
+func newLink(from, to int, faults *networkFaults) (*link, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	return &link{
+		from:     from,
+		to:       to,
+		listener: listener,
+		faults:   faults,
+		conns:    make(map[net.Conn]struct{}),
+	}, nil
+}
+
+// start forwards the connections that the link accepts to the target, the
+// p2p address of the node to.
+func (l *link) start(target string) {
+	l.target = target
+	go l.accept()
+}
+
+// Addr returns the address that the node from dials instead of its peer.
+func (l *link) Addr() string {
+	return l.listener.Addr().String()
+}
+
+func (l *link) accept() {
+	for {
+		conn, err := l.listener.Accept()
+		if err != nil {
+			return
+		}
+		if l.faults.isPartitioned(l.from, l.to) {
+			conn.Close()
+			continue
+		}
+		target, err := net.Dial("tcp", l.target)
+		if err != nil {
+			conn.Close()
+			continue
+		}
+		if !l.track(conn, target) {
+			conn.Close()
+			target.Close()
+			return
+		}
+		go l.forward(conn, target, l.from, l.to)
+		go l.forward(target, conn, l.to, l.from)
+	}
+}
+
+// forward copies the data from src to dst, applying the faults of the
+// messages sent by the node from to the node to. Both connections are closed
+// when either of them fails.
+func (l *link) forward(src, dst net.Conn, from, to int) {
+	type chunk struct {
+		data      []byte
+		deliverAt time.Time
+	}
+	queue := make(chan chunk, maxQueuedChunks)
+	writerDone := make(chan struct{})
+	readerDone := make(chan struct{})
+	defer func() {
+		l.untrack(src, dst)
+		src.Close()
+		dst.Close()
+	}()
+
+	go func() {
+		defer close(readerDone)
+		defer close(queue)
+		for {
+			buf := make([]byte, chunkSize)
+			n, err := src.Read(buf)
+			if n > 0 {
+				faults := l.faults.link(from, to)
+				// closing the connection forces the nodes to reconnect
+				if faults.DropRate > 0 && rand.Float64() < faults.DropRate {
+					return
+				}
+				select {
+				case queue <- chunk{data: buf[:n], deliverAt: time.Now().Add(faults.Delay)}:
+				case <-writerDone:
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	defer close(writerDone)
+
+	for c := range queue {
+		time.Sleep(time.Until(c.deliverAt))
+		// data that is still queued when the connection closes during a
+		// pause is discarded
+		if !l.faults.waitUntilResumed(from, to, readerDone) {
+			return
+		}
+		if _, err := dst.Write(c.data); err != nil {
+			return
+		}
+	}
+}
+
+// track registers the connections of the link so that they can be cut. It
+// returns false if the link has been closed.
+func (l *link) track(conns ...net.Conn) bool {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.closed {
+		return false
+	}
+	for _, conn := range conns {
+		l.conns[conn] = struct{}{}
+	}
+	return true
+}
+
+func (l *link) untrack(conns ...net.Conn) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	for _, conn := range conns {
+		delete(l.conns, conn)
+	}
+}
+
+// cut closes all connections of the link. The nodes may reconnect through the
+// link unless they are partitioned.
+func (l *link) cut() {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	for conn := range l.conns {
+		conn.Close()
+	}
+}
+
+// Close stops accepting connections and cuts the existing ones.
+func (l *link) Close() error {
+	l.mtx.Lock()
+	l.closed = true
+	l.mtx.Unlock()
+	err := l.listener.Close()
+	l.cut()
+	return err
+}
+
+// networkFaults are the faults injected into the links of a Network.
+type networkFaults struct {
+	mtx sync.Mutex
+	// links are the faults of the messages sent from one node to another.
+	links map[[2]int]LinkFaults
+	// partitioned contains the pairs of nodes, ordered by index, that can't
+	// communicate.
+	partitioned map[[2]int]bool
+	// paused maps paused nodes to a channel that is closed when they resume.
+	paused map[int]chan struct{}
+}
+
+func newNetworkFaults() *networkFaults {
+	return &networkFaults{
+		links:       make(map[[2]int]LinkFaults),
+		partitioned: make(map[[2]int]bool),
+		paused:      make(map[int]chan struct{}),
+	}
+}
+
+func (f *networkFaults) link(from, to int) LinkFaults {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return f.links[[2]int{from, to}]
+}
+
+func (f *networkFaults) isPartitioned(a, b int) bool {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return f.partitioned[pair(a, b)]
+}
+
+// waitUntilResumed blocks while either node is paused. It returns false if
+// done is closed first.
+func (f *networkFaults) waitUntilResumed(a, b int, done <-chan struct{}) bool {
+	for {
+		f.mtx.Lock()
+		resumed, paused := f.paused[a]
+		if !paused {
+			resumed, paused = f.paused[b]
+		}
+		f.mtx.Unlock()
+		if !paused {
+			return true
+		}
+		select {
+		case <-resumed:
+		case <-done:
+			return false
+		}
+	}
+}
+
+// pair returns the nodes ordered by index.
+func pair(a, b int) [2]int {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]int{a, b}
+}
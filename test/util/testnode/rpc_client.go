@@ -20,6 +20,24 @@ import (
 // called during cleanup to teardown the node, core client, along with canceling
 // the internal context.Context in the returned Context.
 func StartNode(tmNode *node.Node, cctx Context) (Context, func() error, error) {
+	cctx, stop, err := startNode(tmNode, cctx)
+	if err != nil {
+		return cctx, stop, err
+	}
+	cleanup := func() error {
+		if err := stop(); err != nil {
+			return err
+		}
+		return removeDir(path.Join([]string{cctx.HomeDir, "config"}...))
+	}
+
+	return cctx, cleanup, nil
+}
+
+// startNode starts the tendermint node along with a local core rpc client.
+// Unlike the cleanup of StartNode, the returned function keeps the files of
+// the node so that it can be restarted.
+func startNode(tmNode *node.Node, cctx Context) (Context, func() error, error) {
 	if err := tmNode.Start(); err != nil {
 		return cctx, func() error { return nil }, err
 	}
@@ -29,17 +47,17 @@ func StartNode(tmNode *node.Node, cctx Context) (Context, func() error, error) {
 	cctx.Context = cctx.WithClient(coreClient)
 	goCtx, cancel := context.WithCancel(context.Background())
 	cctx.rootCtx = goCtx
-	cleanup := func() error {
+	stop := func() error {
 		cancel()
 		err := tmNode.Stop()
 		if err != nil {
 			return err
 		}
 		tmNode.Wait()
-		return removeDir(path.Join([]string{cctx.HomeDir, "config"}...))
+		return nil
 	}
 
-	return cctx, cleanup, nil
+	return cctx, stop, nil
 }
 
 // StartGRPCServer starts the grpc server using the provided application and
@@ -53,6 +71,12 @@ func StartGRPCServer(app srvtypes.Application, appCfg *srvconfig.Config, cctx Co
 	// Add the tendermint queries service in the gRPC router.
 	app.RegisterTendermintService(cctx.Context)
 
+	// Add the node service, which exposes the node's configuration, in the
+	// gRPC router.
+	if a, ok := app.(srvtypes.ApplicationQueryService); ok {
+		a.RegisterNodeService(cctx.Context)
+	}
+
 	grpcSrv, err := srvgrpc.StartGRPCServer(cctx.Context, app, appCfg.GRPC)
 	if err != nil {
 		return Context{}, emptycleanup, err
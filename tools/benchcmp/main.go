@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+func main() {
+	regressed, err := Run(os.Args[1:], os.Stdout)
+	if err != nil {
+		fmt.Printf("ERROR: %s\n", err.Error())
+		os.Exit(2)
+	}
+	if regressed {
+		os.Exit(1)
+	}
+}
+
+// Run compares the benchmark results of the current file against the
+// baseline file and writes the comparison to w. It returns whether any metric
+// regressed by more than the threshold.
+func Run(args []string, w io.Writer) (bool, error) {
+	flags := flag.NewFlagSet("benchcmp", flag.ContinueOnError)
+	threshold := flags.Float64("threshold", 10, "percentage by which a metric may grow before it is a regression")
+	if err := flags.Parse(args); err != nil {
+		return false, err
+	}
+	if flags.NArg() != 2 {
+		return false, fmt.Errorf("usage: benchcmp [-threshold percent] <baseline> <current>")
+	}
+
+	baseline, err := parseFile(flags.Arg(0))
+	if err != nil {
+		return false, err
+	}
+	current, err := parseFile(flags.Arg(1))
+	if err != nil {
+		return false, err
+	}
+	comparisons := compare(baseline, current, *threshold)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "benchmark\tmetric\tbaseline\tcurrent\tdelta\t")
+	regressed := false
+	for _, c := range comparisons {
+		status := ""
+		if c.regressed {
+			status = "REGRESSION"
+			regressed = true
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%.4g\t%.4g\t%+.2f%%\t%s\n", c.name, c.unit, c.baseline, c.current, c.delta, status)
+	}
+	if err := tw.Flush(); err != nil {
+		return false, err
+	}
+	for _, name := range missing(current, baseline) {
+		fmt.Fprintf(w, "new benchmark without a baseline: %s\n", name)
+	}
+	for _, name := range missing(baseline, current) {
+		fmt.Fprintf(w, "benchmark of the baseline was not run: %s\n", name)
+	}
+	if regressed {
+		fmt.Fprintf(w, "\nmetrics regressed by more than %.2f%%\n", *threshold)
+	}
+	return regressed, nil
+}
+
+// results maps the name of every benchmark to the mean of each of its metrics
+// by unit, for example ns/op, B/op and allocs/op.
+type results map[string]map[string]float64
+
+// cpuSuffix is the GOMAXPROCS suffix that is appended to the name of a
+// benchmark.
+var cpuSuffix = regexp.MustCompile(`-\d+$`)
+
+func parseFile(path string) (results, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parse(f)
+}
+
+// parse parses the output of `go test -bench`. The metrics of benchmarks that
+// were run multiple times, for example with -count, are averaged.
+func parse(r io.Reader) (results, error) {
+	sums := make(results)
+	runs := make(map[string]map[string]int)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// a result is the name, the number of iterations and pairs of values
+		// and units
+		if len(fields) < 4 || len(fields)%2 != 0 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+		if _, err := strconv.Atoi(fields[1]); err != nil {
+			continue
+		}
+		name := cpuSuffix.ReplaceAllString(fields[0], "")
+		if sums[name] == nil {
+			sums[name] = make(map[string]float64)
+			runs[name] = make(map[string]int)
+		}
+		for i := 2; i < len(fields); i += 2 {
+			value, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("parsing %s of %s: %w", fields[i+1], name, err)
+			}
+			sums[name][fields[i+1]] += value
+			runs[name][fields[i+1]]++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	for name, metrics := range sums {
+		for unit := range metrics {
+			metrics[unit] /= float64(runs[name][unit])
+		}
+	}
+	return sums, nil
+}
+
+type comparison struct {
+	name      string
+	unit      string
+	baseline  float64
+	current   float64
+	delta     float64
+	regressed bool
+}
+
+// compare compares every metric of the benchmarks that are in both results.
+// Higher values are worse for all metrics, so a metric regressed if it grew
+// by more than the threshold in percent.
+func compare(baseline, current results, threshold float64) []comparison {
+	var comparisons []comparison
+	for name, metrics := range current {
+		for unit, value := range metrics {
+			base, ok := baseline[name][unit]
+			if !ok {
+				continue
+			}
+			delta := 0.0
+			if base != 0 {
+				delta = (value - base) / base * 100
+			} else if value != 0 {
+				delta = 100
+			}
+			comparisons = append(comparisons, comparison{
+				name:      name,
+				unit:      unit,
+				baseline:  base,
+				current:   value,
+				delta:     delta,
+				regressed: delta > threshold,
+			})
+		}
+	}
+	sort.Slice(comparisons, func(i, j int) bool {
+		if comparisons[i].name != comparisons[j].name {
+			return comparisons[i].name < comparisons[j].name
+		}
+		return comparisons[i].unit < comparisons[j].unit
+	})
+	return comparisons
+}
+
+// missing returns the sorted names of the benchmarks of a that are not in b.
+func missing(a, b results) []string {
+	var names []string
+	for name := range a {
+		if _, ok := b[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const baselineOutput = `goos: linux
+goarch: amd64
+pkg: github.com/celestiaorg/celestia-app/app/test
+BenchmarkPrepareProposal/sends-8         	      10	 100000000 ns/op	        64.00 square_size	 1000 B/op	   100 allocs/op
+BenchmarkPrepareProposal/sends-8         	      10	 120000000 ns/op	        64.00 square_size	 1000 B/op	   100 allocs/op
+BenchmarkProcessProposal/sends-8         	      10	 100000000 ns/op	        64.00 square_size	 1000 B/op	   100 allocs/op
+BenchmarkProposalStages/sends/DAH-8      	      10	  50000000 ns/op	 2000 B/op	   200 allocs/op
+PASS
+ok  	github.com/celestiaorg/celestia-app/app/test	13.964s
+`
+
+func TestParse(t *testing.T) {
+	got, err := parse(strings.NewReader(baselineOutput))
+	require.NoError(t, err)
+	want := results{
+		"BenchmarkPrepareProposal/sends":    {"ns/op": 110000000, "square_size": 64, "B/op": 1000, "allocs/op": 100},
+		"BenchmarkProcessProposal/sends":    {"ns/op": 100000000, "square_size": 64, "B/op": 1000, "allocs/op": 100},
+		"BenchmarkProposalStages/sends/DAH": {"ns/op": 50000000, "B/op": 2000, "allocs/op": 200},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestCompare(t *testing.T) {
+	baseline := results{
+		"BenchmarkA": {"ns/op": 100, "allocs/op": 10},
+		"BenchmarkB": {"ns/op": 100},
+		"BenchmarkC": {"ns/op": 100},
+	}
+	current := results{
+		"BenchmarkA": {"ns/op": 111, "allocs/op": 5},
+		"BenchmarkB": {"ns/op": 110},
+		"BenchmarkD": {"ns/op": 1000},
+	}
+	got := compare(baseline, current, 10)
+	want := []comparison{
+		{name: "BenchmarkA", unit: "allocs/op", baseline: 10, current: 5, delta: -50},
+		{name: "BenchmarkA", unit: "ns/op", baseline: 100, current: 111, delta: 11, regressed: true},
+		{name: "BenchmarkB", unit: "ns/op", baseline: 100, current: 110, delta: 10},
+	}
+	require.Len(t, got, len(want))
+	for i := range want {
+		assert.Equal(t, want[i].name, got[i].name)
+		assert.Equal(t, want[i].unit, got[i].unit)
+		assert.InDelta(t, want[i].delta, got[i].delta, 1e-9)
+		assert.Equal(t, want[i].regressed, got[i].regressed, "%s %s", want[i].name, want[i].unit)
+	}
+	assert.Equal(t, []string{"BenchmarkD"}, missing(current, baseline))
+	assert.Equal(t, []string{"BenchmarkC"}, missing(baseline, current))
+}
@@ -101,7 +101,7 @@ func handleValsetRequest(ctx sdk.Context, k keeper.Keeper) {
 			// this condition should only occur in the simulator ref :
 			// https://github.com/Gravity-Bridge/Gravity-Bridge/issues/35
 			if errors.Is(err, types.ErrNoValidators) {
-				ctx.Logger().Error("no bonded validators",
+				k.Logger(ctx).Error("no bonded validators",
 					"cause", err.Error(),
 				)
 				return
@@ -145,15 +145,15 @@ func pruneAttestations(ctx sdk.Context, k keeper.Keeper) {
 	}
 	earliestAttestation, found, err := k.GetAttestationByNonce(ctx, k.GetEarliestAvailableAttestationNonce(ctx))
 	if err != nil {
-		ctx.Logger().Error("error getting earliest attestation for pruning", "err", err.Error())
+		k.Logger(ctx).Error("error getting earliest attestation for pruning", "err", err.Error())
 		return
 	}
 	if !found {
-		ctx.Logger().Error("couldn't find earliest attestation for pruning")
+		k.Logger(ctx).Error("couldn't find earliest attestation for pruning")
 		return
 	}
 	if earliestAttestation == nil {
-		ctx.Logger().Error("nil earliest attestation")
+		k.Logger(ctx).Error("nil earliest attestation")
 		return
 	}
 	currentBlockTime := ctx.BlockTime()
@@ -162,15 +162,15 @@ func pruneAttestations(ctx sdk.Context, k keeper.Keeper) {
 	for newEarliestAvailableNonce = earliestAttestation.GetNonce(); newEarliestAvailableNonce < latestAttestationNonce; newEarliestAvailableNonce++ {
 		newEarliestAttestation, found, err := k.GetAttestationByNonce(ctx, newEarliestAvailableNonce)
 		if err != nil {
-			ctx.Logger().Error("error getting attestation for pruning", "nonce", newEarliestAvailableNonce, "err", err.Error())
+			k.Logger(ctx).Error("error getting attestation for pruning", "nonce", newEarliestAvailableNonce, "err", err.Error())
 			return
 		}
 		if !found {
-			ctx.Logger().Error("couldn't find attestation for pruning", "nonce", newEarliestAvailableNonce)
+			k.Logger(ctx).Error("couldn't find attestation for pruning", "nonce", newEarliestAvailableNonce)
 			return
 		}
 		if newEarliestAttestation == nil {
-			ctx.Logger().Error("nil attestation for pruning", "nonce", newEarliestAvailableNonce)
+			k.Logger(ctx).Error("nil attestation for pruning", "nonce", newEarliestAvailableNonce)
 			return
 		}
 		attestationExpirationTime := newEarliestAttestation.BlockTime().Add(AttestationExpiryTime)
@@ -184,7 +184,7 @@ func pruneAttestations(ctx sdk.Context, k keeper.Keeper) {
 	if newEarliestAvailableNonce > earliestAttestation.GetNonce() {
 		// some attestations were pruned and we need to update the state for it
 		k.SetEarliestAvailableAttestationNonce(ctx, newEarliestAvailableNonce)
-		ctx.Logger().Debug(
+		k.Logger(ctx).Debug(
 			"pruned attestations from Blobstream store",
 			"count",
 			newEarliestAvailableNonce-earliestAttestation.GetNonce(),
@@ -101,7 +101,7 @@ func (k Keeper) GetCurrentValset(ctx sdk.Context) (types.Valset, error) {
 			// for existing validators wasn't conducted). A validator
 			// should always have an associated EVM address. Fortunately we can
 			// safely recover from this by deriving the default again.
-			ctx.Logger().Error("validator does not have an evm address set")
+			k.Logger(ctx).Error("validator does not have an evm address set")
 			evmAddress = types.DefaultEVMAddress(val)
 			k.SetEVMAddress(ctx, val, evmAddress)
 		}
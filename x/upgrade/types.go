@@ -2,6 +2,8 @@ package upgrade
 
 import (
 	fmt "fmt"
+	"strconv"
+	"strings"
 
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/codec"
@@ -109,6 +111,78 @@ func (s Schedule) ShouldProposeUpgrade(height int64) (uint64, bool) {
 	return 0, false
 }
 
+// ExpectedVersion returns the app version the network should be running at
+// the given height according to the schedule. False is returned if no plan
+// in the schedule has completed by that height.
+func (s Schedule) ExpectedVersion(height int64) (uint64, bool) {
+	var (
+		version uint64
+		found   bool
+	)
+	for _, plan := range s {
+		if height > plan.End {
+			version, found = plan.Version, true
+		}
+	}
+	return version, found
+}
+
+// Equal returns true if both schedules contain the same plans in the same
+// order.
+func (s Schedule) Equal(other Schedule) bool {
+	if len(s) != len(other) {
+		return false
+	}
+	for i := range s {
+		if s[i] != other[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseSchedules parses plans formatted as
+// <chain-id>:<start-height>:<end-height>:<version> into the schedules of their
+// chain IDs. The plans of a chain ID must be given in order.
+func ParseSchedules(plans []string) (map[string]Schedule, error) {
+	schedules := make(map[string]Schedule)
+	for _, p := range plans {
+		parts := strings.Split(p, ":")
+		if len(parts) != 4 {
+			return nil, fmt.Errorf("plan %q: expected <chain-id>:<start-height>:<end-height>:<version>", p)
+		}
+		start, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("plan %q: invalid start height: %w", p, err)
+		}
+		end, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("plan %q: invalid end height: %w", p, err)
+		}
+		version, err := strconv.ParseUint(parts[3], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("plan %q: invalid version: %w", p, err)
+		}
+		schedules[parts[0]] = append(schedules[parts[0]], NewPlan(start, end, version))
+	}
+	for chainID, schedule := range schedules {
+		if err := schedule.ValidateBasic(); err != nil {
+			return nil, fmt.Errorf("invalid schedule %s: %w", chainID, err)
+		}
+	}
+	return schedules, nil
+}
+
+// FormatPlans formats the plans of the schedule for the chain ID in the format
+// parsed by ParseSchedules.
+func (s Schedule) FormatPlans(chainID string) []string {
+	plans := make([]string, len(s))
+	for i, plan := range s {
+		plans[i] = fmt.Sprintf("%s:%d:%d:%d", chainID, plan.Start, plan.End, plan.Version)
+	}
+	return plans
+}
+
 func (p Plan) ValidateBasic() error {
 	if p.Start < 1 {
 		return fmt.Errorf("plan start height cannot be negative or zero: %d", p.Start)
@@ -67,3 +67,63 @@ func TestScheduleValidateVersions(t *testing.T) {
 		})
 	}
 }
+
+func TestScheduleExpectedVersion(t *testing.T) {
+	schedule := upgrade.Schedule{
+		upgrade.Plan{Start: 10, End: 20, Version: 2},
+		upgrade.Plan{Start: 30, End: 40, Version: 3},
+	}
+
+	testCases := []struct {
+		height  int64
+		version uint64
+		found   bool
+	}{
+		{1, 0, false},
+		{20, 0, false},
+		{21, 2, true},
+		{40, 2, true},
+		{41, 3, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("height%d", tc.height), func(t *testing.T) {
+			version, found := schedule.ExpectedVersion(tc.height)
+			require.Equal(t, tc.found, found)
+			require.Equal(t, tc.version, version)
+		})
+	}
+}
+
+func TestScheduleEqual(t *testing.T) {
+	schedule := upgrade.NewSchedule(upgrade.NewPlan(10, 20, 2), upgrade.NewPlan(30, 40, 3))
+
+	require.True(t, schedule.Equal(upgrade.NewSchedule(upgrade.NewPlan(10, 20, 2), upgrade.NewPlan(30, 40, 3))))
+	require.True(t, upgrade.Schedule(nil).Equal(upgrade.Schedule{}))
+	require.False(t, schedule.Equal(upgrade.NewSchedule(upgrade.NewPlan(10, 20, 2))))
+	require.False(t, schedule.Equal(upgrade.NewSchedule(upgrade.NewPlan(10, 20, 2), upgrade.NewPlan(30, 41, 3))))
+}
+
+func TestParseSchedules(t *testing.T) {
+	schedule := upgrade.NewSchedule(upgrade.NewPlan(10, 20, 2), upgrade.NewPlan(30, 40, 3))
+	plans := append(schedule.FormatPlans("testnet"), "mocha-4:5:5:2")
+
+	schedules, err := upgrade.ParseSchedules(plans)
+	require.NoError(t, err)
+	require.Equal(t, map[string]upgrade.Schedule{
+		"testnet": schedule,
+		"mocha-4": upgrade.NewSchedule(upgrade.NewPlan(5, 5, 2)),
+	}, schedules)
+
+	for _, plans := range [][]string{
+		{"testnet:10:20"},
+		{"testnet:a:20:2"},
+		{"testnet:10:20:-2"},
+		// plans must be valid and in order
+		{"testnet:20:10:2"},
+		{"testnet:30:40:3", "testnet:10:20:2"},
+	} {
+		_, err := upgrade.ParseSchedules(plans)
+		require.Error(t, err, plans)
+	}
+}
@@ -23,6 +23,13 @@ func (k Keeper) ShouldProposeUpgrade(chainID string, height int64) (uint64, bool
 	return 0, false
 }
 
+// GetSchedule returns the upgrade schedule configured for the network of the
+// given chainID. False is returned if no schedule is configured.
+func (k Keeper) GetSchedule(chainID string) (Schedule, bool) {
+	schedule, ok := k.upgradeSchedule[chainID]
+	return schedule, ok
+}
+
 func (k *Keeper) PrepareUpgradeAtEndBlock(version uint64) {
 	k.pendingAppVersion = version
 }
@@ -127,7 +127,7 @@ func TestUpgradeAppVersion(t *testing.T) {
 		txs := [][]byte{[]byte("hello world"), upgradeTx}
 		dataSquare, txs, err := square.Build(txs, appconsts.LatestVersion, appconsts.DefaultGovMaxSquareSize)
 		require.NoError(t, err)
-		eds, err := da.ExtendShares(shares.ToBytes(dataSquare))
+		eds, err := da.ExtendShares(shares.ToBytes(dataSquare), appconsts.LatestVersion)
 		require.NoError(t, err)
 		dah, err := da.NewDataAvailabilityHeader(eds)
 		require.NoError(t, err)